@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"path/filepath"
 	"time"
 
 	"github.com/mark3labs/mcp-go/server"
@@ -27,7 +28,7 @@ func main() {
 
 	runErr := make(chan error)
 	go func() {
-		runErr <- run()
+		runErr <- run(ctx)
 	}()
 
 	select {
@@ -56,36 +57,122 @@ func watchParent(ctx context.Context, cancel context.CancelFunc) {
 	}
 }
 
-func run() error {
-	root := flag.String("root", ".", "Root directory of the Go codebase to index")
+func run(ctx context.Context) error {
+	var roots []string
+	flag.Func("root", "Root directory of a Go module or go.work workspace to index (repeatable; default: .)", func(s string) error {
+		roots = append(roots, s)
+		return nil
+	})
+	watch := flag.Bool("watch", true, "Re-index automatically when Go files, go.mod, or go.work change")
+	useCache := flag.Bool("cache", true, "Load the index from the on-disk cache at startup and save it after indexing")
+	cacheDir := flag.String("cache-dir", defaultCacheDir(), "Directory for the on-disk index cache")
+	clearCache := flag.Bool("clear-cache", false, "Delete the cached index for the roots before starting")
+	goos := flag.String("goos", "", "Target operating system to index for (default: $GOOS or the host's)")
+	goarch := flag.String("goarch", "", "Target architecture to index for (default: $GOARCH or the host's)")
+	tags := flag.String("tags", "", "Comma-separated build tags to index with")
+	maxResponseBytes := flag.Int("max-response-bytes", 100_000, "Cut tool responses larger than this many bytes down to fit; 0 for no limit")
+	var extraConfigs []indexer.BuildConfig
+	flag.Func("build-config", "Additional build configuration to index and merge, as goos/goarch[:tag,...] (repeatable)", func(s string) error {
+		c, err := indexer.ParseBuildConfig(s)
+		if err != nil {
+			return err
+		}
+		extraConfigs = append(extraConfigs, c)
+		return nil
+	})
 	flag.Parse()
 
-	info, err := os.Stat(*root)
-	if err != nil {
-		return fmt.Errorf("invalid --root: %w", err)
+	if *maxResponseBytes != 0 && *maxResponseBytes < tools.MinResponseBytes {
+		return fmt.Errorf("--max-response-bytes must be 0 or at least %d", tools.MinResponseBytes)
+	}
+
+	if len(roots) == 0 {
+		roots = []string{"."}
 	}
-	if !info.IsDir() {
-		return fmt.Errorf("--root %q is not a directory", *root)
+	for _, root := range roots {
+		info, err := os.Stat(root)
+		if err != nil {
+			return fmt.Errorf("invalid --root: %w", err)
+		}
+		if !info.IsDir() {
+			return fmt.Errorf("--root %q is not a directory", root)
+		}
 	}
 
-	idx, err := indexer.New(*root)
+	primary := indexer.BuildConfig{GOOS: *goos, GOARCH: *goarch, Tags: indexer.SplitTags(*tags)}
+	opts := []indexer.Option{indexer.WithBuildConfigs(append([]indexer.BuildConfig{primary}, extraConfigs...)...)}
+	if *useCache && *cacheDir != "" {
+		opts = append(opts, indexer.WithCacheDir(*cacheDir))
+	}
+	opts = append(opts, indexer.WithRoots(roots[1:]...))
+	idx, err := indexer.New(roots[0], opts...)
 	if err != nil {
 		return fmt.Errorf("creating indexer: %w", err)
 	}
+	if *clearCache {
+		if err := idx.ClearCache(); err != nil {
+			return err
+		}
+	}
 
-	fmt.Fprintln(os.Stderr, "Indexing codebase...")
-	if err := idx.Index(); err != nil {
-		return fmt.Errorf("indexing codebase: %w", err)
+	cached, err := idx.LoadCache()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Ignoring index cache: %v\n", err)
+	}
+	if cached {
+		// Serve the cached symbols right away and restore type information in the background.
+		fmt.Fprintln(os.Stderr, "Index loaded from cache; type-checking in the background...")
+		go loadTypes(idx)
+	} else {
+		fmt.Fprintln(os.Stderr, "Indexing codebase...")
+		if err := idx.Index(); err != nil {
+			return fmt.Errorf("indexing codebase: %w", err)
+		}
+		saveCache(idx)
+		fmt.Fprintln(os.Stderr, "Index ready.")
+	}
+
+	if *watch {
+		if err := watchSource(ctx, idx); err != nil {
+			return fmt.Errorf("starting file watcher: %w", err)
+		}
 	}
-	fmt.Fprintln(os.Stderr, "Index ready.")
 
 	f := finder.New(idx)
 
 	s := server.NewMCPServer("go-llm-lens", version)
-	tools.Register(s, f)
+	tools.Register(s, idx, f, tools.WithMaxResponseBytes(*maxResponseBytes))
 
 	if err := server.ServeStdio(s); err != nil {
 		return fmt.Errorf("serving MCP: %w", err)
 	}
 	return nil
 }
+
+// defaultCacheDir returns the per-user cache directory for index caches, or
+// empty if the platform has none.
+func defaultCacheDir() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "go-llm-lens")
+}
+
+// loadTypes restores type information in the background after a cached startup.
+func loadTypes(idx *indexer.Indexer) {
+	if err := idx.LoadTypes(); err != nil {
+		fmt.Fprintf(os.Stderr, "Type-checking codebase failed: %v\n", err)
+		return
+	}
+	saveCache(idx)
+	fmt.Fprintln(os.Stderr, "Index ready.")
+}
+
+// saveCache writes the index cache, reporting but otherwise ignoring failures:
+// the cache only speeds up the next startup.
+func saveCache(idx *indexer.Indexer) {
+	if err := idx.SaveCache(); err != nil {
+		fmt.Fprintf(os.Stderr, "Saving index cache failed: %v\n", err)
+	}
+}
@@ -0,0 +1,170 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+
+	"github.com/tender-barbarian/go-llm-lens/internal/indexer"
+)
+
+// reindexDebounce is how long the watcher waits after the last file change
+// before re-indexing, so that a burst of edits triggers a single rebuild.
+const reindexDebounce = 500 * time.Millisecond
+
+// watchSource starts a background watcher that re-indexes idx whenever Go
+// sources or module files under its source directories change. Queries keep being served
+// from the previous index until the rebuild completes.
+func watchSource(ctx context.Context, idx *indexer.Indexer) error {
+	w, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("creating file watcher: %w", err)
+	}
+	for _, dir := range idx.SourceDirs() {
+		if err := addWatchDirs(w, dir); err != nil {
+			_ = w.Close()
+			return fmt.Errorf("watching %q: %w", dir, err)
+		}
+	}
+
+	go func() {
+		defer func() { _ = w.Close() }()
+		runWatcher(ctx, relevantEvents(ctx, w), reindexDebounce, func() { reindex(idx) })
+	}()
+	return nil
+}
+
+// relevantEvents forwards the events of w that relevantEvent accepts, and
+// reports watcher errors on stderr. The returned channel is closed when ctx is
+// done or w is closed.
+func relevantEvents(ctx context.Context, w *fsnotify.Watcher) <-chan fsnotify.Event {
+	events := make(chan fsnotify.Event)
+	go func() {
+		defer close(events)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case ev, ok := <-w.Events:
+				if !ok {
+					return
+				}
+				if !relevantEvent(w, ev) {
+					continue
+				}
+				select {
+				case events <- ev:
+				case <-ctx.Done():
+					return
+				}
+			case err, ok := <-w.Errors:
+				if !ok {
+					return
+				}
+				fmt.Fprintf(os.Stderr, "File watcher error: %v\n", err)
+			}
+		}
+	}()
+	return events
+}
+
+// runWatcher calls update once no event has arrived on events for debounce,
+// so that a burst of changes triggers a single update, and runs at most one
+// update at a time. Events that arrive while an update is running schedule
+// another one. It returns when ctx is done or events is closed.
+func runWatcher(ctx context.Context, events <-chan fsnotify.Event, debounce time.Duration, update func()) {
+	timer := time.NewTimer(debounce)
+	timer.Stop()
+	done := make(chan struct{}, 1)
+	running, pending := false, false
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case _, ok := <-events:
+			if !ok {
+				return
+			}
+			timer.Reset(debounce)
+		case <-timer.C:
+			if running {
+				pending = true
+				continue
+			}
+			running = true
+			go func() {
+				update()
+				done <- struct{}{}
+			}()
+		case <-done:
+			running = false
+			if pending {
+				pending = false
+				timer.Reset(debounce)
+			}
+		}
+	}
+}
+
+// relevantEvent reports whether ev can affect the index. Newly created
+// directories are added to the watch list as a side effect, and files in
+// directories the indexer skips are ignored.
+func relevantEvent(w *fsnotify.Watcher, ev fsnotify.Event) bool {
+	if ev.Has(fsnotify.Create) {
+		if info, err := os.Stat(ev.Name); err == nil && info.IsDir() {
+			if indexer.SkipDir(ev.Name) {
+				return false
+			}
+			if err := addWatchDirs(w, ev.Name); err != nil {
+				fmt.Fprintf(os.Stderr, "File watcher error: %v\n", err)
+			}
+			return true
+		}
+	}
+	if ev.Has(fsnotify.Remove) || ev.Has(fsnotify.Rename) {
+		// A removed directory may have held Go files whose own events were not delivered.
+		if slices.Contains(w.WatchList(), ev.Name) {
+			return true
+		}
+	}
+	return !ev.Has(fsnotify.Chmod) && indexer.IsTrackedFile(filepath.Base(ev.Name)) &&
+		slices.Contains(w.WatchList(), filepath.Dir(ev.Name))
+}
+
+// addWatchDirs watches root and every directory beneath it that the indexer scans.
+func addWatchDirs(w *fsnotify.Watcher, root string) error {
+	return filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		if path != root && indexer.SkipDir(path) {
+			return filepath.SkipDir
+		}
+		return w.Add(path)
+	})
+}
+
+// reindex incrementally updates idx, saves it to the cache if anything
+// changed, and reports the outcome on stderr.
+func reindex(idx *indexer.Indexer) {
+	start := time.Now()
+	paths, err := idx.Update()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Re-indexing failed: %v\n", err)
+		return
+	}
+	if len(paths) > 0 {
+		fmt.Fprintf(os.Stderr, "Re-indexed %d package(s) in %s.\n", len(paths), time.Since(start).Round(time.Millisecond))
+		saveCache(idx)
+	}
+}
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testDebounce is the debounce the runWatcher tests use, short enough to keep
+// them fast.
+const testDebounce = 20 * time.Millisecond
+
+// startWatcher runs runWatcher on a fake event channel until the test ends.
+// Each update is reported on the returned calls channel and then waits for a
+// value on release.
+func startWatcher(t *testing.T) (events chan<- fsnotify.Event, calls <-chan struct{}, release chan<- struct{}) {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	ev := make(chan fsnotify.Event)
+	c := make(chan struct{})
+	r := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		runWatcher(ctx, ev, testDebounce, func() {
+			c <- struct{}{}
+			<-r
+		})
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-stopped
+	})
+	return ev, c, r
+}
+
+// expectCall waits for the next update.
+func expectCall(t *testing.T, calls <-chan struct{}) {
+	t.Helper()
+	select {
+	case <-calls:
+	case <-time.After(time.Second):
+		require.Fail(t, "no update")
+	}
+}
+
+// expectNoCall checks that no update starts for several debounce periods.
+func expectNoCall(t *testing.T, calls <-chan struct{}) {
+	t.Helper()
+	select {
+	case <-calls:
+		assert.Fail(t, "unexpected update")
+	case <-time.After(10 * testDebounce):
+	}
+}
+
+func TestRunWatcher(t *testing.T) {
+	event := fsnotify.Event{Name: "greeter.go", Op: fsnotify.Write}
+
+	t.Run("a burst of events triggers one update", func(t *testing.T) {
+		events, calls, release := startWatcher(t)
+		for range 5 {
+			events <- event
+		}
+		expectCall(t, calls)
+		release <- struct{}{}
+		expectNoCall(t, calls)
+	})
+
+	t.Run("events during an update trigger one more", func(t *testing.T) {
+		events, calls, release := startWatcher(t)
+		events <- event
+		expectCall(t, calls)
+		for range 3 {
+			events <- event
+		}
+		time.Sleep(5 * testDebounce) // let the debounce expire while the update runs
+		release <- struct{}{}
+		expectCall(t, calls)
+		release <- struct{}{}
+		expectNoCall(t, calls)
+	})
+
+	t.Run("no events, no update", func(t *testing.T) {
+		_, calls, _ := startWatcher(t)
+		expectNoCall(t, calls)
+	})
+}
+
+func TestRelevantEvent(t *testing.T) {
+	root := t.TempDir()
+	for _, dir := range []string{"greeter", "testdata"} {
+		require.NoError(t, os.Mkdir(filepath.Join(root, dir), 0o750))
+	}
+	w, err := fsnotify.NewWatcher()
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = w.Close() })
+	require.NoError(t, addWatchDirs(w, root))
+	require.ElementsMatch(t, []string{root, filepath.Join(root, "greeter")}, w.WatchList())
+
+	tests := []struct {
+		name     string
+		path     string
+		op       fsnotify.Op
+		mkdir    bool
+		expected bool
+		watched  bool // whether a new directory is watched afterwards
+	}{
+		{name: "Go file", path: "greeter/greeter.go", op: fsnotify.Write, expected: true},
+		{name: "test file", path: "greeter/greeter_test.go", op: fsnotify.Create, expected: true},
+		{name: "go.mod", path: "go.mod", op: fsnotify.Write, expected: true},
+		{name: "go.work", path: "go.work", op: fsnotify.Remove, expected: true},
+		{name: "other file", path: "greeter/README.md", op: fsnotify.Write},
+		{name: "permission change", path: "greeter/greeter.go", op: fsnotify.Chmod},
+		{name: "file in a skipped directory", path: "testdata/fixture.go", op: fsnotify.Write},
+		{name: "new directory", path: "greeter/internal", op: fsnotify.Create, mkdir: true, expected: true, watched: true},
+		{name: "new skipped directory", path: "vendor", op: fsnotify.Create, mkdir: true},
+		{name: "removed directory", path: "greeter", op: fsnotify.Remove, expected: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(root, filepath.FromSlash(tt.path))
+			if tt.mkdir {
+				require.NoError(t, os.Mkdir(path, 0o750))
+			}
+			assert.Equal(t, tt.expected, relevantEvent(w, fsnotify.Event{Name: path, Op: tt.op}))
+			if tt.mkdir {
+				assert.Equal(t, tt.watched, slices.Contains(w.WatchList(), path))
+			}
+		})
+	}
+}
@@ -0,0 +1,373 @@
+package finder
+
+import (
+	"cmp"
+	"fmt"
+	"go/types"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/tender-barbarian/go-llm-lens/internal/indexer"
+	"github.com/tender-barbarian/go-llm-lens/internal/symtab"
+)
+
+// GetCallers returns the calls made to the function or method symbol of package
+// pkgPath, then the calls made to those callers, and so on up to depth steps
+// away. symbol is named as in FindReferences. A call through an interface
+// method counts as a call to every indexed method that can implement it
+// (class hierarchy analysis), and is reported with Via set. Calls through
+// function values are not followed. Test code, including test types whose
+// methods implement interfaces, is only walked when includeTests is set.
+func (f *Finder) GetCallers(pkgPath, symbol string, depth int, includeTests bool) ([]symtab.CallEdge, error) {
+	g, start, err := f.callGraph(pkgPath, symbol, depth, includeTests)
+	if err != nil {
+		return nil, err
+	}
+	return g.walk(start, depth, true), nil
+}
+
+// GetCallees returns the functions and methods symbol calls, then the ones
+// those call, and so on up to depth steps away. A call to an interface method is
+// reported both as such and, with Via set, as a call to every indexed method
+// that can implement it. See GetCallers.
+func (f *Finder) GetCallees(pkgPath, symbol string, depth int, includeTests bool) ([]symtab.CallEdge, error) {
+	g, start, err := f.callGraph(pkgPath, symbol, depth, includeTests)
+	if err != nil {
+		return nil, err
+	}
+	return g.walk(start, depth, false), nil
+}
+
+// callGraph resolves the starting function of a call graph walk and prepares
+// the walk over the current snapshot.
+func (f *Finder) callGraph(pkgPath, symbol string, depth int, includeTests bool) (*callGraph, *types.Func, error) {
+	if depth < 1 {
+		return nil, nil, fmt.Errorf("depth must be at least 1, got %d", depth)
+	}
+	snap := f.idx.Snapshot()
+	if snap.Partial() {
+		return nil, nil, errPartialIndex
+	}
+	obj, err := lookupObject(snap, pkgPath, symbol)
+	if err != nil {
+		return nil, nil, err
+	}
+	fn, ok := obj.(*types.Func)
+	if !ok {
+		return nil, nil, fmt.Errorf("%q is not a function or method", symbol)
+	}
+	return &callGraph{snap: snap, includeTests: includeTests, hierarchy: f.hierarchy(snap, includeTests)}, fn, nil
+}
+
+// callNode is a function in the call graph: a package-level function, a method
+// as TypeName.MethodName, or "init" for package initialization.
+type callNode struct {
+	pkg  string
+	name string
+}
+
+// String returns the node's name as reported in symtab.CallEdge.
+func (n callNode) String() string {
+	return n.pkg + "." + n.name
+}
+
+// nodeOf returns the call graph node of fn.
+func nodeOf(fn *types.Func) callNode {
+	name := fn.Name()
+	if recv := fn.Signature().Recv(); recv != nil {
+		if named := namedOf(recv.Type()); named != nil {
+			name = named.Obj().Name() + "." + name
+		}
+	}
+	return callNode{pkg: fn.Pkg().Path(), name: name}
+}
+
+// callGraph walks the static call graph recorded in one snapshot's references.
+type callGraph struct {
+	snap         *indexer.Snapshot
+	includeTests bool
+	*hierarchy
+}
+
+// hierarchy is the class hierarchy of the indexed packages. dispatch maps the
+// SymbolKey of each interface method to the concrete methods a call through it
+// can reach, and implemented the other way round.
+type hierarchy struct {
+	dispatch    map[string]map[string]*types.Func
+	implemented map[string]map[string]*types.Func
+}
+
+// hierarchyCache keeps the class hierarchies of the most recent snapshot
+// walked, with and without test code, since building one checks every
+// interface against every concrete type.
+type hierarchyCache struct {
+	mu          sync.Mutex
+	snap        *indexer.Snapshot
+	hierarchies map[bool]*hierarchy // keyed by includeTests
+}
+
+// hierarchy returns the class hierarchy of snap, building it on first use.
+func (f *Finder) hierarchy(snap *indexer.Snapshot, includeTests bool) *hierarchy {
+	f.calls.mu.Lock()
+	defer f.calls.mu.Unlock()
+	if f.calls.snap != snap {
+		f.calls.snap, f.calls.hierarchies = snap, make(map[bool]*hierarchy)
+	}
+	h, ok := f.calls.hierarchies[includeTests]
+	if !ok {
+		h = newHierarchy(snap, includeTests)
+		f.calls.hierarchies[includeTests] = h
+	}
+	return h
+}
+
+// newHierarchy works out which concrete methods each interface method of the
+// indexed packages can dispatch to. Test types are matched against the
+// interfaces, or interfaces of tests against the types, as the test variant
+// they were checked in declares them.
+func newHierarchy(snap *indexer.Snapshot, includeTests bool) *hierarchy {
+	h := &hierarchy{
+		dispatch:    make(map[string]map[string]*types.Func),
+		implemented: make(map[string]map[string]*types.Func),
+	}
+	type candidate struct {
+		tn     *types.TypeName
+		isTest bool
+	}
+	var ifaces, concrete []candidate
+	eachType(snap, func(ti symtab.TypeInfo, tn *types.TypeName) {
+		named, ok := tn.Type().(*types.Named)
+		switch {
+		case !ok || named.TypeParams().Len() > 0:
+			// Aliases and generic types: a generic type's methods are only
+			// known to implement an interface once it is instantiated.
+		case ti.IsTest && !includeTests, ti.Kind == symtab.TypeKindConstraint:
+		case ti.Kind == symtab.TypeKindInterface:
+			ifaces = append(ifaces, candidate{tn, ti.IsTest})
+		default:
+			concrete = append(concrete, candidate{tn, ti.IsTest})
+		}
+	})
+	for _, ic := range ifaces {
+		iface, ok := ic.tn.Type().Underlying().(*types.Interface)
+		if !ok || iface.NumMethods() == 0 {
+			continue
+		}
+		mKeys := make([]string, iface.NumMethods())
+		for i := range mKeys {
+			mKeys[i], _ = indexer.SymbolKey(iface.Method(i))
+		}
+		for _, cc := range concrete {
+			T, seen := cc.tn.Type(), iface
+			switch {
+			case cc.isTest:
+				seen, _ = asSeenFrom(cc.tn.Pkg(), ic.tn).Type().Underlying().(*types.Interface)
+			case ic.isTest:
+				T = asSeenFrom(ic.tn.Pkg(), cc.tn).Type()
+			}
+			if seen == nil || !implements(T, seen) {
+				continue
+			}
+			for i, mKey := range mKeys {
+				m := seen.Method(i)
+				obj, _, _ := types.LookupFieldOrMethod(types.NewPointer(T), false, m.Pkg(), m.Name())
+				impl, ok := obj.(*types.Func)
+				if !ok || mKey == "" {
+					continue
+				}
+				implKey, err := indexer.SymbolKey(impl)
+				if err != nil {
+					continue
+				}
+				addEdge(h.dispatch, mKey, implKey, impl)
+				addEdge(h.implemented, implKey, mKey, m)
+			}
+		}
+	}
+	return h
+}
+
+// addEdge records fn under m[from][to].
+func addEdge(m map[string]map[string]*types.Func, from, to string, fn *types.Func) {
+	if m[from] == nil {
+		m[from] = make(map[string]*types.Func)
+	}
+	m[from][to] = fn
+}
+
+// step is one call found while expanding a node: the function on the other end
+// and the sites of the call.
+type step struct {
+	node  callNode
+	fn    *types.Func // nil for package initialization
+	via   string
+	sites []symtab.Location
+}
+
+// walk expands the graph breadth first from start for depth levels, toward
+// callers or callees, and returns every call found. Each function is expanded
+// once, at the smallest depth it is reached.
+func (g *callGraph) walk(start *types.Func, depth int, towardCallers bool) []symtab.CallEdge {
+	type item struct {
+		node callNode
+		fn   *types.Func
+	}
+	first := nodeOf(start)
+	visited := map[callNode]bool{first: true}
+	level := []item{{first, start}}
+	var edges []symtab.CallEdge
+	for d := 1; d <= depth && len(level) > 0; d++ {
+		var next []item
+		for _, it := range level {
+			var steps []step
+			if towardCallers {
+				steps = g.callers(it.fn)
+			} else {
+				steps = g.callees(it.node)
+			}
+			for _, s := range steps {
+				edge := symtab.CallEdge{Caller: it.node.String(), Callee: s.node.String(), Via: s.via, Depth: d, Sites: s.sites, Func: g.symbolRef(s.node, s.sites[0])}
+				if towardCallers {
+					edge.Caller, edge.Callee = edge.Callee, edge.Caller
+				}
+				edges = append(edges, edge)
+				if !visited[s.node] && s.fn != nil {
+					visited[s.node] = true
+					next = append(next, item{s.node, s.fn})
+				}
+			}
+		}
+		level = next
+	}
+	return edges
+}
+
+// callers returns the calls made to fn, including calls through the interface
+// methods it implements.
+func (g *callGraph) callers(fn *types.Func) []step {
+	key, err := indexer.SymbolKey(fn)
+	if err != nil {
+		return nil
+	}
+	steps := g.callersOf(key, "")
+	for _, mKey := range slices.Sorted(maps.Keys(g.implemented[key])) {
+		steps = append(steps, g.callersOf(mKey, nodeOf(g.implemented[key][mKey]).String())...)
+	}
+	return steps
+}
+
+// callersOf returns the functions calling the function with key, grouped by
+// caller and ordered by name.
+func (g *callGraph) callersOf(key, via string) []step {
+	sites := make(map[callNode][]symtab.Location)
+	for _, pkg := range g.snap.PkgInfos() {
+		for _, ref := range pkg.Refs[key] {
+			if ref.Kind != symtab.RefKindCall || ref.IsTest && !g.includeTests {
+				continue
+			}
+			caller := callNode{pkg: ref.Package, name: cmp.Or(ref.Func, "init")}
+			sites[caller] = append(sites[caller], ref.Location)
+		}
+	}
+	steps := make([]step, 0, len(sites))
+	for _, caller := range slices.SortedFunc(maps.Keys(sites), compareNodes) {
+		s := step{node: caller, via: via, sites: sortLocations(sites[caller])}
+		if caller.name != "init" {
+			if fn, ok := lookupFunc(g.snap, caller); ok {
+				s.fn = fn
+			}
+		}
+		steps = append(steps, s)
+	}
+	return steps
+}
+
+// callees returns the calls the function node makes. A call to an interface method is followed
+// by a call to each method that implements it.
+func (g *callGraph) callees(node callNode) []step {
+	pkg, ok := g.snap.PkgInfos()[node.pkg]
+	if !ok {
+		return nil
+	}
+	var steps []step
+	for _, key := range slices.Sorted(maps.Keys(pkg.Refs)) {
+		var sites []symtab.Location
+		for _, ref := range pkg.Refs[key] {
+			if ref.Kind == symtab.RefKindCall && ref.Func == node.name && (!ref.IsTest || g.includeTests) {
+				sites = append(sites, ref.Location)
+			}
+		}
+		if len(sites) == 0 {
+			continue
+		}
+		obj, err := g.snap.Object(key)
+		fn, ok := obj.(*types.Func)
+		if err != nil || !ok {
+			continue // a call through a function value
+		}
+		sites = sortLocations(sites)
+		callee := nodeOf(fn)
+		steps = append(steps, step{node: callee, fn: fn, sites: sites})
+		for _, implKey := range slices.Sorted(maps.Keys(g.dispatch[key])) {
+			impl := g.dispatch[key][implKey]
+			steps = append(steps, step{node: nodeOf(impl), fn: impl, via: callee.String(), sites: sites})
+		}
+	}
+	return steps
+}
+
+// lookupFunc resolves a call graph node to its function.
+func lookupFunc(snap *indexer.Snapshot, node callNode) (*types.Func, bool) {
+	obj, err := lookupObject(snap, node.pkg, node.name)
+	if err != nil {
+		return nil, false
+	}
+	fn, ok := obj.(*types.Func)
+	return fn, ok
+}
+
+// symbolRef describes node as found in the index. Package initialization,
+// which has no declaration, is located at site, its first call.
+func (g *callGraph) symbolRef(node callNode, site symtab.Location) symtab.SymbolRef {
+	ref := symtab.SymbolRef{Name: node.name, Package: node.pkg, Kind: symtab.SymbolKindFunc}
+	pkg, ok := g.snap.PkgInfos()[node.pkg]
+	typeName, method, isMethod := strings.Cut(node.name, ".")
+	switch {
+	case node.name == "init":
+		ref.IsTest = strings.HasSuffix(site.File, "_test.go")
+		ref.Location = site
+	case !ok:
+	case !isMethod:
+		if i := slices.IndexFunc(pkg.Funcs, func(f symtab.FuncInfo) bool { return f.Name == node.name }); i >= 0 {
+			return funcRef(pkg.ImportPath, &pkg.Funcs[i], symtab.SymbolKindFunc)
+		}
+	default:
+		ref.Name, ref.Kind = method, symtab.SymbolKindMethod
+		for _, t := range pkg.Types {
+			if t.Name != typeName {
+				continue
+			}
+			for i := range t.Methods {
+				if m := &t.Methods[i]; m.Name == method && !m.IsPromoted {
+					return funcRef(pkg.ImportPath, m, symtab.SymbolKindMethod)
+				}
+			}
+		}
+	}
+	return ref
+}
+
+// compareNodes orders call graph nodes by package and name.
+func compareNodes(a, b callNode) int {
+	return cmp.Or(strings.Compare(a.pkg, b.pkg), strings.Compare(a.name, b.name))
+}
+
+// sortLocations sorts locs by file and position.
+func sortLocations(locs []symtab.Location) []symtab.Location {
+	slices.SortFunc(locs, func(a, b symtab.Location) int {
+		return cmp.Or(strings.Compare(a.File, b.File), a.Line-b.Line, a.Column-b.Column)
+	})
+	return locs
+}
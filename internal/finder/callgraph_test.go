@@ -0,0 +1,195 @@
+package finder
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tender-barbarian/go-llm-lens/internal/indexer"
+	"github.com/tender-barbarian/go-llm-lens/internal/symtab"
+)
+
+const welcomePkg = "example.com/testdata/welcome"
+
+// newCallGraphFinder indexes a copy of the fixture module with a welcome
+// package that calls into greeter directly and through the Greeter interface.
+func newCallGraphFinder(t *testing.T) *Finder {
+	t.Helper()
+	idx, _ := indexFixture(t, map[string]string{
+		"welcome/welcome.go": `package welcome
+
+import "example.com/testdata/greeter"
+
+func Welcome(name string) string { return greet(greeter.New("Hi, "), name) }
+
+func greet(g greeter.Greeter, name string) string { return g.Greet(name) }
+
+var banner = Welcome("all")
+`,
+		"welcome/welcome_test.go": `package welcome
+
+import "testing"
+
+func TestWelcome(t *testing.T) { Welcome("test") }
+`,
+	})
+	return New(idx)
+}
+
+// edge is the part of a symtab.CallEdge the tests compare.
+type edge struct {
+	Caller, Callee, Via string
+	Depth               int
+	Sites               int
+}
+
+func edgesOf(calls []symtab.CallEdge) []edge {
+	result := make([]edge, 0, len(calls))
+	for _, c := range calls {
+		result = append(result, edge{c.Caller, c.Callee, c.Via, c.Depth, len(c.Sites)})
+	}
+	return result
+}
+
+func TestGetCallers(t *testing.T) {
+	finder := newCallGraphFinder(t)
+	const (
+		greet   = welcomePkg + ".greet"
+		welcome = welcomePkg + ".Welcome"
+		iface   = fixturePkg + ".Greeter.Greet"
+	)
+
+	tests := []struct {
+		name         string
+		pkgPath      string
+		symbol       string
+		depth        int
+		includeTests bool
+		expected     []edge
+		expectedErr  string
+	}{
+		{
+			name: "direct callers", pkgPath: welcomePkg, symbol: "greet", depth: 1,
+			expected: []edge{{welcome, greet, "", 1, 1}},
+		},
+		{
+			name: "package initialization", pkgPath: welcomePkg, symbol: "Welcome", depth: 1,
+			expected: []edge{{welcomePkg + ".init", welcome, "", 1, 1}},
+		},
+		{
+			name: "test callers", pkgPath: welcomePkg, symbol: "Welcome", depth: 1, includeTests: true,
+			expected: []edge{{welcomePkg + ".TestWelcome", welcome, "", 1, 1}, {welcomePkg + ".init", welcome, "", 1, 1}},
+		},
+		{
+			name: "calls through an interface", pkgPath: fixturePkg, symbol: "English.Greet", depth: 3,
+			expected: []edge{
+				{greet, fixturePkg + ".English.Greet", iface, 1, 1},
+				{welcome, greet, "", 2, 1},
+				{welcomePkg + ".init", welcome, "", 3, 1},
+			},
+		},
+		{
+			name: "promoted methods dispatch to their declaration", pkgPath: fixturePkg, symbol: "FormalEnglish.Greet", depth: 1,
+			expected: []edge{{greet, fixturePkg + ".Formal.Greet", iface, 1, 1}},
+		},
+		{name: "not a function", pkgPath: fixturePkg, symbol: "English", depth: 1, expectedErr: "is not a function or method"},
+		{name: "invalid depth", pkgPath: fixturePkg, symbol: "New", expectedErr: "depth must be at least 1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual, err := finder.GetCallers(tt.pkgPath, tt.symbol, tt.depth, tt.includeTests)
+			if tt.expectedErr != "" {
+				assert.ErrorContains(t, err, tt.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, edgesOf(actual))
+		})
+	}
+}
+
+func TestGetCallees(t *testing.T) {
+	finder := newCallGraphFinder(t)
+	const greet = welcomePkg + ".greet"
+
+	actual, err := finder.GetCallees(welcomePkg, "Welcome", 2, false)
+	require.NoError(t, err)
+	assert.Equal(t, []edge{
+		{welcomePkg + ".Welcome", fixturePkg + ".New", "", 1, 1},
+		{welcomePkg + ".Welcome", greet, "", 1, 1},
+		{greet, fixturePkg + ".Greeter.Greet", "", 2, 1},
+		{greet, fixturePkg + ".English.Greet", fixturePkg + ".Greeter.Greet", 2, 1},
+		{greet, fixturePkg + ".Formal.Greet", fixturePkg + ".Greeter.Greet", 2, 1},
+	}, edgesOf(actual))
+
+	byCallee := make(map[string]symtab.CallEdge)
+	for _, c := range actual {
+		byCallee[c.Callee] = c
+	}
+	assert.Equal(t, symtab.SymbolKindFunc, byCallee[fixturePkg+".New"].Func.Kind)
+	assert.Equal(t, "New", byCallee[fixturePkg+".New"].Func.Name)
+	assert.Contains(t, byCallee[fixturePkg+".New"].Func.Signature, "func New(")
+	english := byCallee[fixturePkg+".English.Greet"].Func
+	assert.Equal(t, symtab.SymbolKindMethod, english.Kind)
+	assert.Equal(t, "*"+fixturePkg+".English", english.Receiver)
+	assert.Equal(t, "greeter.go", filepath.Base(english.Location.File))
+	site := byCallee[greet].Sites[0]
+	assert.Equal(t, "welcome.go", filepath.Base(site.File))
+	assert.Equal(t, 5, site.Line)
+	assert.Equal(t, 43, site.Column)
+}
+
+func TestCallGraphTestFakes(t *testing.T) {
+	idx, _ := indexFixture(t, map[string]string{
+		"greeter/store.go": `package greeter
+
+// Store looks up greeters.
+type Store interface {
+	Get(name string) (*English, error)
+}
+
+// Lookup calls Get.
+func Lookup(s Store) { _, _ = s.Get("x") }
+`,
+		"greeter/greeter_test.go": "package greeter\n\ntype fakeStore struct{}\n\nfunc (fakeStore) Get(string) (*English, error) { return nil, nil }\n",
+		"greeter/example_test.go": "package greeter_test\n\nimport \"example.com/testdata/greeter\"\n\ntype externalStore struct{}\n\nfunc (*externalStore) Get(string) (*greeter.English, error) { return nil, nil }\n",
+	})
+	finder := New(idx)
+	const (
+		lookup = fixturePkg + ".Lookup"
+		get    = fixturePkg + ".Store.Get"
+	)
+
+	actual, err := finder.GetCallees(fixturePkg, "Lookup", 1, true)
+	require.NoError(t, err)
+	assert.Equal(t, []edge{
+		{lookup, get, "", 1, 1},
+		{lookup, fixturePkg + ".fakeStore.Get", get, 1, 1},
+		{lookup, fixturePkg + "_test.externalStore.Get", get, 1, 1},
+	}, edgesOf(actual))
+
+	actual, err = finder.GetCallers(fixturePkg, "fakeStore.Get", 1, true)
+	require.NoError(t, err)
+	assert.Equal(t, []edge{{lookup, fixturePkg + ".fakeStore.Get", get, 1, 1}}, edgesOf(actual))
+
+	actual, err = finder.GetCallees(fixturePkg, "Lookup", 1, false)
+	require.NoError(t, err)
+	assert.Equal(t, []edge{{lookup, get, "", 1, 1}}, edgesOf(actual))
+}
+
+func TestHierarchyCache(t *testing.T) {
+	idx, err := indexer.New("../../tests/testdata")
+	require.NoError(t, err)
+	require.NoError(t, idx.Index())
+	finder := New(idx)
+
+	snap := idx.Snapshot()
+	h := finder.hierarchy(snap, false)
+	assert.Same(t, h, finder.hierarchy(snap, false))
+	assert.NotSame(t, h, finder.hierarchy(snap, true))
+
+	require.NoError(t, idx.Index())
+	assert.NotSame(t, h, finder.hierarchy(idx.Snapshot(), false))
+}
@@ -0,0 +1,118 @@
+package finder
+
+import (
+	"cmp"
+	"fmt"
+	"go/types"
+	"slices"
+	"strings"
+
+	"github.com/tender-barbarian/go-llm-lens/internal/symtab"
+)
+
+// FindConstructors returns the indexed package-level functions that produce a
+// value of the named type: those returning T or *T, alone or followed by an
+// error, and, for a concrete type, those returning a non-empty interface that
+// T or *T implements in the same way. Functions of the type's own package come
+// first, then those returning the type itself, then the packages closest to
+// it by import path.
+func (f *Finder) FindConstructors(pkgPath, typeName string) ([]symtab.Constructor, error) {
+	snap := f.idx.Snapshot()
+	if snap.Partial() {
+		return nil, errPartialIndex
+	}
+	obj, err := lookupObject(snap, pkgPath, typeName)
+	if err != nil {
+		return nil, err
+	}
+	tn, ok := obj.(*types.TypeName)
+	if !ok {
+		return nil, fmt.Errorf("%q is not a type", typeName)
+	}
+	T := tn.Type()
+	concrete := !types.IsInterface(T)
+	if named, ok := types.Unalias(T).(*types.Named); ok && named.TypeParams().Len() > 0 {
+		concrete = false // only instantiations implement interfaces
+	}
+
+	// produced returns the interface a result type is an abstraction of T
+	// through, "" if it is T or *T itself, or false if it is neither.
+	produced := func(t types.Type) (string, bool) {
+		if isNamed(deref(t), pkgPath, typeName) {
+			return "", true
+		}
+		iface, ok := t.Underlying().(*types.Interface)
+		if !concrete || !ok || iface.NumMethods() == 0 || !iface.IsMethodSet() || isError(t) {
+			return "", false
+		}
+		if !implements(T, iface) {
+			return "", false
+		}
+		return types.TypeString(t, nil), true
+	}
+
+	var result []symtab.Constructor
+	for _, pkg := range snap.PkgInfos() {
+		for i := range pkg.Funcs {
+			fn := &pkg.Funcs[i]
+			fnObj, err := lookupObject(snap, pkg.ImportPath, fn.Name)
+			if err != nil {
+				continue
+			}
+			tf, ok := fnObj.(*types.Func)
+			if !ok {
+				continue
+			}
+			results := tf.Signature().Results()
+			if results.Len() == 0 || results.Len() > 2 || results.Len() == 2 && !isError(results.At(1).Type()) {
+				continue
+			}
+			iface, ok := produced(results.At(0).Type())
+			if !ok {
+				continue
+			}
+			result = append(result, symtab.Constructor{
+				Name:      fn.Name,
+				Package:   pkg.ImportPath,
+				Signature: fn.Signature,
+				Interface: iface,
+				IsTest:    fn.IsTest,
+				Doc:       fn.Doc,
+				Location:  fn.Location,
+			})
+		}
+	}
+	slices.SortFunc(result, func(a, b symtab.Constructor) int {
+		return cmp.Or(
+			compareBool(a.Package == pkgPath, b.Package == pkgPath),
+			compareBool(a.Interface == "", b.Interface == ""),
+			cmp.Compare(commonPathLen(b.Package, pkgPath), commonPathLen(a.Package, pkgPath)),
+			strings.Compare(a.Package, b.Package),
+			strings.Compare(a.Name, b.Name),
+		)
+	})
+	return result, nil
+}
+
+// isNamed reports whether t is the named type name of package pkgPath, or an
+// instantiation of it. Declarations are compared by name because a package's
+// test variant declares its types anew.
+func isNamed(t types.Type, pkgPath, name string) bool {
+	named, ok := types.Unalias(t).(*types.Named)
+	return ok && named.Obj().Pkg() != nil && named.Obj().Pkg().Path() == pkgPath && named.Obj().Name() == name
+}
+
+// isError reports whether t is the predeclared error type.
+func isError(t types.Type) bool {
+	return types.Identical(t, types.Universe.Lookup("error").Type())
+}
+
+// commonPathLen returns how many leading import path elements a and b share.
+func commonPathLen(a, b string) int {
+	as, bs := strings.Split(a, "/"), strings.Split(b, "/")
+	n := 0
+	for n < len(as) && n < len(bs) && as[n] == bs[n] {
+		n++
+	}
+	return n
+}
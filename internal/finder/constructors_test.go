@@ -0,0 +1,96 @@
+package finder
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tender-barbarian/go-llm-lens/internal/symtab"
+)
+
+func TestFindConstructors(t *testing.T) {
+	idx, _ := indexFixture(t, map[string]string{
+		"greeter/factories.go": `package greeter
+
+// ParseEnglish parses a greeting prefix.
+func ParseEnglish(s string) (*English, error) { return New(s), nil }
+
+// Pick picks a greeter.
+func Pick(formal bool) Greeter { return New("") }
+
+func Pair() (*English, *English) { return nil, nil }
+
+func WithCount() (English, int) { return English{}, 0 }
+`,
+		"greeter/factories_test.go": `package greeter
+
+func newTestEnglish() *English { return New("test: ") }
+`,
+		"welcome/welcome.go": `package welcome
+
+import "example.com/testdata/greeter"
+
+// Default returns the default greeter.
+func Default() *greeter.English { return greeter.New(greeter.DefaultPrefix) }
+`,
+		"deep/factory/factory.go": `package factory
+
+import "example.com/testdata/greeter"
+
+func Make() greeter.Greeter { return greeter.New("") }
+
+func Fail() error { return nil }
+`,
+	})
+	finder := New(idx)
+
+	t.Run("concrete type", func(t *testing.T) {
+		ctors, err := finder.FindConstructors(fixturePkg, "English")
+		require.NoError(t, err)
+		type ctor struct{ Package, Name, Interface string }
+		var got []ctor
+		for _, c := range ctors {
+			got = append(got, ctor{c.Package, c.Name, c.Interface})
+		}
+		greeterIface := fixturePkg + ".Greeter"
+		assert.Equal(t, []ctor{
+			{fixturePkg, "New", ""},
+			{fixturePkg, "ParseEnglish", ""},
+			{fixturePkg, "newTestEnglish", ""},
+			{fixturePkg, "Pick", greeterIface},
+			{welcomePkg, "Default", ""},
+			{"example.com/testdata/deep/factory", "Make", greeterIface},
+		}, got)
+
+		assert.Equal(t, symtab.Constructor{
+			Name:      "ParseEnglish",
+			Package:   fixturePkg,
+			Signature: ctors[1].Signature,
+			Doc:       "ParseEnglish parses a greeting prefix.",
+			Location:  ctors[1].Location,
+		}, ctors[1])
+		assert.Contains(t, ctors[1].Signature, "ParseEnglish(s string)")
+		assert.True(t, ctors[2].IsTest)
+	})
+
+	t.Run("interface type", func(t *testing.T) {
+		ctors, err := finder.FindConstructors(fixturePkg, "Greeter")
+		require.NoError(t, err)
+		var names []string
+		for _, c := range ctors {
+			names = append(names, c.Name)
+			assert.Empty(t, c.Interface)
+		}
+		assert.Equal(t, []string{"Pick", "Make"}, names)
+	})
+
+	t.Run("not a type", func(t *testing.T) {
+		_, err := finder.FindConstructors(fixturePkg, "New")
+		assert.ErrorContains(t, err, "is not a type")
+	})
+
+	t.Run("unknown type", func(t *testing.T) {
+		_, err := finder.FindConstructors(fixturePkg, "Missing")
+		assert.ErrorContains(t, err, `symbol "Missing" not found`)
+	})
+}
@@ -1,8 +1,12 @@
 package finder
 
 import (
+	"cmp"
+	"errors"
 	"fmt"
 	"go/types"
+	"maps"
+	"slices"
 	"strings"
 
 	"github.com/tender-barbarian/go-llm-lens/internal/indexer"
@@ -16,32 +20,36 @@ const (
 	MatchExact    MatchMode = "exact"
 	MatchPrefix   MatchMode = "prefix"
 	MatchContains MatchMode = "contains"
+	// MatchFuzzy matches names containing the query's characters in order,
+	// such as "NHS" or "NewServ" for NewHTTPServer. It ignores case.
+	MatchFuzzy MatchMode = "fuzzy"
+	// MatchRegex matches names against the query as an RE2 regular
+	// expression, such as ^(New|Must)[A-Z].*Client$. It is unanchored, so
+	// use ^ and $ to match whole names.
+	MatchRegex MatchMode = "regex"
 )
 
 // Validate returns an error if m is not a recognised MatchMode.
 func (m MatchMode) Validate() error {
 	switch m {
-	case MatchExact, MatchPrefix, MatchContains:
+	case MatchExact, MatchPrefix, MatchContains, MatchFuzzy, MatchRegex:
 		return nil
 	default:
-		return fmt.Errorf("unknown match mode %q: must be one of exact, prefix, contains", m)
+		return fmt.Errorf("unknown match mode %q: must be one of exact, prefix, contains, fuzzy, regex", m)
 	}
 }
 
-func matchesQuery(symbolName, query string, mode MatchMode) bool {
-	switch mode {
-	case MatchPrefix:
-		return strings.HasPrefix(symbolName, query)
-	case MatchContains:
-		return strings.Contains(symbolName, query)
-	default: // MatchExact, and the zero value "" which callers treat as exact
-		return symbolName == query
-	}
-}
+// errPartialIndex is returned by queries that need type information while the
+// index is still a partial snapshot restored from the on-disk cache.
+var errPartialIndex = errors.New("type information is still loading; retry once index_status reports partial=false")
 
 // Finder queries an Indexer for symbols and type relationships across indexed packages.
+// Every method reads a single index snapshot from start to finish, so results are
+// consistent even while the Indexer is being rebuilt concurrently.
 type Finder struct {
-	idx *indexer.Indexer
+	idx    *indexer.Indexer
+	search searchCache
+	calls  hierarchyCache
 }
 
 // New creates a Finder backed by the given Indexer.
@@ -49,77 +57,101 @@ func New(idx *indexer.Indexer) *Finder {
 	return &Finder{idx: idx}
 }
 
+// Generation returns the generation of the index snapshot that queries are
+// currently answered from. Results of queries made while it stays the same
+// are consistent with each other.
+func (f *Finder) Generation() uint64 {
+	return f.idx.Snapshot().Generation()
+}
+
 // FindSymbol searches for symbols matching name across all indexed packages.
 // It matches package-level functions, types, variables, constants, and methods.
-// mode controls how name is compared: exact (default), prefix, or contains.
-// All matches are returned; the caller can filter by Kind or Package.
-func (f *Finder) FindSymbol(name string, mode MatchMode) []symtab.SymbolRef {
-	var refs []symtab.SymbolRef
-	for _, pkg := range f.idx.PkgInfos() {
-		refs = append(refs, refsFromFuncs(pkg, name, mode)...)
-		refs = append(refs, refsFromTypes(pkg, name, mode)...)
-		refs = append(refs, refsFromVars(pkg, name, mode)...)
+// mode controls how name is compared: exact (default), prefix, contains,
+// fuzzy, or regex; ignoreCase makes all but fuzzy case-insensitive. All matches
+// are returned, best first: by how closely they match, then exported symbols
+// and those of shallower packages first. The caller can filter by Kind or
+// Package. It fails only for a regular expression that does not compile or
+// exceeds the size limits.
+func (f *Finder) FindSymbol(name string, mode MatchMode, ignoreCase bool) ([]symtab.SymbolRef, error) {
+	q, err := newSymbolQuery(name, mode, ignoreCase)
+	if err != nil {
+		return nil, err
 	}
-	return refs
+	var matches []symbolMatch
+	for _, pkg := range f.idx.Snapshot().PkgInfos() {
+		depth := packageDepth(pkg)
+		for _, refs := range [][]symtab.SymbolRef{refsFromFuncs(pkg, q), refsFromTypes(pkg, q), refsFromVars(pkg, q)} {
+			for _, ref := range refs {
+				matches = append(matches, symbolMatch{ref: ref, quality: q.quality(ref.Name), depth: depth})
+			}
+		}
+	}
+	slices.SortFunc(matches, compareMatches)
+	refs := make([]symtab.SymbolRef, 0, len(matches))
+	for _, m := range matches {
+		refs = append(refs, m.ref)
+	}
+	return refs, nil
 }
 
-// refsFromFuncs returns symtab.SymbolRefs for package-level functions matching name.
-func refsFromFuncs(pkg *symtab.PackageInfo, name string, mode MatchMode) []symtab.SymbolRef {
+// refsFromFuncs returns symtab.SymbolRefs for package-level functions matching q.
+func refsFromFuncs(pkg *symtab.PackageInfo, q symbolQuery) []symtab.SymbolRef {
 	var refs []symtab.SymbolRef
 	for i := range pkg.Funcs {
 		f := &pkg.Funcs[i]
-		if !matchesQuery(f.Name, name, mode) {
+		if !q.matches(f.Name) {
 			continue
 		}
-		refs = append(refs, symtab.SymbolRef{
-			Name:      f.Name,
-			Package:   pkg.ImportPath,
-			Kind:      symtab.SymbolKindFunc,
-			Signature: f.Signature,
-			Location:  f.Location,
-		})
+		refs = append(refs, funcRef(pkg.ImportPath, f, symtab.SymbolKindFunc))
 	}
 	return refs
 }
 
-// refsFromTypes returns symtab.SymbolRefs for named types and their methods matching name.
-func refsFromTypes(pkg *symtab.PackageInfo, name string, mode MatchMode) []symtab.SymbolRef {
+// funcRef returns the symtab.SymbolRef of a function or method of package pkgPath.
+func funcRef(pkgPath string, f *symtab.FuncInfo, kind symtab.SymbolKind) symtab.SymbolRef {
+	return symtab.SymbolRef{
+		Name:      f.Name,
+		Package:   pkgPath,
+		Kind:      kind,
+		Receiver:  f.Receiver,
+		Signature: f.Signature,
+		IsTest:    f.IsTest,
+		Location:  f.Location,
+	}
+}
+
+// refsFromTypes returns symtab.SymbolRefs for named types and their methods matching q.
+func refsFromTypes(pkg *symtab.PackageInfo, q symbolQuery) []symtab.SymbolRef {
 	var refs []symtab.SymbolRef
 	for i := range pkg.Types {
 		t := &pkg.Types[i]
-		if matchesQuery(t.Name, name, mode) {
+		if q.matches(t.Name) {
 			refs = append(refs, symtab.SymbolRef{
 				Name:     t.Name,
 				Package:  pkg.ImportPath,
 				Kind:     symtab.SymbolKindType,
+				IsTest:   t.IsTest,
 				Location: t.Location,
 			})
 		}
 		for j := range t.Methods {
 			m := &t.Methods[j]
 			// Skip promoted methods — they belong to the embedded type, not this one.
-			if m.IsPromoted || !matchesQuery(m.Name, name, mode) {
+			if m.IsPromoted || !q.matches(m.Name) {
 				continue
 			}
-			refs = append(refs, symtab.SymbolRef{
-				Name:      m.Name,
-				Package:   pkg.ImportPath,
-				Kind:      symtab.SymbolKindMethod,
-				Receiver:  m.Receiver,
-				Signature: m.Signature,
-				Location:  m.Location,
-			})
+			refs = append(refs, funcRef(pkg.ImportPath, m, symtab.SymbolKindMethod))
 		}
 	}
 	return refs
 }
 
-// refsFromVars returns symtab.SymbolRefs for package-level variables and constants matching name.
-func refsFromVars(pkg *symtab.PackageInfo, name string, mode MatchMode) []symtab.SymbolRef {
+// refsFromVars returns symtab.SymbolRefs for package-level variables and constants matching q.
+func refsFromVars(pkg *symtab.PackageInfo, q symbolQuery) []symtab.SymbolRef {
 	var refs []symtab.SymbolRef
 	for i := range pkg.Vars {
 		v := &pkg.Vars[i]
-		if !matchesQuery(v.Name, name, mode) {
+		if !q.matches(v.Name) {
 			continue
 		}
 		kind := symtab.SymbolKindVar
@@ -130,6 +162,7 @@ func refsFromVars(pkg *symtab.PackageInfo, name string, mode MatchMode) []symtab
 			Name:     v.Name,
 			Package:  pkg.ImportPath,
 			Kind:     kind,
+			IsTest:   v.IsTest,
 			Location: v.Location,
 		})
 	}
@@ -138,8 +171,14 @@ func refsFromVars(pkg *symtab.PackageInfo, name string, mode MatchMode) []symtab
 
 // FindImplementations returns all concrete types in the indexed codebase that implement
 // the named interface. It uses symtab.Implements for precise, type-system-accurate results.
+// Types declared in _test.go files are included and marked with IsTest. They
+// are sorted by package and name.
 func (f *Finder) FindImplementations(pkgPath, ifaceName string) ([]symtab.TypeInfo, error) {
-	typePkgs := f.idx.TypePkgs()
+	snap := f.idx.Snapshot()
+	if snap.Partial() {
+		return nil, errPartialIndex
+	}
+	typePkgs := snap.TypePkgs()
 
 	typPkg, ok := typePkgs[pkgPath]
 	if !ok {
@@ -161,41 +200,378 @@ func (f *Finder) FindImplementations(pkgPath, ifaceName string) ([]symtab.TypeIn
 		return nil, fmt.Errorf("%q is not an interface type", ifaceName)
 	}
 
+	// Types of tests are checked against their own copy of the interface.
+	testIfaces := make(map[*types.Package]*types.Interface)
 	var result []symtab.TypeInfo
-	for _, pkgInfo := range f.idx.PkgInfos() {
+	eachType(snap, func(ti symtab.TypeInfo, tn *types.TypeName) {
+		if ti.Kind == symtab.TypeKindInterface || ti.Kind == symtab.TypeKindConstraint {
+			return
+		}
+		target := iface
+		if ti.IsTest {
+			if target, ok = testIfaces[tn.Pkg()]; !ok {
+				target, _ = asSeenFrom(tn.Pkg(), obj).Type().Underlying().(*types.Interface)
+				testIfaces[tn.Pkg()] = target
+			}
+		}
+		if target != nil && implements(tn.Type(), target) {
+			result = append(result, ti)
+		}
+	})
+	slices.SortFunc(result, func(a, b symtab.TypeInfo) int {
+		return cmp.Or(strings.Compare(a.Package, b.Package), strings.Compare(a.Name, b.Name))
+	})
+	return result, nil
+}
+
+// FindInterfacesForType returns the interfaces that the named type of package
+// pkgPath satisfies, through its value or only through a pointer to it. They
+// include every interface declared in the indexed packages, the exported
+// interfaces of the dependencies and standard library packages loaded with
+// them, and error. Internal and vendored dependencies, interfaces without
+// methods, which every type satisfies, and type constraints are left out.
+func (f *Finder) FindInterfacesForType(pkgPath, typeName string) ([]symtab.InterfaceMatch, error) {
+	snap := f.idx.Snapshot()
+	if snap.Partial() {
+		return nil, errPartialIndex
+	}
+	obj, err := lookupObject(snap, pkgPath, typeName)
+	if err != nil {
+		return nil, err
+	}
+	tn, ok := obj.(*types.TypeName)
+	if !ok {
+		return nil, fmt.Errorf("%q is not a type", typeName)
+	}
+	T := tn.Type()
+	if types.IsInterface(T) {
+		return nil, fmt.Errorf("%q is an interface type", typeName)
+	}
+	if named, ok := types.Unalias(T).(*types.Named); ok && named.TypeParams().Len() > 0 {
+		return nil, fmt.Errorf("%q is a generic type and only its instantiations implement interfaces", typeName)
+	}
+
+	var result []symtab.InterfaceMatch
+	match := func(itn *types.TypeName, m symtab.InterfaceMatch) {
+		iface, ok := itn.Type().Underlying().(*types.Interface)
+		if !ok || iface.NumMethods() == 0 || !iface.IsMethodSet() {
+			return
+		}
+		switch {
+		case types.Implements(T, iface):
+		case types.Implements(types.NewPointer(T), iface):
+			m.PointerOnly = true
+		default:
+			return
+		}
+		result = append(result, m)
+	}
+
+	eachType(snap, func(ti symtab.TypeInfo, itn *types.TypeName) {
+		if ti.Kind == symtab.TypeKindInterface {
+			match(itn, symtab.InterfaceMatch{Name: ti.Name, Package: ti.Package, IsTest: ti.IsTest, Doc: ti.Doc, Location: ti.Location})
+		}
+	})
+	indexed := snap.PkgInfos()
+	for path, tp := range snap.TypePkgs() {
+		if _, ok := indexed[path]; ok || unimportable(path) {
+			continue
+		}
+		scope := tp.Scope()
+		for _, name := range scope.Names() {
+			itn, ok := scope.Lookup(name).(*types.TypeName)
+			if !ok || !itn.Exported() {
+				continue
+			}
+			pos := snap.Position(itn.Pos())
+			match(itn, symtab.InterfaceMatch{Name: name, Package: path, Location: symtab.Location{File: pos.Filename, Line: pos.Line, Column: pos.Column}})
+		}
+	}
+	match(types.Universe.Lookup("error").(*types.TypeName), symtab.InterfaceMatch{Name: "error"})
+
+	slices.SortFunc(result, func(a, b symtab.InterfaceMatch) int {
+		return cmp.Or(strings.Compare(a.Package, b.Package), strings.Compare(a.Name, b.Name))
+	})
+	return result, nil
+}
+
+// unimportable reports whether the indexed packages cannot import the
+// dependency at path themselves: an internal or vendored package.
+func unimportable(path string) bool {
+	for elem := range strings.SplitSeq(path, "/") {
+		if elem == "internal" || elem == "vendor" {
+			return true
+		}
+	}
+	return false
+}
+
+// eachType calls fn for every named type of every indexed package, together
+// with its type-checked declaration.
+func eachType(snap *indexer.Snapshot, fn func(ti symtab.TypeInfo, tn *types.TypeName)) {
+	typePkgs := snap.TypePkgs()
+	for _, pkgInfo := range snap.PkgInfos() {
 		tp, ok := typePkgs[pkgInfo.ImportPath]
 		if !ok {
 			continue
 		}
+		// Types declared in in-package _test.go files only exist in the test variant.
+		testTp, hasTestTypes := snap.TestTypePkgs()[pkgInfo.ImportPath]
 		for _, ti := range pkgInfo.Types {
-			if ti.Kind == symtab.TypeKindInterface {
-				continue
+			scope := tp.Scope()
+			if ti.IsTest && hasTestTypes {
+				scope = testTp.Scope()
 			}
-			obj2 := tp.Scope().Lookup(ti.Name)
-			if obj2 == nil {
-				continue
+			if tn, ok := scope.Lookup(ti.Name).(*types.TypeName); ok {
+				fn(ti, tn)
+			}
+		}
+	}
+}
+
+// asSeenFrom returns obj, a package-level object, as the packages type-checked
+// together with pkg declare it. A test variant is checked with its own copy of
+// the package under test, so types of tests only implement the interfaces, and
+// mention the types, of that copy. It returns obj itself when pkg does not
+// import obj's package, directly or not.
+func asSeenFrom(pkg *types.Package, obj types.Object) types.Object {
+	seen := map[*types.Package]bool{pkg: true}
+	queue := []*types.Package{pkg}
+	for len(queue) > 0 {
+		p := queue[0]
+		queue = queue[1:]
+		if p.Path() == obj.Pkg().Path() {
+			if seenObj := p.Scope().Lookup(obj.Name()); seenObj != nil {
+				return seenObj
 			}
-			T := obj2.Type()
-			if types.Implements(T, iface) || types.Implements(types.NewPointer(T), iface) {
-				result = append(result, ti)
+			return obj
+		}
+		for _, imp := range p.Imports() {
+			if !seen[imp] {
+				seen[imp] = true
+				queue = append(queue, imp)
 			}
 		}
 	}
+	return obj
+}
+
+// implements reports whether T or *T implements iface.
+func implements(T types.Type, iface *types.Interface) bool {
+	return types.Implements(T, iface) || types.Implements(types.NewPointer(T), iface)
+}
+
+// FindReferences returns every use of a symbol across the indexed packages,
+// ordered by file and position. symbol names a package-level function, type,
+// variable, or constant, or a method or field as TypeName.Member; a promoted
+// member resolves to the declaration it is promoted from. The declaration
+// itself is not a use.
+func (f *Finder) FindReferences(pkgPath, symbol string) ([]symtab.Reference, error) {
+	snap := f.idx.Snapshot()
+	if snap.Partial() {
+		return nil, errPartialIndex
+	}
+	obj, err := lookupObject(snap, pkgPath, symbol)
+	if err != nil {
+		return nil, err
+	}
+	return references(snap, obj, symbol)
+}
+
+// FindInstantiations returns the uses of a generic function or type that
+// instantiate it, explicitly or with inferred type arguments, ordered by file
+// and position. Each carries its TypeArgs. Uses within the generic declaration
+// itself, which pass on its own type parameters, are included too.
+func (f *Finder) FindInstantiations(pkgPath, symbol string) ([]symtab.Reference, error) {
+	snap := f.idx.Snapshot()
+	if snap.Partial() {
+		return nil, errPartialIndex
+	}
+	obj, err := lookupObject(snap, pkgPath, symbol)
+	if err != nil {
+		return nil, err
+	}
+	if !isGeneric(obj) {
+		return nil, fmt.Errorf("%q is not a generic function or type", symbol)
+	}
+	refs, err := references(snap, obj, symbol)
+	if err != nil {
+		return nil, err
+	}
+	return slices.DeleteFunc(refs, func(r symtab.Reference) bool { return r.TypeArgs == nil }), nil
+}
+
+// isGeneric reports whether obj is a function or type with type parameters.
+func isGeneric(obj types.Object) bool {
+	switch o := obj.(type) {
+	case *types.Func:
+		return o.Signature().TypeParams().Len() > 0
+	case *types.TypeName:
+		switch t := o.Type().(type) {
+		case *types.Named:
+			return t.TypeParams().Len() > 0
+		case *types.Alias:
+			return t.TypeParams().Len() > 0
+		}
+	}
+	return false
+}
+
+// references returns the recorded uses of obj, named symbol, ordered by file
+// and position.
+func references(snap *indexer.Snapshot, obj types.Object, symbol string) ([]symtab.Reference, error) {
+	key, err := indexer.SymbolKey(obj)
+	if err != nil {
+		return nil, fmt.Errorf("symbol %q has no references: %w", symbol, err)
+	}
+
+	var result []symtab.Reference
+	for _, pkg := range snap.PkgInfos() {
+		result = append(result, pkg.Refs[key]...)
+	}
+	slices.SortFunc(result, func(a, b symtab.Reference) int {
+		if c := strings.Compare(a.Location.File, b.Location.File); c != 0 {
+			return c
+		}
+		if a.Location.Line != b.Location.Line {
+			return a.Location.Line - b.Location.Line
+		}
+		return a.Location.Column - b.Location.Column
+	})
 	return result, nil
 }
 
-// GetPackages returns all indexed packages.
+// lookupObject resolves symbol, a package-level name or TypeName.Member, in the
+// package pkgPath of snap. Names declared in the package's _test.go files are
+// found too.
+func lookupObject(snap *indexer.Snapshot, pkgPath, symbol string) (types.Object, error) {
+	name, member, isMember := strings.Cut(symbol, ".")
+	found := false
+	var obj types.Object
+	for _, tp := range []*types.Package{snap.TypePkgs()[pkgPath], snap.TestTypePkgs()[pkgPath]} {
+		if tp == nil {
+			continue
+		}
+		found = true
+		if obj = tp.Scope().Lookup(name); obj != nil {
+			break
+		}
+	}
+	if !found {
+		return nil, fmt.Errorf("package %q not found in index", pkgPath)
+	}
+	if obj == nil {
+		return nil, fmt.Errorf("symbol %q not found in package %q", name, pkgPath)
+	}
+	if !isMember {
+		return obj, nil
+	}
+
+	tn, ok := obj.(*types.TypeName)
+	if !ok {
+		return nil, fmt.Errorf("%q is not a type", name)
+	}
+	m, _, _ := types.LookupFieldOrMethod(tn.Type(), true, tn.Pkg(), member)
+	if m == nil {
+		return nil, fmt.Errorf("type %q has no field or method %q", name, member)
+	}
+	return m, nil
+}
+
+// GetPackages returns all indexed packages, sorted by import path.
 func (f *Finder) GetPackages() []*symtab.PackageInfo {
-	pkgs := f.idx.PkgInfos()
+	pkgs := f.idx.Snapshot().PkgInfos()
 	result := make([]*symtab.PackageInfo, 0, len(pkgs))
-	for _, p := range pkgs {
-		result = append(result, p)
+	for _, path := range slices.Sorted(maps.Keys(pkgs)) {
+		result = append(result, pkgs[path])
 	}
 	return result
 }
 
 // GetPackage returns a package by import path.
 func (f *Finder) GetPackage(importPath string) (*symtab.PackageInfo, bool) {
-	p, ok := f.idx.PkgInfos()[importPath]
+	p, ok := f.idx.Snapshot().PkgInfos()[importPath]
 	return p, ok
 }
+
+// GetEnum returns the constants declared of the named type typeName of package
+// pkgPath, across every indexed package, in declaration order: those of the
+// type's own package first, then those of other packages by import path.
+func (f *Finder) GetEnum(pkgPath, typeName string) (*symtab.EnumInfo, error) {
+	pkgInfos := f.idx.Snapshot().PkgInfos()
+	pkg, ok := pkgInfos[pkgPath]
+	if !ok {
+		return nil, fmt.Errorf("package %q not found in index", pkgPath)
+	}
+	i := slices.IndexFunc(pkg.Types, func(t symtab.TypeInfo) bool { return t.Name == typeName })
+	if i < 0 {
+		return nil, fmt.Errorf("type %q not found in package %q", typeName, pkgPath)
+	}
+	ti := pkg.Types[i]
+	if ti.Kind != symtab.TypeKindOther {
+		return nil, fmt.Errorf("%q has kind %s and cannot have constants", typeName, ti.Kind)
+	}
+
+	enum := &symtab.EnumInfo{
+		Name:    ti.Name,
+		Package: ti.Package,
+		HasString: slices.ContainsFunc(ti.Methods, func(m symtab.FuncInfo) bool {
+			return m.Name == "String" && strings.HasSuffix(m.Signature, ") String() string")
+		}),
+		Doc:    ti.Doc,
+		Consts: []symtab.VarInfo{},
+	}
+	typeString := pkgPath + "." + typeName
+	others := slices.DeleteFunc(slices.Sorted(maps.Keys(pkgInfos)), func(path string) bool { return path == pkgPath })
+	paths := append([]string{pkgPath}, others...)
+	for _, path := range paths {
+		var consts []symtab.VarInfo
+		for _, v := range pkgInfos[path].Vars {
+			if v.IsConst && v.Type == typeString {
+				consts = append(consts, v)
+			}
+		}
+		slices.SortStableFunc(consts, func(a, b symtab.VarInfo) int {
+			return cmp.Or(strings.Compare(a.Location.File, b.Location.File), a.Location.Line-b.Location.Line)
+		})
+		enum.Consts = append(enum.Consts, consts...)
+	}
+	return enum, nil
+}
+
+// ListTests returns the test, benchmark, fuzz, and example functions of a package,
+// including those in its external test package, ordered by file and line.
+// pkgPath may also name the external test package itself.
+func (f *Finder) ListTests(pkgPath string) ([]symtab.FuncInfo, error) {
+	pkgInfos := f.idx.Snapshot().PkgInfos()
+	paths := []string{pkgPath}
+	if !strings.HasSuffix(pkgPath, "_test") {
+		paths = append(paths, pkgPath+"_test")
+	}
+
+	found := false
+	var result []symtab.FuncInfo
+	for _, path := range paths {
+		pkg, ok := pkgInfos[path]
+		if !ok {
+			continue
+		}
+		found = true
+		for _, fn := range pkg.Funcs {
+			if fn.TestKind != "" {
+				result = append(result, fn)
+			}
+		}
+	}
+	if !found {
+		return nil, fmt.Errorf("package %q not found in index", pkgPath)
+	}
+
+	slices.SortFunc(result, func(a, b symtab.FuncInfo) int {
+		if c := strings.Compare(a.Location.File, b.Location.File); c != 0 {
+			return c
+		}
+		return a.Location.Line - b.Location.Line
+	})
+	return result, nil
+}
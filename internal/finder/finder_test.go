@@ -1,7 +1,12 @@
 package finder
 
 import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -11,6 +16,24 @@ import (
 
 const fixturePkg = "example.com/testdata/greeter"
 
+// indexFixture indexes a copy of the fixture module with the extra files
+// added, keyed by their path relative to the root, and returns the indexer and
+// the copy's root.
+func indexFixture(t *testing.T, extra map[string]string) (*indexer.Indexer, string) {
+	t.Helper()
+	root := t.TempDir()
+	require.NoError(t, os.CopyFS(root, os.DirFS("../../tests/testdata")))
+	for name, content := range extra {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o750))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	}
+	idx, err := indexer.New(root)
+	require.NoError(t, err)
+	require.NoError(t, idx.Index())
+	return idx, root
+}
+
 func TestFindSymbol(t *testing.T) {
 	idx, err := indexer.New("../../tests/testdata")
 	require.NoError(t, err)
@@ -20,6 +43,7 @@ func TestFindSymbol(t *testing.T) {
 	tests := []struct {
 		symbol            string
 		mode              MatchMode
+		ignoreCase        bool
 		expectedLen       int               // expected number of results; 0 means expect empty
 		expectedKind      symtab.SymbolKind // empty → expect no results
 		expectedSigHas    string            // substring checked in every Signature when non-empty
@@ -34,11 +58,16 @@ func TestFindSymbol(t *testing.T) {
 		{symbol: "ThisSymbolDefinitelyDoesNotExist"},
 		{symbol: "Engl", mode: MatchPrefix, expectedLen: 1, expectedKind: symtab.SymbolKindType},
 		{symbol: "Length", mode: MatchContains, expectedLen: 1, expectedKind: symtab.SymbolKindVar},
+		{symbol: "english"},
+		{symbol: "english", ignoreCase: true, expectedLen: 1, expectedKind: symtab.SymbolKindType},
+		{symbol: "engl", mode: MatchPrefix, ignoreCase: true, expectedLen: 1, expectedKind: symtab.SymbolKindType},
+		{symbol: "maxlen", mode: MatchFuzzy, expectedLen: 1, expectedKind: symtab.SymbolKindVar},
 	}
 
 	for _, tt := range tests {
-		t.Run(tt.symbol, func(t *testing.T) {
-			refs := finder.FindSymbol(tt.symbol, tt.mode)
+		t.Run(fmt.Sprintf("%s/%s/%t", tt.symbol, tt.mode, tt.ignoreCase), func(t *testing.T) {
+			refs, err := finder.FindSymbol(tt.symbol, tt.mode, tt.ignoreCase)
+			require.NoError(t, err)
 			if tt.expectedKind == "" {
 				assert.Empty(t, refs)
 				return
@@ -122,3 +151,412 @@ func TestFindImplementations(t *testing.T) {
 		})
 	}
 }
+
+func TestFindImplementationsTestFakes(t *testing.T) {
+	idx, _ := indexFixture(t, map[string]string{
+		"greeter/store.go":        "package greeter\n\n// Store looks up greeters.\ntype Store interface {\n\tGet(name string) (*English, error)\n}\n",
+		"greeter/greeter_test.go": "package greeter\n\ntype fakeStore struct{}\n\nfunc (fakeStore) Get(string) (*English, error) { return nil, nil }\n",
+		"greeter/example_test.go": "package greeter_test\n\nimport \"example.com/testdata/greeter\"\n\ntype externalStore struct{}\n\nfunc (*externalStore) Get(string) (*greeter.English, error) { return nil, nil }\n",
+	})
+
+	impls, err := New(idx).FindImplementations(fixturePkg, "Store")
+	require.NoError(t, err)
+	names := make([]string, len(impls))
+	for i, ti := range impls {
+		assert.True(t, ti.IsTest, ti.Name)
+		names[i] = ti.Name
+	}
+	assert.Equal(t, []string{"fakeStore", "externalStore"}, names)
+}
+
+func TestFindInterfacesForType(t *testing.T) {
+	idx, err := indexer.New("../../tests/testdata")
+	require.NoError(t, err)
+	require.NoError(t, idx.Index())
+	finder := New(idx)
+
+	type match struct {
+		pkg, name   string
+		pointerOnly bool
+	}
+	tests := []struct {
+		name        string
+		typeName    string
+		expectedErr string
+		expected    []match
+	}{
+		{
+			name:     "value receiver",
+			typeName: "Formal",
+			expected: []match{{fixturePkg, "Greeter", false}},
+		},
+		{
+			name:     "pointer receiver",
+			typeName: "English",
+			expected: []match{{fixturePkg, "Greeter", true}},
+		},
+		{
+			name:     "dependency interface through embedded field",
+			typeName: "Lockable",
+			expected: []match{{"sync", "Locker", true}},
+		},
+		{
+			name:        "interface",
+			typeName:    "Greeter",
+			expectedErr: "is an interface type",
+		},
+		{
+			name:        "not a type",
+			typeName:    "New",
+			expectedErr: "is not a type",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ifaces, err := finder.FindInterfacesForType(fixturePkg, tt.typeName)
+			if tt.expectedErr != "" {
+				assert.ErrorContains(t, err, tt.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			actual := make([]match, len(ifaces))
+			for i, m := range ifaces {
+				actual[i] = match{m.Package, m.Name, m.PointerOnly}
+			}
+			assert.Equal(t, tt.expected, actual)
+		})
+	}
+
+	t.Run("predeclared error", func(t *testing.T) {
+		idx, _ := indexFixture(t, map[string]string{"greeter/failure.go": `package greeter
+
+import "io"
+
+// Failure is an error that can also be read.
+type Failure struct{}
+
+func (Failure) Error() string { return "failure" }
+
+func (*Failure) Read(p []byte) (int, error) { return 0, nil }
+
+var _ io.Reader = (*Failure)(nil)
+`})
+
+		ifaces, err := New(idx).FindInterfacesForType(fixturePkg, "Failure")
+		require.NoError(t, err)
+		require.Len(t, ifaces, 2)
+		assert.Equal(t, symtab.InterfaceMatch{Name: "error"}, ifaces[0])
+		assert.Equal(t, "io", ifaces[1].Package)
+		assert.Equal(t, "Reader", ifaces[1].Name)
+		assert.True(t, ifaces[1].PointerOnly)
+		assert.Contains(t, ifaces[1].Location.File, "io.go")
+	})
+}
+
+func TestFinderConcurrentWithUpdate(t *testing.T) {
+	idx, root := indexFixture(t, nil)
+	finder := New(idx)
+
+	file := filepath.Join(root, "greeter", "greeter.go")
+	original, err := os.ReadFile(file) // #nosec G304 -- test fixture path
+	require.NoError(t, err)
+
+	stop := make(chan struct{})
+	var wg sync.WaitGroup
+	for range 4 {
+		wg.Go(func() {
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+				refs, err := finder.FindSymbol("Greet", MatchExact, false)
+				assert.NoError(t, err)
+				assert.NotEmpty(t, refs)
+				impls, err := finder.FindImplementations(fixturePkg, "Greeter")
+				assert.NoError(t, err)
+				assert.Len(t, impls, 3)
+				assert.Len(t, finder.GetPackages(), 1)
+			}
+		})
+	}
+
+	// Each edit grows the file so that its size changes even on coarse-mtime file systems.
+	content := original
+	for i := range 5 {
+		content = fmt.Appendf(content, "\n// Added%d is new.\nfunc Added%d() {}\n", i, i)
+		require.NoError(t, os.WriteFile(file, content, 0o600))
+		_, err := idx.Update()
+		require.NoError(t, err)
+	}
+	close(stop)
+	wg.Wait()
+
+	refs, err := finder.FindSymbol("Added4", MatchExact, false)
+	require.NoError(t, err)
+	assert.Len(t, refs, 1)
+}
+
+func TestFindImplementationsPartialIndex(t *testing.T) {
+	cacheDir := t.TempDir()
+	idx, err := indexer.New("../../tests/testdata", indexer.WithCacheDir(cacheDir))
+	require.NoError(t, err)
+	require.NoError(t, idx.Index())
+	require.NoError(t, idx.SaveCache())
+
+	restored, err := indexer.New("../../tests/testdata", indexer.WithCacheDir(cacheDir))
+	require.NoError(t, err)
+	hit, err := restored.LoadCache()
+	require.NoError(t, err)
+	require.True(t, hit)
+	finder := New(restored)
+
+	// Symbol lookups work from the cached snapshot; type-based queries ask the caller to retry.
+	refs, err := finder.FindSymbol("New", MatchExact, false)
+	require.NoError(t, err)
+	assert.Len(t, refs, 1)
+	_, err = finder.FindImplementations(fixturePkg, "Greeter")
+	assert.ErrorIs(t, err, errPartialIndex)
+	_, err = finder.FindReferences(fixturePkg, "New")
+	assert.ErrorIs(t, err, errPartialIndex)
+	_, err = finder.FindInterfacesForType(fixturePkg, "English")
+	assert.ErrorIs(t, err, errPartialIndex)
+}
+
+func TestListTests(t *testing.T) {
+	idx, _ := indexFixture(t, map[string]string{
+		"greeter/greeter_test.go": "package greeter\n\nimport \"testing\"\n\nfunc TestGreet(t *testing.T) {}\n\nfunc helper() {}\n",
+		"greeter/example_test.go": "package greeter_test\n\nfunc ExampleNew() {}\n",
+	})
+	finder := New(idx)
+
+	tests := []struct {
+		name        string
+		pkgPath     string
+		expected    []string
+		expectedErr string
+	}{
+		{name: "package with external tests", pkgPath: fixturePkg, expected: []string{"ExampleNew", "TestGreet"}},
+		{name: "external test package only", pkgPath: fixturePkg + "_test", expected: []string{"ExampleNew"}},
+		{name: "unknown package", pkgPath: "no/such/pkg", expectedErr: `package "no/such/pkg" not found in index`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual, err := finder.ListTests(tt.pkgPath)
+			if tt.expectedErr != "" {
+				assert.ErrorContains(t, err, tt.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			names := make([]string, 0, len(actual))
+			for _, fn := range actual {
+				assert.True(t, fn.IsTest)
+				names = append(names, fn.Name)
+			}
+			assert.Equal(t, tt.expected, names)
+		})
+	}
+}
+
+func TestFindReferences(t *testing.T) {
+	idx, root := indexFixture(t, map[string]string{
+		"welcome/welcome.go": `package welcome
+
+import "example.com/testdata/greeter"
+
+func Welcome(name string) string {
+	g := greeter.New(greeter.DefaultPrefix)
+	return g.Greet(name) + greeter.FormalEnglish{}.Greet(name)
+}
+`,
+		"greeter/greeter_test.go": "package greeter\n\nimport \"testing\"\n\nfunc TestNew(t *testing.T) { _ = New(\"\").Prefix }\n",
+	})
+	finder := New(idx)
+	welcome := filepath.Join(root, "welcome", "welcome.go")
+
+	type site struct {
+		File string
+		Func string
+		Kind symtab.RefKind
+	}
+	tests := []struct {
+		name        string
+		pkgPath     string
+		symbol      string
+		expected    []site
+		expectedErr string
+	}{
+		{
+			name: "function", pkgPath: fixturePkg, symbol: "New",
+			expected: []site{{"greeter_test.go", "TestNew", symtab.RefKindCall}, {"welcome.go", "Welcome", symtab.RefKindCall}},
+		},
+		{
+			name: "field", pkgPath: fixturePkg, symbol: "English.Prefix",
+			expected: []site{{"greeter.go", "English.Greet", symtab.RefKindRead}, {"greeter.go", "New", symtab.RefKindWrite}, {"greeter_test.go", "TestNew", symtab.RefKindRead}},
+		},
+		{
+			name: "method", pkgPath: fixturePkg, symbol: "English.Greet",
+			expected: []site{{"welcome.go", "Welcome", symtab.RefKindCall}},
+		},
+		{
+			name: "promoted method resolves to its declaration", pkgPath: fixturePkg, symbol: "FormalEnglish.Greet",
+			expected: []site{{"welcome.go", "Welcome", symtab.RefKindCall}},
+		},
+		{
+			name: "constant", pkgPath: fixturePkg, symbol: "DefaultPrefix",
+			expected: []site{{"welcome.go", "Welcome", symtab.RefKindRead}},
+		},
+		{name: "unused symbol", pkgPath: fixturePkg, symbol: "NoReturn"},
+		{name: "package not found", pkgPath: "no/such/package", symbol: "New", expectedErr: "not found in index"},
+		{name: "symbol not found", pkgPath: fixturePkg, symbol: "Nope", expectedErr: "not found in package"},
+		{name: "member of a non-type", pkgPath: fixturePkg, symbol: "New.Prefix", expectedErr: "is not a type"},
+		{name: "member not found", pkgPath: fixturePkg, symbol: "English.Nope", expectedErr: "has no field or method"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			refs, err := finder.FindReferences(tt.pkgPath, tt.symbol)
+			if tt.expectedErr != "" {
+				assert.ErrorContains(t, err, tt.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			var actual []site
+			for _, r := range refs {
+				assert.Equal(t, filepath.Base(r.Location.File) == "greeter_test.go", r.IsTest)
+				actual = append(actual, site{filepath.Base(r.Location.File), r.Func, r.Kind})
+			}
+			assert.Equal(t, tt.expected, actual)
+		})
+	}
+
+	t.Run("references follow updates", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(welcome, []byte("package welcome\n\nimport \"example.com/testdata/greeter\"\n\nvar w = greeter.New(\"\")\n"), 0o600))
+		future := time.Now().Add(time.Hour)
+		require.NoError(t, os.Chtimes(welcome, future, future))
+		_, err := idx.Update()
+		require.NoError(t, err)
+
+		refs, err := finder.FindReferences(fixturePkg, "English.Greet")
+		require.NoError(t, err)
+		assert.Empty(t, refs)
+		refs, err = finder.FindReferences(fixturePkg, "New")
+		require.NoError(t, err)
+		require.Len(t, refs, 2)
+		assert.Empty(t, refs[1].Func)
+	})
+}
+
+func TestFindInstantiations(t *testing.T) {
+	const genericPkg = "example.com/testdata/generic"
+
+	idx, _ := indexFixture(t, map[string]string{"generic/generic.go": `package generic
+
+func Max[T int | float64](a, b T) T {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+type List[T any] struct{ items []T }
+
+func (l *List[T]) Push(v T) { l.items = append(l.items, v) }
+
+var (
+	top   = Max(1.5, 2)
+	ints  = Max[int](1, 2)
+	names List[string]
+)
+
+func Plain() {}
+`})
+	finder := New(idx)
+
+	type site struct {
+		line     int
+		typeArgs []string
+	}
+	sites := func(refs []symtab.Reference) []site {
+		result := make([]site, 0, len(refs))
+		for _, r := range refs {
+			result = append(result, site{r.Location.Line, r.TypeArgs})
+		}
+		return result
+	}
+
+	refs, err := finder.FindInstantiations(genericPkg, "Max")
+	require.NoError(t, err)
+	assert.Equal(t, []site{{15, []string{"float64"}}, {16, []string{"int"}}}, sites(refs))
+
+	// The receiver of Push does not instantiate List.
+	refs, err = finder.FindInstantiations(genericPkg, "List")
+	require.NoError(t, err)
+	assert.Equal(t, []site{{17, []string{"string"}}}, sites(refs))
+
+	_, err = finder.FindInstantiations(genericPkg, "Plain")
+	assert.ErrorContains(t, err, "is not a generic function or type")
+}
+
+func TestGetEnum(t *testing.T) {
+	const statusPkg = "example.com/testdata/status"
+
+	idx, _ := indexFixture(t, map[string]string{
+		"status/status.go": `package status
+
+// Status is the state of an account.
+type Status int
+
+const (
+	StatusPending Status = iota
+	StatusClosed
+)
+
+const StatusActive Status = 5
+
+func (s Status) String() string { return "" }
+
+// Level has no String method.
+type Level string
+
+const Debug Level = "debug"
+
+const Untyped = 1
+`,
+		"status/extra.go": `package status
+
+const StatusArchived Status = 9
+`,
+		"legacy/legacy.go": `package legacy
+
+import "example.com/testdata/status"
+
+const StatusLegacy status.Status = -1
+`,
+	})
+	finder := New(idx)
+
+	enum, err := finder.GetEnum(statusPkg, "Status")
+	require.NoError(t, err)
+	assert.Equal(t, "Status is the state of an account.", enum.Doc)
+	assert.True(t, enum.HasString)
+	var consts []string
+	for _, c := range enum.Consts {
+		consts = append(consts, c.Name+"="+c.Value)
+	}
+	// extra.go sorts before status.go.
+	assert.Equal(t, []string{"StatusArchived=9", "StatusPending=0", "StatusClosed=1", "StatusActive=5", "StatusLegacy=-1"}, consts)
+
+	enum, err = finder.GetEnum(statusPkg, "Level")
+	require.NoError(t, err)
+	assert.False(t, enum.HasString)
+	require.Len(t, enum.Consts, 1)
+	assert.Equal(t, `"debug"`, enum.Consts[0].Value)
+
+	_, err = finder.GetEnum(fixturePkg, "English")
+	assert.ErrorContains(t, err, "has kind struct")
+	_, err = finder.GetEnum(statusPkg, "Nope")
+	assert.ErrorContains(t, err, "not found in package")
+}
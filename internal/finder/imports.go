@@ -0,0 +1,124 @@
+package finder
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/tender-barbarian/go-llm-lens/internal/symtab"
+)
+
+// importEdge is one package reached from another in the import graph.
+type importEdge struct {
+	path   string
+	isTest bool
+}
+
+// GetPackageImports returns the packages that pkgPath imports, then the ones
+// those import, and so on up to depth steps away. Packages outside the index
+// are listed but not followed, or left out entirely when internalOnly is set.
+// With includeTests, the imports of pkgPath's own _test.go files are walked
+// too and marked IsTest, along with everything reached only through them; the
+// test files of other packages are never compiled into pkgPath's and are
+// ignored.
+func (f *Finder) GetPackageImports(pkgPath string, depth int, internalOnly, includeTests bool) ([]symtab.PackageDep, error) {
+	if depth < 1 {
+		return nil, fmt.Errorf("depth must be at least 1, got %d", depth)
+	}
+	pkgInfos := f.idx.Snapshot().PkgInfos()
+	if _, ok := pkgInfos[pkgPath]; !ok {
+		return nil, fmt.Errorf("package %q not found in index", pkgPath)
+	}
+	next := func(path string) []importEdge {
+		pkg, ok := pkgInfos[path]
+		if !ok {
+			return nil
+		}
+		var edges []importEdge
+		for _, imp := range pkg.Imports {
+			edges = append(edges, importEdge{path: imp})
+		}
+		if includeTests && path == pkgPath {
+			for _, imp := range pkg.TestImports {
+				edges = append(edges, importEdge{path: imp, isTest: true})
+			}
+		}
+		if internalOnly {
+			edges = slices.DeleteFunc(edges, func(e importEdge) bool { return pkgInfos[e.path] == nil })
+		}
+		return edges
+	}
+	return walkImports(pkgInfos, pkgPath, depth, true, next), nil
+}
+
+// GetPackageImporters returns the indexed packages that import pkgPath, then
+// the ones importing those, and so on up to depth steps away. pkgPath may be a
+// package outside the index, such as a standard library package. With
+// includeTests, packages whose _test.go files import a package are listed too,
+// including external test packages, but nothing imports test code so they are
+// not followed.
+func (f *Finder) GetPackageImporters(pkgPath string, depth int, includeTests bool) ([]symtab.PackageDep, error) {
+	if depth < 1 {
+		return nil, fmt.Errorf("depth must be at least 1, got %d", depth)
+	}
+	pkgInfos := f.idx.Snapshot().PkgInfos()
+	importers := make(map[string][]importEdge)
+	for _, pkg := range pkgInfos {
+		for _, imp := range pkg.Imports {
+			importers[imp] = append(importers[imp], importEdge{path: pkg.ImportPath, isTest: pkg.IsTest})
+		}
+		for _, imp := range pkg.TestImports {
+			importers[imp] = append(importers[imp], importEdge{path: pkg.ImportPath, isTest: true})
+		}
+	}
+	if _, ok := pkgInfos[pkgPath]; !ok && importers[pkgPath] == nil {
+		return nil, fmt.Errorf("package %q not found in index or imported by it", pkgPath)
+	}
+	next := func(path string) []importEdge {
+		edges := importers[path]
+		if !includeTests {
+			edges = slices.DeleteFunc(slices.Clone(edges), func(e importEdge) bool { return e.isTest })
+		}
+		return edges
+	}
+	return walkImports(pkgInfos, pkgPath, depth, false, next), nil
+}
+
+// walkImports walks the import graph breadth first from start for depth
+// levels, following the edges next returns. Each package is listed once, at
+// the smallest depth it is reached and through the first package, in import
+// path order, that reaches it. Packages outside the index are not followed,
+// and neither are packages reached through test files unless followTests is
+// set; what those lead to is only reached through test files as well.
+func walkImports(pkgInfos map[string]*symtab.PackageInfo, start string, depth int, followTests bool, next func(path string) []importEdge) []symtab.PackageDep {
+	result := make([]symtab.PackageDep, 0)
+	seen := map[string]bool{start: true}
+	level := []symtab.PackageDep{{Package: start}}
+	for d := 1; d <= depth && len(level) > 0; d++ {
+		found := make(map[string]symtab.PackageDep)
+		for _, from := range level {
+			for _, e := range next(from.Package) {
+				isTest := e.isTest || from.IsTest
+				if dep, ok := found[e.path]; seen[e.path] || ok && (!dep.IsTest || isTest) {
+					continue // reached already, and not only through test files
+				}
+				dep := symtab.PackageDep{Package: e.path, Depth: d, External: pkgInfos[e.path] == nil, IsTest: isTest}
+				if d > 1 {
+					dep.Via = from.Package
+				}
+				found[e.path] = dep
+			}
+		}
+		var nextLevel []symtab.PackageDep
+		for _, path := range slices.Sorted(maps.Keys(found)) {
+			dep := found[path]
+			seen[path] = true
+			result = append(result, dep)
+			if !dep.External && (!dep.IsTest || followTests) {
+				nextLevel = append(nextLevel, dep)
+			}
+		}
+		level = nextLevel
+	}
+	return result
+}
@@ -0,0 +1,127 @@
+package finder
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tender-barbarian/go-llm-lens/internal/symtab"
+)
+
+const appPkg = "example.com/testdata/app"
+
+// newImportsFinder indexes a copy of the fixture module extended with two
+// layers on top of greeter: app imports welcome, which imports greeter.
+func newImportsFinder(t *testing.T) *Finder {
+	t.Helper()
+	idx, _ := indexFixture(t, map[string]string{
+		"welcome/welcome.go": `package welcome
+
+import (
+	"strings"
+
+	"example.com/testdata/greeter"
+)
+
+func Welcome(name string) string { return greeter.New("Hi, ").Greet(strings.TrimSpace(name)) }
+`,
+		"welcome/welcome_test.go": `package welcome
+
+import "testing"
+
+func TestWelcome(t *testing.T) { Welcome("test") }
+`,
+		"app/app.go": `package app
+
+import "example.com/testdata/welcome"
+
+var Banner = welcome.Welcome("all")
+`,
+	})
+	return New(idx)
+}
+
+func TestGetPackageImports(t *testing.T) {
+	finder := newImportsFinder(t)
+
+	pkg, ok := finder.GetPackage(welcomePkg)
+	require.True(t, ok)
+	assert.Equal(t, []string{fixturePkg, "strings"}, pkg.Imports)
+	assert.Equal(t, []string{"testing"}, pkg.TestImports)
+
+	tests := []struct {
+		name         string
+		depth        int
+		internalOnly bool
+		includeTests bool
+		expected     []symtab.PackageDep
+	}{
+		{
+			name:  "direct",
+			depth: 1,
+			expected: []symtab.PackageDep{
+				{Package: fixturePkg, Depth: 1},
+				{Package: "strings", Depth: 1, External: true},
+			},
+		},
+		{
+			name:  "transitive",
+			depth: 3,
+			expected: []symtab.PackageDep{
+				{Package: fixturePkg, Depth: 1},
+				{Package: "strings", Depth: 1, External: true},
+				{Package: "sync", Depth: 2, Via: fixturePkg, External: true},
+			},
+		},
+		{
+			name:         "internal only",
+			depth:        3,
+			internalOnly: true,
+			expected:     []symtab.PackageDep{{Package: fixturePkg, Depth: 1}},
+		},
+		{
+			name:         "with tests",
+			depth:        1,
+			includeTests: true,
+			expected: []symtab.PackageDep{
+				{Package: fixturePkg, Depth: 1},
+				{Package: "strings", Depth: 1, External: true},
+				{Package: "testing", Depth: 1, External: true, IsTest: true},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			deps, err := finder.GetPackageImports(welcomePkg, tt.depth, tt.internalOnly, tt.includeTests)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, deps)
+		})
+	}
+
+	_, err := finder.GetPackageImports("strings", 1, false, false)
+	assert.ErrorContains(t, err, "not found in index")
+	_, err = finder.GetPackageImports(welcomePkg, 0, false, false)
+	assert.ErrorContains(t, err, "depth must be at least 1")
+}
+
+func TestGetPackageImporters(t *testing.T) {
+	finder := newImportsFinder(t)
+
+	deps, err := finder.GetPackageImporters(fixturePkg, 5, false)
+	require.NoError(t, err)
+	assert.Equal(t, []symtab.PackageDep{
+		{Package: welcomePkg, Depth: 1},
+		{Package: appPkg, Depth: 2, Via: welcomePkg},
+	}, deps)
+
+	deps, err = finder.GetPackageImporters("testing", 1, false)
+	require.NoError(t, err)
+	assert.Empty(t, deps)
+
+	deps, err = finder.GetPackageImporters("testing", 2, true)
+	require.NoError(t, err)
+	assert.Equal(t, []symtab.PackageDep{{Package: welcomePkg, Depth: 1, IsTest: true}}, deps)
+
+	_, err = finder.GetPackageImporters("no/such/package", 1, false)
+	assert.ErrorContains(t, err, "not found in index")
+}
@@ -0,0 +1,223 @@
+package finder
+
+import (
+	"cmp"
+	"fmt"
+	"go/token"
+	"regexp"
+	"regexp/syntax"
+	"strings"
+
+	"github.com/tender-barbarian/go-llm-lens/internal/symtab"
+)
+
+// matchQuality ranks how closely a symbol name matches a query; higher is
+// closer.
+type matchQuality int
+
+const (
+	noMatch          matchQuality = iota
+	matchPattern                  // the name matches the query as a regular expression
+	matchSubsequence              // the query's characters appear in order
+	matchSubstring                // the query appears anywhere
+	matchInitials                 // the query's parts start camelCase words, as NHS in NewHTTPServer
+	matchStart                    // the query starts the name
+	matchFolded                   // the name equals the query, ignoring case
+	matchEqual                    // the name equals the query
+)
+
+// Limits on the regular expressions of MatchRegex queries. RE2 matches in
+// time linear in the size of the compiled program, so bounding it bounds the
+// cost of matching every indexed name.
+const (
+	maxRegexLen   = 512  // bytes of the expression
+	maxRegexInsts = 4096 // instructions of the compiled program
+)
+
+// symbolQuery is a name looked up by FindSymbol.
+type symbolQuery struct {
+	name       string
+	mode       MatchMode
+	ignoreCase bool
+	re         *regexp.Regexp // for MatchRegex
+}
+
+// newSymbolQuery prepares a FindSymbol query, compiling the regular
+// expression of a MatchRegex one within the size limits.
+func newSymbolQuery(name string, mode MatchMode, ignoreCase bool) (symbolQuery, error) {
+	q := symbolQuery{name: name, mode: mode, ignoreCase: ignoreCase}
+	if mode != MatchRegex {
+		return q, nil
+	}
+	if len(name) > maxRegexLen {
+		return q, fmt.Errorf("regular expression is %d bytes long, more than the maximum of %d", len(name), maxRegexLen)
+	}
+	flags := syntax.Perl
+	if ignoreCase {
+		flags |= syntax.FoldCase
+	}
+	parsed, err := syntax.Parse(name, flags)
+	if err != nil {
+		return q, err
+	}
+	prog, err := syntax.Compile(parsed.Simplify())
+	if err != nil {
+		return q, err
+	}
+	if len(prog.Inst) > maxRegexInsts {
+		return q, fmt.Errorf("regular expression is too complex: %d instructions, more than the maximum of %d", len(prog.Inst), maxRegexInsts)
+	}
+	if ignoreCase {
+		name = "(?i)" + name
+	}
+	q.re, err = regexp.Compile(name)
+	return q, err
+}
+
+// matches reports whether symbolName matches q.
+func (q symbolQuery) matches(symbolName string) bool {
+	return q.quality(symbolName) != noMatch
+}
+
+// quality returns how closely symbolName matches q, or noMatch. Each mode
+// accepts the qualities of the stricter modes too.
+func (q symbolQuery) quality(symbolName string) matchQuality {
+	if q.re != nil {
+		if q.re.MatchString(symbolName) {
+			return matchPattern
+		}
+		return noMatch
+	}
+	if symbolName == q.name {
+		return matchEqual
+	}
+	name, query := symbolName, q.name
+	if q.ignoreCase || q.mode == MatchFuzzy {
+		name, query = strings.ToLower(name), strings.ToLower(query)
+		if name == query {
+			return matchFolded
+		}
+	}
+	switch q.mode {
+	case MatchPrefix:
+		if strings.HasPrefix(name, query) {
+			return matchStart
+		}
+	case MatchContains:
+		if strings.HasPrefix(name, query) {
+			return matchStart
+		}
+		if strings.Contains(name, query) {
+			return matchSubstring
+		}
+	case MatchFuzzy:
+		switch {
+		case strings.HasPrefix(name, query):
+			return matchStart
+		case matchesInitials(nameWords(symbolName), query):
+			return matchInitials
+		case strings.Contains(name, query):
+			return matchSubstring
+		case isSubsequence(name, query):
+			return matchSubsequence
+		}
+	}
+	return noMatch
+}
+
+// nameWords splits an identifier into its lower-case camelCase and
+// underscore-separated words.
+func nameWords(name string) []string {
+	var words []string
+	for part := range strings.FieldsFuncSeq(name, func(r rune) bool { return r == '_' }) {
+		for _, w := range splitCamel(part) {
+			words = append(words, strings.ToLower(w))
+		}
+	}
+	return words
+}
+
+// matchesInitials reports whether query, in lower case, can be split into
+// parts that each start one of words, in order. Words may be skipped, so "nhs"
+// and "newserv" both match new, http, server.
+func matchesInitials(words []string, query string) bool {
+	// failed[w][q] records that query[q:] cannot be matched from words[w:].
+	failed := make(map[[2]int]bool)
+	var match func(w, q int) bool
+	match = func(w, q int) bool {
+		if q == len(query) {
+			return true
+		}
+		if w == len(words) || failed[[2]int{w, q}] {
+			return false
+		}
+		word := words[w]
+		for n := min(len(word), len(query)-q); n > 0; n-- {
+			if word[:n] == query[q:q+n] && match(w+1, q+n) {
+				return true
+			}
+		}
+		if match(w+1, q) {
+			return true
+		}
+		failed[[2]int{w, q}] = true
+		return false
+	}
+	return match(0, 0)
+}
+
+// isSubsequence reports whether the bytes of query appear in s in order.
+func isSubsequence(s, query string) bool {
+	i := 0
+	for j := 0; j < len(s) && i < len(query); j++ {
+		if s[j] == query[i] {
+			i++
+		}
+	}
+	return i == len(query)
+}
+
+// symbolMatch is a FindSymbol result with what it is ranked by.
+type symbolMatch struct {
+	ref     symtab.SymbolRef
+	quality matchQuality
+	depth   int // of its package below the module root
+}
+
+// compareMatches orders symbols best first: closer matches, then exported
+// symbols, shallower packages, non-test code, and shorter names. The rest
+// breaks ties so that the order is stable.
+func compareMatches(a, b symbolMatch) int {
+	return cmp.Or(
+		cmp.Compare(b.quality, a.quality),
+		compareBool(token.IsExported(a.ref.Name), token.IsExported(b.ref.Name)),
+		cmp.Compare(a.depth, b.depth),
+		compareBool(!a.ref.IsTest, !b.ref.IsTest),
+		cmp.Compare(len(a.ref.Name), len(b.ref.Name)),
+		strings.Compare(a.ref.Package, b.ref.Package),
+		strings.Compare(a.ref.Name, b.ref.Name),
+		strings.Compare(a.ref.Receiver, b.ref.Receiver),
+		strings.Compare(string(a.ref.Kind), string(b.ref.Kind)),
+	)
+}
+
+// compareBool orders true before false.
+func compareBool(a, b bool) int {
+	switch {
+	case a == b:
+		return 0
+	case a:
+		return -1
+	}
+	return 1
+}
+
+// packageDepth returns how many path elements pkg's import path has below its
+// module's, or below the root of the import path if it has no module.
+func packageDepth(pkg *symtab.PackageInfo) int {
+	rel, ok := strings.CutPrefix(pkg.ImportPath, pkg.Module)
+	if !ok || pkg.Module == "" {
+		return strings.Count(pkg.ImportPath, "/")
+	}
+	return strings.Count(rel, "/")
+}
@@ -0,0 +1,99 @@
+package finder
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSymbolQueryQuality(t *testing.T) {
+	tests := []struct {
+		query      string
+		mode       MatchMode
+		ignoreCase bool
+		name       string
+		expected   matchQuality
+	}{
+		{query: "Server", mode: MatchExact, name: "Server", expected: matchEqual},
+		{query: "server", mode: MatchExact, name: "Server", expected: noMatch},
+		{query: "server", mode: MatchExact, ignoreCase: true, name: "Server", expected: matchFolded},
+		{query: "new", mode: MatchPrefix, name: "NewServer", expected: noMatch},
+		{query: "new", mode: MatchPrefix, ignoreCase: true, name: "NewServer", expected: matchStart},
+		{query: "serv", mode: MatchContains, ignoreCase: true, name: "NewServer", expected: matchSubstring},
+		{query: "NHS", mode: MatchFuzzy, name: "NewHTTPServer", expected: matchInitials},
+		{query: "newserv", mode: MatchFuzzy, name: "NewHTTPServer", expected: matchInitials},
+		{query: "httpse", mode: MatchFuzzy, name: "NewHTTPServer", expected: matchInitials},
+		{query: "tpser", mode: MatchFuzzy, name: "NewHTTPServer", expected: matchSubstring},
+		{query: "nwsrv", mode: MatchFuzzy, name: "NewHTTPServer", expected: matchSubsequence},
+		{query: "maxlen", mode: MatchFuzzy, name: "max_length", expected: matchInitials},
+		{query: "NHSX", mode: MatchFuzzy, name: "NewHTTPServer", expected: noMatch},
+		{query: "NHS", mode: MatchContains, name: "NewHTTPServer", expected: noMatch},
+		{query: "^(New|Must)[A-Z].*Client$", mode: MatchRegex, name: "MustHTTPClient", expected: matchPattern},
+		{query: "^(New|Must)[A-Z].*Client$", mode: MatchRegex, name: "NewClientPool", expected: noMatch},
+		{query: "handler$", mode: MatchRegex, name: "NewHandler", expected: noMatch},
+		{query: "handler$", mode: MatchRegex, ignoreCase: true, name: "NewHandler", expected: matchPattern},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query+"/"+tt.name, func(t *testing.T) {
+			q, err := newSymbolQuery(tt.query, tt.mode, tt.ignoreCase)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, q.quality(tt.name))
+		})
+	}
+}
+
+func TestNewSymbolQueryRegexLimits(t *testing.T) {
+	tests := []struct {
+		name        string
+		query       string
+		expectedErr string
+	}{
+		{name: "invalid", query: "New(", expectedErr: "missing closing )"},
+		{name: "too long", query: strings.Repeat("a", maxRegexLen+1), expectedErr: "more than the maximum of 512"},
+		{name: "too complex", query: "(abcde){900}", expectedErr: "too complex"},
+		{name: "largest accepted", query: strings.Repeat("a", maxRegexLen)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := newSymbolQuery(tt.query, MatchRegex, false)
+			if tt.expectedErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorContains(t, err, tt.expectedErr)
+		})
+	}
+}
+
+func TestFindSymbolRanking(t *testing.T) {
+	idx, _ := indexFixture(t, map[string]string{
+		"greeter/server.go": `package greeter
+
+func NHS()              {}
+func NewHTTPServer()    {}
+func NotHandledSafely() {}
+func newHTTPServer()    {}
+`,
+		"deep/nested/server.go": `package nested
+
+func NewHTTPServer() {}
+`,
+	})
+
+	refs, err := New(idx).FindSymbol("NHS", MatchFuzzy, false)
+	require.NoError(t, err)
+	require.GreaterOrEqual(t, len(refs), 5)
+	var got []string
+	for _, r := range refs[:5] {
+		got = append(got, r.Package+"."+r.Name)
+	}
+	assert.Equal(t, []string{
+		fixturePkg + ".NHS",
+		fixturePkg + ".NewHTTPServer",
+		fixturePkg + ".NotHandledSafely",
+		"example.com/testdata/deep/nested.NewHTTPServer",
+		fixturePkg + ".newHTTPServer",
+	}, got)
+}
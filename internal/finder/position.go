@@ -0,0 +1,315 @@
+package finder
+
+import (
+	"cmp"
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"strings"
+
+	"github.com/tender-barbarian/go-llm-lens/internal/indexer"
+	"github.com/tender-barbarian/go-llm-lens/internal/symtab"
+	"golang.org/x/tools/go/ast/astutil"
+	"golang.org/x/tools/go/types/typeutil"
+)
+
+// GetDefinition resolves the identifier at the 1-based line and byte column of
+// file to the declaration of what it names: a package-level symbol, a method
+// or field, an imported package, or a local variable, parameter, or label.
+// An identifier that declares something resolves to itself. The file is
+// type-checked as it is on disk, so positions inside function bodies work too.
+func (f *Finder) GetDefinition(file string, line, column int) (*symtab.Definition, error) {
+	cf, path, err := f.checkPosition(file, line, column)
+	if err != nil {
+		return nil, err
+	}
+	id, ok := path[0].(*ast.Ident)
+	if !ok {
+		return nil, fmt.Errorf("no identifier at %s:%d:%d", file, line, column)
+	}
+	obj := identObject(cf, id)
+	if obj == nil {
+		return nil, fmt.Errorf("%q at %s:%d:%d does not name a declaration", id.Name, file, line, column)
+	}
+	return definition(cf, obj), nil
+}
+
+// GetHover describes the expression at the 1-based line and byte column of
+// file: the innermost expression there that has a type, such as a local
+// variable, a call, or a selector. For an identifier, or the name selected by a
+// selector, the object it names is described too.
+func (f *Finder) GetHover(file string, line, column int) (*symtab.Hover, error) {
+	cf, path, err := f.checkPosition(file, line, column)
+	if err != nil {
+		return nil, err
+	}
+	expr, obj := hoverTarget(cf, path)
+	if expr == nil {
+		return nil, fmt.Errorf("no expression at %s:%d:%d", file, line, column)
+	}
+
+	hover := &symtab.Hover{Expression: types.ExprString(expr)}
+	var typ types.Type
+	if tv, ok := cf.Info.Types[expr]; ok {
+		typ, hover.IsType = tv.Type, tv.IsType()
+		if tv.Value != nil {
+			hover.Value = indexer.ConstValue(tv.Value)
+		}
+	} else if obj != nil {
+		// Declaring identifiers and package names have no recorded type.
+		_, hover.IsType = obj.(*types.TypeName)
+		if _, ok := obj.(*types.PkgName); !ok {
+			typ = obj.Type()
+		}
+		if c, ok := obj.(*types.Const); ok {
+			hover.Value = indexer.ConstValue(c.Val())
+		}
+	}
+	if typ != nil {
+		hover.Type = types.TypeString(typ, nil)
+		hover.Methods = methodSet(typ)
+	}
+	if obj != nil {
+		hover.Object = definition(cf, obj)
+		hover.Doc = f.symbolDoc(hover.Object)
+	}
+	return hover, nil
+}
+
+// hoverTarget returns the expression path starts at and, if it is an
+// identifier or selector, the object it names. It returns a nil expression if
+// the innermost syntax at the position is not an expression.
+func hoverTarget(cf *indexer.CheckedFile, path []ast.Node) (ast.Expr, types.Object) {
+	if id, ok := path[0].(*ast.Ident); ok {
+		obj := identObject(cf, id)
+		// The selected name of x.f has no type of its own; x.f does.
+		if len(path) > 1 {
+			if sel, ok := path[1].(*ast.SelectorExpr); ok && sel.Sel == id {
+				if _, ok := cf.Info.Types[sel]; ok {
+					return sel, obj
+				}
+			}
+		}
+		return id, obj
+	}
+	for _, n := range path {
+		expr, ok := n.(ast.Expr)
+		if !ok {
+			break
+		}
+		if _, ok := cf.Info.Types[expr]; ok {
+			return expr, nil
+		}
+	}
+	return nil, nil
+}
+
+// methodSet lists the methods callable on a value of type t as
+// Name(params) results, sorted by name. For a type that is neither a pointer
+// nor an interface, the methods of a pointer to it are included, since they
+// can be called on any addressable value.
+func methodSet(t types.Type) []string {
+	var methods []string
+	for _, sel := range typeutil.IntuitiveMethodSet(t, nil) {
+		fn := sel.Obj().(*types.Func)
+		methods = append(methods, fn.Name()+types.TypeString(fn.Signature(), nil)[len("func"):])
+	}
+	return methods
+}
+
+// symbolDoc returns the doc comment the index records for def, or "" if def
+// is not a symbol of an indexed package. Fields have their comment instead.
+func (f *Finder) symbolDoc(def *symtab.Definition) string {
+	pkg, ok := f.idx.Snapshot().PkgInfos()[def.Package]
+	if !ok || def.Symbol == "" {
+		return ""
+	}
+	name, member, isMember := strings.Cut(def.Symbol, ".")
+	if !isMember {
+		for _, fn := range pkg.Funcs {
+			if fn.Name == name {
+				return fn.Doc
+			}
+		}
+		for _, v := range pkg.Vars {
+			if v.Name == name {
+				return cmp.Or(v.Doc, v.GroupDoc)
+			}
+		}
+	}
+	for _, t := range pkg.Types {
+		switch {
+		case t.Name != name:
+		case !isMember:
+			return t.Doc
+		case def.Kind == symtab.SymbolKindField:
+			for _, field := range t.Fields {
+				if field.Name == member {
+					return field.Comment
+				}
+			}
+		default:
+			for _, m := range t.Methods {
+				if m.Name == member {
+					return m.Doc
+				}
+			}
+		}
+	}
+	return ""
+}
+
+// checkPosition type-checks the package of file and returns the syntax
+// enclosing the given line and column, innermost first.
+func (f *Finder) checkPosition(file string, line, column int) (*indexer.CheckedFile, []ast.Node, error) {
+	if f.idx.Snapshot().Partial() {
+		return nil, nil, errPartialIndex
+	}
+	cf, err := f.idx.CheckFile(file)
+	if err != nil {
+		return nil, nil, err
+	}
+	pos, err := cf.Pos(line, column)
+	if err != nil {
+		return nil, nil, err
+	}
+	path, _ := astutil.PathEnclosingInterval(cf.File, pos, pos+1)
+	if len(path) == 0 {
+		return nil, nil, fmt.Errorf("nothing at %s:%d:%d", file, line, column)
+	}
+	return cf, path, nil
+}
+
+// identObject returns the object id declares or uses.
+func identObject(cf *indexer.CheckedFile, id *ast.Ident) types.Object {
+	if obj := cf.Info.Defs[id]; obj != nil {
+		return obj
+	}
+	if obj := cf.Info.Uses[id]; obj != nil {
+		return obj
+	}
+	// The variable declared by a type switch guard has one implicit object
+	// per case clause rather than a definition of its own.
+	for _, obj := range cf.Info.Implicits {
+		if obj.Pos() == id.Pos() {
+			return obj
+		}
+	}
+	return nil
+}
+
+// definition describes obj, found while checking cf.
+func definition(cf *indexer.CheckedFile, obj types.Object) *symtab.Definition {
+	def := &symtab.Definition{
+		Name:        obj.Name(),
+		Kind:        objectKind(obj),
+		Symbol:      symbolName(obj),
+		Declaration: types.ObjectString(obj, nil),
+	}
+	if obj.Pkg() != nil {
+		def.Package = obj.Pkg().Path()
+	}
+	if pn, ok := obj.(*types.PkgName); ok {
+		def.Package = pn.Imported().Path()
+	}
+	if obj.Pos().IsValid() {
+		start := cf.Position(obj, obj.Pos())
+		end := cf.Position(obj, obj.Pos()+token.Pos(len(obj.Name())))
+		def.IsTest = strings.HasSuffix(start.Filename, "_test.go")
+		def.Location = &symtab.Location{
+			File:   start.Filename,
+			Line:   start.Line,
+			Column: start.Column,
+			Name: &symtab.Range{
+				Start: symtab.Position{Line: start.Line, Column: start.Column, Offset: start.Offset},
+				End:   symtab.Position{Line: end.Line, Column: end.Column, Offset: end.Offset},
+			},
+		}
+	}
+	return def
+}
+
+// objectKind classifies obj.
+func objectKind(obj types.Object) symtab.SymbolKind {
+	switch o := obj.(type) {
+	case *types.Func:
+		if o.Signature().Recv() != nil {
+			return symtab.SymbolKindMethod
+		}
+		return symtab.SymbolKindFunc
+	case *types.TypeName:
+		if _, ok := o.Type().(*types.TypeParam); ok {
+			return symtab.SymbolKindTypeParam
+		}
+		return symtab.SymbolKindType
+	case *types.Const:
+		return symtab.SymbolKindConst
+	case *types.Var:
+		switch o.Kind() {
+		case types.FieldVar:
+			return symtab.SymbolKindField
+		case types.ParamVar, types.ResultVar, types.RecvVar:
+			return symtab.SymbolKindParam
+		case types.PackageVar:
+			return symtab.SymbolKindVar
+		}
+		return symtab.SymbolKindLocal
+	case *types.PkgName:
+		return symtab.SymbolKindPackage
+	case *types.Label:
+		return symtab.SymbolKindLabel
+	}
+	return symtab.SymbolKindBuiltin
+}
+
+// symbolName returns the name the other tools know obj by: its name if it is
+// declared at package level, or TypeName.Member for a method or field of a
+// package-level type. It returns "" for anything else.
+func symbolName(obj types.Object) string {
+	if obj.Pkg() == nil {
+		return ""
+	}
+	scope := obj.Pkg().Scope()
+	if obj.Parent() == scope {
+		return obj.Name()
+	}
+	switch o := obj.(type) {
+	case *types.Func:
+		if recv := o.Signature().Recv(); recv != nil {
+			if named := namedOf(recv.Type()); named != nil && named.Obj().Parent() == scope {
+				return named.Obj().Name() + "." + o.Name()
+			}
+		}
+	case *types.Var:
+		if !o.IsField() {
+			return ""
+		}
+		for _, name := range scope.Names() {
+			tn, ok := scope.Lookup(name).(*types.TypeName)
+			if !ok {
+				continue
+			}
+			st, ok := tn.Type().Underlying().(*types.Struct)
+			if !ok {
+				continue
+			}
+			for field := range st.Fields() {
+				if field == o.Origin() {
+					return tn.Name() + "." + o.Name()
+				}
+			}
+		}
+	}
+	return ""
+}
+
+// namedOf returns the named type t or *t denotes, or nil.
+func namedOf(t types.Type) *types.Named {
+	t = types.Unalias(t)
+	if ptr, ok := t.(*types.Pointer); ok {
+		t = types.Unalias(ptr.Elem())
+	}
+	named, _ := t.(*types.Named)
+	return named
+}
@@ -0,0 +1,210 @@
+package finder
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tender-barbarian/go-llm-lens/internal/symtab"
+)
+
+// newPositionFinder indexes a copy of the fixture module with a welcome
+// package whose function body uses greeter, and returns the path of its file.
+func newPositionFinder(t *testing.T) (finder *Finder, root, welcome string) {
+	t.Helper()
+	idx, root := indexFixture(t, map[string]string{"welcome/welcome.go": `package welcome
+
+import (
+	"fmt"
+
+	"example.com/testdata/greeter"
+)
+
+// Welcome greets a newcomer.
+func Welcome(name string) string {
+	g := greeter.New("Welcome, ")
+	g.Prefix = "Hi, "
+	count := len(name)
+	return fmt.Sprint(g.Greet(name), count)
+}
+
+const limit = 2 * 10
+var fallback greeter.Formal
+`})
+	return New(idx), root, filepath.Join(root, "welcome", "welcome.go")
+}
+
+func TestGetDefinition(t *testing.T) {
+	finder, root, welcome := newPositionFinder(t)
+	greeterFile := filepath.Join(root, "greeter", "greeter.go")
+
+	tests := []struct {
+		name         string
+		line, column int
+		kind         symtab.SymbolKind
+		pkg          string
+		symbol       string
+		file         string // "" for no location
+		declLine     int
+	}{
+		{name: "local variable", line: 14, column: 35, kind: symtab.SymbolKindLocal, pkg: welcomePkg, file: welcome, declLine: 13},
+		{name: "declaring identifier", line: 11, column: 2, kind: symtab.SymbolKindLocal, pkg: welcomePkg, file: welcome, declLine: 11},
+		{name: "parameter", line: 14, column: 28, kind: symtab.SymbolKindParam, pkg: welcomePkg, file: welcome, declLine: 10},
+		{name: "function", line: 10, column: 8, kind: symtab.SymbolKindFunc, pkg: welcomePkg, symbol: "Welcome", file: welcome, declLine: 10},
+		{name: "imported function", line: 11, column: 15, kind: symtab.SymbolKindFunc, pkg: fixturePkg, symbol: "New", file: greeterFile, declLine: 38},
+		{name: "field", line: 12, column: 4, kind: symtab.SymbolKindField, pkg: fixturePkg, symbol: "English.Prefix", file: greeterFile, declLine: 15},
+		{name: "method", line: 14, column: 22, kind: symtab.SymbolKindMethod, pkg: fixturePkg, symbol: "English.Greet", file: greeterFile, declLine: 19},
+		{name: "package name", line: 14, column: 9, kind: symtab.SymbolKindPackage, pkg: "fmt", file: welcome, declLine: 4},
+		{name: "builtin", line: 13, column: 11, kind: symtab.SymbolKindBuiltin},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			def, err := finder.GetDefinition(welcome, tt.line, tt.column)
+			require.NoError(t, err)
+			assert.Equal(t, tt.kind, def.Kind)
+			assert.Equal(t, tt.pkg, def.Package)
+			assert.Equal(t, tt.symbol, def.Symbol)
+			if tt.file == "" {
+				assert.Nil(t, def.Location)
+				return
+			}
+			require.NotNil(t, def.Location)
+			assert.Equal(t, tt.file, def.Location.File)
+			assert.Equal(t, tt.declLine, def.Location.Line)
+		})
+	}
+
+	t.Run("relative path", func(t *testing.T) {
+		def, err := finder.GetDefinition(filepath.Join("welcome", "welcome.go"), 14, 35)
+		require.NoError(t, err)
+		assert.Equal(t, "count", def.Name)
+		assert.Equal(t, "var count int", def.Declaration)
+	})
+
+	for name, pos := range map[string][2]int{
+		"not an identifier":    {11, 19},
+		"line out of range":    {99, 1},
+		"column past the line": {12, 40},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := finder.GetDefinition(welcome, pos[0], pos[1])
+			assert.Error(t, err)
+		})
+	}
+
+	t.Run("file not indexed", func(t *testing.T) {
+		_, err := finder.GetDefinition(filepath.Join(root, "missing.go"), 1, 1)
+		assert.ErrorContains(t, err, "not part of any indexed package")
+	})
+}
+
+func TestGetHover(t *testing.T) {
+	finder, _, welcome := newPositionFinder(t)
+	english := fixturePkg + ".English"
+
+	tests := []struct {
+		name         string
+		line, column int
+		expected     symtab.Hover // Object is checked by its kind only
+		kind         symtab.SymbolKind
+		methods      []string // a subset of the expected methods
+	}{
+		{
+			name: "local variable", line: 14, column: 20,
+			expected: symtab.Hover{Expression: "g", Type: "*" + english},
+			kind:     symtab.SymbolKindLocal,
+			methods:  []string{"Greet(name string) string", "BlankReceiver()"},
+		},
+		{
+			name: "call", line: 14, column: 27,
+			expected: symtab.Hover{Expression: "g.Greet(name)", Type: "string"},
+		},
+		{
+			name: "method", line: 14, column: 22,
+			expected: symtab.Hover{Expression: "g.Greet", Type: "func(name string) string", Doc: "Greet returns a greeting."},
+			kind:     symtab.SymbolKindMethod,
+		},
+		{
+			name: "field", line: 12, column: 4,
+			expected: symtab.Hover{Expression: "g.Prefix", Type: "string", Doc: "Prefix is prepended to the name."},
+			kind:     symtab.SymbolKindField,
+		},
+		{
+			name: "imported function", line: 11, column: 15,
+			expected: symtab.Hover{Expression: "greeter.New", Type: "func(prefix string) *" + english, Doc: "New returns an English greeter with the given prefix."},
+			kind:     symtab.SymbolKindFunc,
+		},
+		{
+			name: "declared constant", line: 17, column: 7,
+			expected: symtab.Hover{Expression: "limit", Type: "untyped int", Value: "20"},
+			kind:     symtab.SymbolKindConst,
+		},
+		{
+			name: "literal", line: 17, column: 19,
+			expected: symtab.Hover{Expression: "10", Type: "untyped int", Value: "10"},
+		},
+		{
+			name: "type", line: 18, column: 22,
+			expected: symtab.Hover{Expression: "greeter.Formal", Type: fixturePkg + ".Formal", IsType: true, Doc: "Formal greets with a formal salutation."},
+			kind:     symtab.SymbolKindType,
+			methods:  []string{"Greet(name string) string"},
+		},
+		{
+			name: "package name", line: 14, column: 9,
+			expected: symtab.Hover{Expression: "fmt"},
+			kind:     symtab.SymbolKindPackage,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hover, err := finder.GetHover(welcome, tt.line, tt.column)
+			require.NoError(t, err)
+			if tt.kind == "" {
+				assert.Nil(t, hover.Object)
+			} else {
+				require.NotNil(t, hover.Object)
+				assert.Equal(t, tt.kind, hover.Object.Kind)
+			}
+			for _, m := range tt.methods {
+				assert.Contains(t, hover.Methods, m)
+			}
+			hover.Object, hover.Methods = nil, nil
+			assert.Equal(t, tt.expected, *hover)
+		})
+	}
+
+	t.Run("not an expression", func(t *testing.T) {
+		_, err := finder.GetHover(welcome, 14, 1)
+		assert.ErrorContains(t, err, "no expression")
+	})
+}
+
+func TestGetDefinitionExternalTest(t *testing.T) {
+	idx, root := indexFixture(t, map[string]string{
+		"greeter/export_test.go": "package greeter\n\n// Exported exposes New to the external tests.\nvar Exported = New\n",
+		"greeter/greeter_test.go": `package greeter_test
+
+import "example.com/testdata/greeter"
+
+var g = greeter.Exported("Hi, ")
+`,
+	})
+	finder := New(idx)
+	exportTest := filepath.Join(root, "greeter", "export_test.go")
+	external := filepath.Join(root, "greeter", "greeter_test.go")
+
+	def, err := finder.GetDefinition(external, 5, 17)
+	require.NoError(t, err)
+	assert.Equal(t, symtab.SymbolKindVar, def.Kind)
+	assert.Equal(t, fixturePkg, def.Package)
+	assert.True(t, def.IsTest)
+	require.NotNil(t, def.Location)
+	assert.Equal(t, exportTest, def.Location.File)
+	assert.Equal(t, 4, def.Location.Line)
+
+	hover, err := finder.GetHover(external, 5, 17)
+	require.NoError(t, err)
+	assert.Equal(t, "func(prefix string) *"+fixturePkg+".English", hover.Type)
+	assert.Equal(t, "Exported exposes New to the external tests.", hover.Doc)
+}
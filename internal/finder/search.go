@@ -0,0 +1,262 @@
+package finder
+
+import (
+	"cmp"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/tender-barbarian/go-llm-lens/internal/indexer"
+	"github.com/tender-barbarian/go-llm-lens/internal/symtab"
+)
+
+// BM25 parameters: how quickly repeated terms stop adding to the score, and
+// how much long documents are penalized.
+const (
+	bm25K1 = 1.2
+	bm25B  = 0.75
+)
+
+// nameWeight is how many times the terms of a symbol's name count, relative
+// to those of its doc comment and signature.
+const nameWeight = 3
+
+// maxSnippetLen caps the length in bytes of a search result's snippet.
+const maxSnippetLen = 200
+
+// stopWords are words too common in questions and prose to carry meaning.
+var stopWords = map[string]bool{
+	"a": true, "an": true, "and": true, "are": true, "as": true, "at": true, "be": true,
+	"by": true, "do": true, "does": true, "for": true, "from": true, "how": true, "i": true,
+	"in": true, "is": true, "it": true, "its": true, "of": true, "on": true, "or": true,
+	"our": true, "that": true, "the": true, "this": true, "to": true, "we": true, "what": true,
+	"when": true, "where": true, "which": true, "who": true, "why": true, "with": true,
+}
+
+// searchDoc is one symbol as the search index sees it: the terms of its name,
+// doc comment, field comments, and signature.
+type searchDoc struct {
+	ref   symtab.SymbolRef
+	doc   string
+	terms map[string]int // term frequency, with name terms counted nameWeight times
+	len   int
+}
+
+// searchIndex holds the documents of one snapshot and their term statistics.
+type searchIndex struct {
+	docs   []searchDoc
+	df     map[string]int // number of documents containing each term
+	avgLen float64
+}
+
+// searchCache keeps the search index of the most recent snapshot searched.
+type searchCache struct {
+	mu    sync.Mutex
+	snap  *indexer.Snapshot
+	index *searchIndex
+}
+
+// Search ranks the indexed functions, methods, types, variables, and constants
+// by how well they match the words of query, using BM25 over their names split
+// at camelCase boundaries, doc comments, field names and comments, and
+// signatures. Name matches weigh more than the rest. It returns every symbol
+// that matches at all, best first, optionally only of one kind. Symbols
+// declared in _test.go files are only returned when includeTests is set.
+func (f *Finder) Search(query string, kind symtab.SymbolKind, includeTests bool) ([]symtab.SearchResult, error) {
+	var terms []string
+	for _, t := range tokenize(query) {
+		if !slices.Contains(terms, t) {
+			terms = append(terms, t)
+		}
+	}
+	if len(terms) == 0 {
+		return nil, fmt.Errorf("query %q has no searchable words", query)
+	}
+	index := f.searchIndex()
+
+	var results []symtab.SearchResult
+	for i := range index.docs {
+		d := &index.docs[i]
+		if d.ref.IsTest && !includeTests || kind != "" && d.ref.Kind != kind {
+			continue
+		}
+		score := index.score(d, terms)
+		if score == 0 {
+			continue
+		}
+		results = append(results, symtab.SearchResult{
+			SymbolRef: d.ref,
+			Score:     math.Round(score*1000) / 1000,
+			Snippet:   snippet(d.doc, terms),
+		})
+	}
+	slices.SortFunc(results, func(a, b symtab.SearchResult) int {
+		return cmp.Or(cmp.Compare(b.Score, a.Score), strings.Compare(a.Package, b.Package),
+			strings.Compare(a.Receiver, b.Receiver), strings.Compare(a.Name, b.Name), strings.Compare(string(a.Kind), string(b.Kind)))
+	})
+	return results, nil
+}
+
+// searchIndex returns the search index of the current snapshot, building it
+// on the first search after each rebuild.
+func (f *Finder) searchIndex() *searchIndex {
+	snap := f.idx.Snapshot()
+	f.search.mu.Lock()
+	defer f.search.mu.Unlock()
+	if f.search.snap != snap {
+		f.search.snap, f.search.index = snap, newSearchIndex(snap)
+	}
+	return f.search.index
+}
+
+// newSearchIndex collects the documents of every symbol in snap.
+func newSearchIndex(snap *indexer.Snapshot) *searchIndex {
+	index := &searchIndex{df: make(map[string]int)}
+	add := func(ref symtab.SymbolRef, doc string, text ...string) {
+		d := searchDoc{ref: ref, doc: doc, terms: make(map[string]int)}
+		for _, t := range tokenize(ref.Name) {
+			d.terms[t] += nameWeight
+			d.len += nameWeight
+		}
+		for _, s := range append(text, doc) {
+			for _, t := range tokenize(s) {
+				d.terms[t]++
+				d.len++
+			}
+		}
+		for t := range d.terms {
+			index.df[t]++
+		}
+		index.docs = append(index.docs, d)
+	}
+	for _, pkg := range snap.PkgInfos() {
+		for i := range pkg.Funcs {
+			fn := &pkg.Funcs[i]
+			add(funcRef(pkg.ImportPath, fn, symtab.SymbolKindFunc), fn.Doc, fn.Signature)
+		}
+		for i := range pkg.Types {
+			t := &pkg.Types[i]
+			fields := make([]string, 0, 2*len(t.Fields))
+			for _, field := range t.Fields {
+				fields = append(fields, field.Name, field.Comment)
+			}
+			add(symtab.SymbolRef{Name: t.Name, Package: pkg.ImportPath, Kind: symtab.SymbolKindType, IsTest: t.IsTest, Location: t.Location}, t.Doc, fields...)
+			for j := range t.Methods {
+				if m := &t.Methods[j]; !m.IsPromoted {
+					add(funcRef(pkg.ImportPath, m, symtab.SymbolKindMethod), m.Doc, t.Name, m.Signature)
+				}
+			}
+		}
+		for _, v := range pkg.Vars {
+			kind := symtab.SymbolKindVar
+			if v.IsConst {
+				kind = symtab.SymbolKindConst
+			}
+			add(symtab.SymbolRef{Name: v.Name, Package: pkg.ImportPath, Kind: kind, IsTest: v.IsTest, Location: v.Location}, cmp.Or(v.Doc, v.GroupDoc), v.Type)
+		}
+	}
+	total := 0
+	for _, d := range index.docs {
+		total += d.len
+	}
+	if len(index.docs) > 0 {
+		index.avgLen = float64(total) / float64(len(index.docs))
+	}
+	return index
+}
+
+// score returns the BM25 score of d for the query terms.
+func (index *searchIndex) score(d *searchDoc, terms []string) float64 {
+	n := float64(len(index.docs))
+	score := 0.0
+	for _, t := range terms {
+		tf := float64(d.terms[t])
+		if tf == 0 {
+			continue
+		}
+		df := float64(index.df[t])
+		idf := math.Log(1 + (n-df+0.5)/(df+0.5))
+		score += idf * tf * (bm25K1 + 1) / (tf + bm25K1*(1-bm25B+bm25B*float64(d.len)/index.avgLen))
+	}
+	return score
+}
+
+// tokenize splits text into lower-case search terms: words split at
+// punctuation and at camelCase boundaries, so that "parseHTTPHeader" gives
+// parse, http, and header. Stop words are dropped, and plural endings trimmed
+// so that "retries" matches "retry".
+func tokenize(text string) []string {
+	var terms []string
+	for field := range strings.FieldsFuncSeq(text, func(r rune) bool { return !unicode.IsLetter(r) && !unicode.IsDigit(r) }) {
+		for _, word := range splitCamel(field) {
+			if word = strings.ToLower(word); !stopWords[word] {
+				terms = append(terms, stem(word))
+			}
+		}
+	}
+	return terms
+}
+
+// splitCamel splits an identifier at its camelCase boundaries. A run of
+// upper-case letters is one word, except for its last letter when a
+// lower-case letter follows it: "HTTPServer" gives HTTP and Server.
+func splitCamel(s string) []string {
+	runes := []rune(s)
+	var words []string
+	start := 0
+	for i := 1; i < len(runes); i++ {
+		prev, cur := runes[i-1], runes[i]
+		boundary := unicode.IsLower(prev) && unicode.IsUpper(cur) ||
+			unicode.IsUpper(prev) && unicode.IsUpper(cur) && i+1 < len(runes) && unicode.IsLower(runes[i+1]) ||
+			unicode.IsDigit(prev) != unicode.IsDigit(cur)
+		if boundary {
+			words = append(words, string(runes[start:i]))
+			start = i
+		}
+	}
+	return append(words, string(runes[start:]))
+}
+
+// stem trims the plural ending of an English word.
+func stem(word string) string {
+	switch {
+	case len(word) > 4 && strings.HasSuffix(word, "ies"):
+		return word[:len(word)-3] + "y"
+	case len(word) > 3 && strings.HasSuffix(word, "s") && !strings.HasSuffix(word, "ss"):
+		return word[:len(word)-1]
+	}
+	return word
+}
+
+// snippet returns the sentence of doc containing the most query terms, or
+// its first sentence if none does, shortened to maxSnippetLen bytes.
+func snippet(doc string, terms []string) string {
+	doc = strings.Join(strings.Fields(doc), " ")
+	best, bestHits := "", -1
+	for sentence := range strings.SplitAfterSeq(doc, ". ") {
+		hits := 0
+		for _, t := range tokenize(sentence) {
+			if slices.Contains(terms, t) {
+				hits++
+			}
+		}
+		if hits > bestHits {
+			best, bestHits = strings.TrimSpace(sentence), hits
+		}
+	}
+	if len(best) <= maxSnippetLen {
+		return best
+	}
+	cut := strings.LastIndexByte(best[:maxSnippetLen], ' ')
+	if cut <= 0 {
+		cut = maxSnippetLen
+		for !utf8.RuneStart(best[cut]) {
+			cut--
+		}
+	}
+	return best[:cut] + "…"
+}
@@ -0,0 +1,145 @@
+package finder
+
+import (
+	"cmp"
+	"slices"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tender-barbarian/go-llm-lens/internal/symtab"
+)
+
+func TestSearch(t *testing.T) {
+	idx, _ := indexFixture(t, map[string]string{
+		"client/client.go": `package client
+
+import "time"
+
+// withJitter spreads out the retry backoff of failed requests so that
+// clients do not retry in lockstep. The delay doubles after each attempt.
+func withJitter(attempt int, base time.Duration) time.Duration { return base << attempt }
+
+// Config configures a Client.
+type Config struct {
+	// MaxRetries is how many times a request is retried.
+	MaxRetries int
+}
+
+// HTTPClient sends requests.
+type HTTPClient struct{}
+
+// Do sends a request once.
+func (c *HTTPClient) Do() {}
+`,
+		"client/client_test.go": `package client
+
+// retryBackoffForTest is a retry backoff used only by tests.
+const retryBackoffForTest = 1
+`,
+	})
+	finder := New(idx)
+
+	names := func(results []symtab.SearchResult) []string {
+		out := make([]string, 0, len(results))
+		for _, r := range results {
+			out = append(out, r.Name)
+		}
+		return out
+	}
+
+	t.Run("doc comment", func(t *testing.T) {
+		results, err := finder.Search("where do we handle retry backoff", "", false)
+		require.NoError(t, err)
+		require.NotEmpty(t, results)
+		assert.Equal(t, "withJitter", results[0].Name)
+		assert.Equal(t, symtab.SymbolKindFunc, results[0].Kind)
+		assert.Equal(t, "example.com/testdata/client", results[0].Package)
+		assert.Greater(t, results[0].Score, 0.0)
+		assert.Equal(t, "withJitter spreads out the retry backoff of failed requests so that clients do not retry in lockstep.", results[0].Snippet)
+		assert.NotContains(t, names(results), "retryBackoffForTest")
+	})
+
+	t.Run("camelCase name", func(t *testing.T) {
+		results, err := finder.Search("jitter", "", false)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"withJitter"}, names(results))
+	})
+
+	t.Run("acronym", func(t *testing.T) {
+		results, err := finder.Search("http client", symtab.SymbolKindType, false)
+		require.NoError(t, err)
+		require.NotEmpty(t, results)
+		assert.Equal(t, "HTTPClient", results[0].Name)
+	})
+
+	t.Run("field comment", func(t *testing.T) {
+		results, err := finder.Search("retried", symtab.SymbolKindType, false)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"Config"}, names(results))
+	})
+
+	t.Run("plurals", func(t *testing.T) {
+		results, err := finder.Search("retries", symtab.SymbolKindType, false)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"Config"}, names(results))
+	})
+
+	t.Run("tests", func(t *testing.T) {
+		results, err := finder.Search("retry backoff", symtab.SymbolKindConst, true)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"retryBackoffForTest"}, names(results))
+	})
+
+	t.Run("best first", func(t *testing.T) {
+		results, err := finder.Search("greet", "", false)
+		require.NoError(t, err)
+		require.Greater(t, len(results), 1)
+		assert.True(t, slices.IsSortedFunc(results, func(a, b symtab.SearchResult) int { return cmp.Compare(b.Score, a.Score) }))
+	})
+
+	t.Run("only stop words", func(t *testing.T) {
+		_, err := finder.Search("where is the", "", false)
+		assert.Error(t, err)
+	})
+}
+
+func TestTokenize(t *testing.T) {
+	tests := []struct {
+		text     string
+		expected []string
+	}{
+		{text: "withJitter", expected: []string{"jitter"}},
+		{text: "parseHTTPHeader", expected: []string{"parse", "http", "header"}},
+		{text: "MaxRetries", expected: []string{"max", "retry"}},
+		{text: "utf8Decoder", expected: []string{"utf", "8", "decoder"}},
+		{text: "func (c *HTTPClient) Do() error", expected: []string{"func", "c", "http", "client", "error"}},
+		{text: "Close closes the files.", expected: []string{"close", "close", "file"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tokenize(tt.text))
+		})
+	}
+}
+
+func TestSnippet(t *testing.T) {
+	tests := []struct {
+		name     string
+		doc      string
+		expected string
+	}{
+		{name: "best sentence", doc: "Load reads a file. Retry backs off between attempts. Done.", expected: "Retry backs off between attempts."},
+		{name: "cut at a space", doc: "retry " + strings.Repeat("word ", 50), expected: "retry " + strings.TrimSpace(strings.Repeat("word ", 38)) + "…"},
+		{name: "cut within a rune", doc: "retry" + strings.Repeat("日", 100), expected: "retry" + strings.Repeat("日", 65) + "…"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual := snippet(tt.doc, []string{"retry"})
+			assert.True(t, utf8.ValidString(actual))
+			assert.Equal(t, tt.expected, actual)
+		})
+	}
+}
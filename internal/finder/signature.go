@@ -0,0 +1,400 @@
+package finder
+
+import (
+	"cmp"
+	"fmt"
+	"go/ast"
+	"go/constant"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"slices"
+	"strings"
+
+	"github.com/tender-barbarian/go-llm-lens/internal/indexer"
+	"github.com/tender-barbarian/go-llm-lens/internal/symtab"
+)
+
+// maxPatternTypes caps how many types one signature pattern may resolve to,
+// since an unqualified name such as Order can denote a type in every package.
+const maxPatternTypes = 64
+
+// SignatureQuery is the shape of the functions FindBySignature looks for.
+// Params and Results are Go type expressions such as context.Context,
+// *Order, []byte, or map[string]int, in which generic types are instantiated.
+// A package is named by its name or import path, and an unqualified name other
+// than a predeclared type matches types of that name in any indexed package.
+type SignatureQuery struct {
+	// Params must each match a different parameter, in any order. The
+	// receiver of a method counts as its first parameter.
+	Params []string
+	// Results must each match a different result, in any order.
+	Results []string
+	// Exact rejects functions with parameters or results beyond the matched ones.
+	Exact bool
+	// Assignable lets a pattern match a parameter that a value of its type
+	// can be passed to, such as an interface it implements, and a result that
+	// can be assigned to it.
+	Assignable bool
+	// LenientPointers lets T and *T match each other.
+	LenientPointers bool
+}
+
+// FindBySignature returns the indexed functions and methods whose signatures
+// have the parameter and result types of q. Generic functions match a pattern
+// at a type parameter when the pattern's type satisfies its constraint.
+// Results with the fewest unmatched parameters and results come first.
+func (f *Finder) FindBySignature(q SignatureQuery) ([]symtab.SymbolRef, error) {
+	if len(q.Params) == 0 && len(q.Results) == 0 {
+		return nil, fmt.Errorf("at least one parameter or result type is required")
+	}
+	snap := f.idx.Snapshot()
+	if snap.Partial() {
+		return nil, errPartialIndex
+	}
+	params, err := resolvePatterns(snap, q.Params)
+	if err != nil {
+		return nil, err
+	}
+	results, err := resolvePatterns(snap, q.Results)
+	if err != nil {
+		return nil, err
+	}
+
+	type match struct {
+		ref   symtab.SymbolRef
+		extra int // parameters and results no pattern matched
+	}
+	var matches []match
+	try := func(pkgPath string, fn *symtab.FuncInfo, kind symtab.SymbolKind, symbol string) {
+		obj, err := lookupObject(snap, pkgPath, symbol)
+		if err != nil {
+			return
+		}
+		tf, ok := obj.(*types.Func)
+		if !ok {
+			return
+		}
+		sig := tf.Signature()
+		var paramTypes []types.Type
+		if recv := sig.Recv(); recv != nil {
+			paramTypes = append(paramTypes, recv.Type())
+		}
+		for p := range sig.Params().Variables() {
+			paramTypes = append(paramTypes, p.Type())
+		}
+		var resultTypes []types.Type
+		for r := range sig.Results().Variables() {
+			resultTypes = append(resultTypes, r.Type())
+		}
+		extra := len(paramTypes) - len(params) + len(resultTypes) - len(results)
+		if extra < 0 || q.Exact && extra > 0 {
+			return
+		}
+		if !assign(params, paramTypes, func(pattern, t types.Type) bool { return q.matches(pattern, t, true) }) ||
+			!assign(results, resultTypes, func(pattern, t types.Type) bool { return q.matches(pattern, t, false) }) {
+			return
+		}
+		matches = append(matches, match{funcRef(pkgPath, fn, kind), extra})
+	}
+	for _, pkg := range snap.PkgInfos() {
+		for i := range pkg.Funcs {
+			try(pkg.ImportPath, &pkg.Funcs[i], symtab.SymbolKindFunc, pkg.Funcs[i].Name)
+		}
+		for _, t := range pkg.Types {
+			for i := range t.Methods {
+				if m := &t.Methods[i]; !m.IsPromoted {
+					try(pkg.ImportPath, m, symtab.SymbolKindMethod, t.Name+"."+m.Name)
+				}
+			}
+		}
+	}
+	slices.SortFunc(matches, func(a, b match) int {
+		return cmp.Or(a.extra-b.extra, strings.Compare(a.ref.Package, b.ref.Package),
+			strings.Compare(a.ref.Receiver, b.ref.Receiver), strings.Compare(a.ref.Name, b.ref.Name))
+	})
+	refs := make([]symtab.SymbolRef, 0, len(matches))
+	for _, m := range matches {
+		refs = append(refs, m.ref)
+	}
+	return refs, nil
+}
+
+// matches reports whether t, the type of a parameter if isParam is set or of
+// a result otherwise, matches the pattern type under q's options.
+func (q SignatureQuery) matches(pattern, t types.Type, isParam bool) bool {
+	if tp, ok := types.Unalias(t).(*types.TypeParam); ok {
+		return types.Satisfies(pattern, tp.Constraint().Underlying().(*types.Interface))
+	}
+	if types.Identical(pattern, t) {
+		return true
+	}
+	if q.LenientPointers && types.Identical(deref(pattern), deref(t)) {
+		return true
+	}
+	if !q.Assignable {
+		return false
+	}
+	if isParam {
+		return types.AssignableTo(pattern, t) || q.LenientPointers && types.AssignableTo(types.NewPointer(deref(pattern)), t)
+	}
+	return types.AssignableTo(t, pattern) || q.LenientPointers && types.AssignableTo(types.NewPointer(deref(t)), pattern)
+}
+
+// deref returns the element type of a pointer type, or t itself.
+func deref(t types.Type) types.Type {
+	if ptr, ok := types.Unalias(t).(*types.Pointer); ok {
+		return ptr.Elem()
+	}
+	return t
+}
+
+// assign reports whether each pattern can be matched to a different one of
+// types, where each pattern is a set of alternative types.
+func assign(patterns [][]types.Type, typs []types.Type, match func(pattern, t types.Type) bool) bool {
+	used := make([]bool, len(typs))
+	var next func(i int) bool
+	next = func(i int) bool {
+		if i == len(patterns) {
+			return true
+		}
+		for j, t := range typs {
+			if used[j] || !slices.ContainsFunc(patterns[i], func(p types.Type) bool { return match(p, t) }) {
+				continue
+			}
+			used[j] = true
+			if next(i + 1) {
+				return true
+			}
+			used[j] = false
+		}
+		return false
+	}
+	return next(0)
+}
+
+// resolvePatterns resolves each type pattern to the types it can denote.
+func resolvePatterns(snap *indexer.Snapshot, patterns []string) ([][]types.Type, error) {
+	resolved := make([][]types.Type, 0, len(patterns))
+	for _, p := range patterns {
+		expr, err := parser.ParseExpr(p)
+		if err != nil {
+			return nil, fmt.Errorf("invalid type %q: %w", p, err)
+		}
+		typs, err := resolveType(snap, expr)
+		if err != nil {
+			return nil, fmt.Errorf("resolving type %q: %w", p, err)
+		}
+		resolved = append(resolved, typs)
+	}
+	return resolved, nil
+}
+
+// resolveType returns the types a type expression can denote.
+func resolveType(snap *indexer.Snapshot, expr ast.Expr) ([]types.Type, error) {
+	// each resolves the operand types and combines every combination of them.
+	each := func(combine func(ts []types.Type) (types.Type, error), operands ...ast.Expr) ([]types.Type, error) {
+		combos := [][]types.Type{nil}
+		for _, op := range operands {
+			typs, err := resolveType(snap, op)
+			if err != nil {
+				return nil, err
+			}
+			var next [][]types.Type
+			for _, c := range combos {
+				for _, t := range typs {
+					next = append(next, append(slices.Clip(c), t))
+				}
+			}
+			if len(next) > maxPatternTypes {
+				return nil, fmt.Errorf("%s is ambiguous: it denotes more than %d types; qualify its names with their packages", types.ExprString(expr), maxPatternTypes)
+			}
+			combos = next
+		}
+		var result []types.Type
+		for _, c := range combos {
+			t, err := combine(c)
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, t)
+		}
+		return result, nil
+	}
+
+	switch e := expr.(type) {
+	case *ast.ParenExpr:
+		return resolveType(snap, e.X)
+	case *ast.Ident, *ast.SelectorExpr:
+		typs, err := resolveTypeName(snap, e)
+		if err != nil {
+			return nil, err
+		}
+		// The type relations matches relies on are not defined for generic
+		// types that are not instantiated.
+		for _, t := range typs {
+			g, ok := t.(interface {
+				TypeParams() *types.TypeParamList
+				TypeArgs() *types.TypeList
+			})
+			if ok && g.TypeParams().Len() > 0 && g.TypeArgs().Len() == 0 {
+				args := strings.Repeat(", int", g.TypeParams().Len())[2:]
+				return nil, fmt.Errorf("%s is generic; instantiate it, e.g. %[1]s[%s]", types.ExprString(e), args)
+			}
+		}
+		return typs, nil
+	case *ast.StarExpr:
+		return each(func(ts []types.Type) (types.Type, error) { return types.NewPointer(ts[0]), nil }, e.X)
+	case *ast.Ellipsis:
+		return each(func(ts []types.Type) (types.Type, error) { return types.NewSlice(ts[0]), nil }, e.Elt)
+	case *ast.ArrayType:
+		if e.Len == nil {
+			return each(func(ts []types.Type) (types.Type, error) { return types.NewSlice(ts[0]), nil }, e.Elt)
+		}
+		lit, ok := e.Len.(*ast.BasicLit)
+		n, exact := int64(0), false
+		if ok && lit.Kind == token.INT {
+			n, exact = constant.Int64Val(constant.MakeFromLiteral(lit.Value, lit.Kind, 0))
+		}
+		if !exact {
+			return nil, fmt.Errorf("array length %s must be an integer literal", types.ExprString(e.Len))
+		}
+		return each(func(ts []types.Type) (types.Type, error) { return types.NewArray(ts[0], n), nil }, e.Elt)
+	case *ast.MapType:
+		return each(func(ts []types.Type) (types.Type, error) { return types.NewMap(ts[0], ts[1]), nil }, e.Key, e.Value)
+	case *ast.ChanType:
+		dir := types.SendRecv
+		switch e.Dir {
+		case ast.SEND:
+			dir = types.SendOnly
+		case ast.RECV:
+			dir = types.RecvOnly
+		}
+		return each(func(ts []types.Type) (types.Type, error) { return types.NewChan(dir, ts[0]), nil }, e.Value)
+	case *ast.InterfaceType:
+		if len(e.Methods.List) > 0 {
+			return nil, fmt.Errorf("only the empty interface can be written out; name other interfaces")
+		}
+		return []types.Type{types.NewInterfaceType(nil, nil).Complete()}, nil
+	case *ast.FuncType:
+		var operands []ast.Expr
+		var params, results int
+		for _, list := range []*ast.FieldList{e.Params, e.Results} {
+			if list == nil {
+				continue
+			}
+			for _, field := range list.List {
+				for range max(len(field.Names), 1) {
+					operands = append(operands, field.Type)
+					if list == e.Params {
+						params++
+					} else {
+						results++
+					}
+				}
+			}
+		}
+		variadic := params > 0 && isEllipsis(operands[params-1])
+		return each(func(ts []types.Type) (types.Type, error) {
+			return types.NewSignatureType(nil, nil, nil, tuple(ts[:params]), tuple(ts[params:]), variadic), nil
+		}, operands...)
+	case *ast.IndexExpr, *ast.IndexListExpr:
+		x, indices := unpackIndex(e)
+		generics, err := resolveTypeName(snap, x)
+		if err != nil {
+			return nil, err
+		}
+		var result []types.Type
+		for _, g := range generics {
+			typs, err := each(func(ts []types.Type) (types.Type, error) {
+				named, ok := g.(*types.Named)
+				if !ok || named.TypeParams().Len() != len(ts) {
+					return nil, fmt.Errorf("%s is not a generic type with %d type parameters", types.ExprString(x), len(ts))
+				}
+				return types.Instantiate(nil, named, ts, true)
+			}, indices...)
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, typs...)
+		}
+		if len(result) > maxPatternTypes {
+			return nil, fmt.Errorf("%s is ambiguous: it denotes more than %d types; qualify its names with their packages", types.ExprString(expr), maxPatternTypes)
+		}
+		return result, nil
+	}
+	return nil, fmt.Errorf("%s is not a type", types.ExprString(expr))
+}
+
+// resolveTypeName returns the types a type name, optionally qualified by its
+// package, can denote, generic or not.
+func resolveTypeName(snap *indexer.Snapshot, expr ast.Expr) ([]types.Type, error) {
+	switch e := expr.(type) {
+	case *ast.ParenExpr:
+		return resolveTypeName(snap, e.X)
+	case *ast.Ident:
+		return lookupTypeName(snap, "", e.Name)
+	case *ast.SelectorExpr:
+		if pkg, ok := e.X.(*ast.Ident); ok {
+			return lookupTypeName(snap, pkg.Name, e.Sel.Name)
+		}
+	}
+	return nil, fmt.Errorf("%s is not a type", types.ExprString(expr))
+}
+
+// lookupTypeName returns the types named name: the predeclared type, or the
+// types of that name declared in the package pkg, given by its name or the
+// last element of its import path, or in any indexed package if pkg is "".
+func lookupTypeName(snap *indexer.Snapshot, pkg, name string) ([]types.Type, error) {
+	if pkg == "" {
+		if tn, ok := types.Universe.Lookup(name).(*types.TypeName); ok {
+			return []types.Type{tn.Type()}, nil
+		}
+	}
+	var typs []types.Type
+	for path, tp := range snap.TypePkgs() {
+		_, indexed := snap.PkgInfos()[path]
+		if pkg == "" && !indexed || pkg != "" && tp.Name() != pkg && path != pkg && !strings.HasSuffix(path, "/"+pkg) {
+			continue
+		}
+		if tn, ok := tp.Scope().Lookup(name).(*types.TypeName); ok {
+			typs = append(typs, tn.Type())
+		}
+	}
+	switch {
+	case len(typs) == 0 && pkg == "":
+		return nil, fmt.Errorf("no indexed package declares a type %s", name)
+	case len(typs) == 0:
+		return nil, fmt.Errorf("no package %s declaring a type %s was loaded", pkg, name)
+	case len(typs) > maxPatternTypes:
+		return nil, fmt.Errorf("%s is ambiguous: %d packages declare it; qualify it with its package", name, len(typs))
+	}
+	return typs, nil
+}
+
+// unpackIndex returns the operand and indices of an index expression, such as
+// the generic type and type arguments of an instantiation.
+func unpackIndex(expr ast.Expr) (x ast.Expr, indices []ast.Expr) {
+	switch e := expr.(type) {
+	case *ast.IndexExpr:
+		return e.X, []ast.Expr{e.Index}
+	case *ast.IndexListExpr:
+		return e.X, e.Indices
+	}
+	return nil, nil
+}
+
+// isEllipsis reports whether expr is a variadic parameter type, ...T.
+func isEllipsis(expr ast.Expr) bool {
+	_, ok := expr.(*ast.Ellipsis)
+	return ok
+}
+
+// tuple returns a tuple of unnamed variables of the given types.
+func tuple(typs []types.Type) *types.Tuple {
+	vars := make([]*types.Var, 0, len(typs))
+	for _, t := range typs {
+		vars = append(vars, types.NewParam(token.NoPos, nil, "", t))
+	}
+	return types.NewTuple(vars...)
+}
@@ -0,0 +1,151 @@
+package finder
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const ordersPkg = "example.com/testdata/orders"
+
+func TestFindBySignature(t *testing.T) {
+	idx, _ := indexFixture(t, map[string]string{"orders/orders.go": `package orders
+
+import (
+	"context"
+	"io"
+)
+
+type Order struct{ ID int }
+
+type Store interface {
+	Save(ctx context.Context, o *Order) error
+}
+
+func Save(ctx context.Context, o *Order) error { return nil }
+
+func SaveAll(ctx context.Context, orders []*Order, force bool) error { return nil }
+
+func Parse(r io.Reader) (*Order, error) { return nil, nil }
+
+func Index(orders []*Order) map[int]*Order { return nil }
+
+func (o Order) Total() int { return o.ID }
+
+func (o *Order) Submit(ctx context.Context) error { return nil }
+
+type Feed struct{}
+
+func (*Feed) Read(p []byte) (int, error) { return 0, nil }
+
+type ValidationError struct{}
+
+func (ValidationError) Error() string { return "" }
+
+func Validate(o Order) *ValidationError { return nil }
+
+func Max[T int | float64](a, b T) T { return a }
+
+type Stack[T any] struct{ items []T }
+
+func Push[T any](s *Stack[T], v T) {}
+
+func PushInt(s *Stack[int], v int) {}
+`})
+	finder := New(idx)
+
+	tests := []struct {
+		name        string
+		query       SignatureQuery
+		expected    []string // Receiver.Name, without the package
+		expectedErr string
+	}{
+		{
+			name:     "params in any order and a result",
+			query:    SignatureQuery{Params: []string{"*Order", "context.Context"}, Results: []string{"error"}},
+			expected: []string{"Save", "*Order.Submit", "Store.Save"},
+		},
+		{
+			name:     "exact",
+			query:    SignatureQuery{Params: []string{"context.Context", "*orders.Order"}, Results: []string{"error"}, Exact: true},
+			expected: []string{"Save", "*Order.Submit"},
+		},
+		{
+			name:     "composite types",
+			query:    SignatureQuery{Params: []string{"[]*Order"}, Results: []string{"map[int]*Order"}},
+			expected: []string{"Index"},
+		},
+		{
+			name:  "not assignable",
+			query: SignatureQuery{Params: []string{"*Feed"}, Results: []string{"*Order"}},
+		},
+		{
+			name:     "assignable parameter",
+			query:    SignatureQuery{Params: []string{"*Feed"}, Results: []string{"*Order"}, Assignable: true},
+			expected: []string{"Parse"},
+		},
+		{
+			name:  "result not identical",
+			query: SignatureQuery{Params: []string{"Order"}, Results: []string{"error"}, Exact: true},
+		},
+		{
+			name:     "assignable result",
+			query:    SignatureQuery{Params: []string{"Order"}, Results: []string{"error"}, Exact: true, Assignable: true},
+			expected: []string{"Validate"},
+		},
+		{
+			name:  "pointer not lenient",
+			query: SignatureQuery{Params: []string{"Order", "context.Context"}, Results: []string{"error"}, Exact: true},
+		},
+		{
+			name:     "lenient pointers",
+			query:    SignatureQuery{Params: []string{"Order", "context.Context"}, Results: []string{"error"}, Exact: true, LenientPointers: true},
+			expected: []string{"Save", "*Order.Submit"},
+		},
+		{
+			name:     "type parameter",
+			query:    SignatureQuery{Params: []string{"float64"}, Results: []string{"float64"}},
+			expected: []string{"Max"},
+		},
+		{
+			name:  "type parameter constraint",
+			query: SignatureQuery{Params: []string{"string"}, Results: []string{"string"}},
+		},
+		{
+			name:     "instantiated generic type",
+			query:    SignatureQuery{Params: []string{"*Stack[int]", "int"}},
+			expected: []string{"PushInt"},
+		},
+		{name: "generic type", query: SignatureQuery{Params: []string{"*Stack"}}, expectedErr: "Stack is generic; instantiate it, e.g. Stack[int]"},
+		{name: "assignable generic type", query: SignatureQuery{Params: []string{"*Stack", "int"}, Assignable: true}, expectedErr: "Stack is generic"},
+		{name: "wrong type argument count", query: SignatureQuery{Params: []string{"Stack[int, int]"}}, expectedErr: "Stack is not a generic type with 2 type parameters"},
+		{name: "no patterns", expectedErr: "at least one"},
+		{name: "unknown type", query: SignatureQuery{Params: []string{"Invoice"}}, expectedErr: "no indexed package declares a type Invoice"},
+		{name: "not a type", query: SignatureQuery{Results: []string{"f()"}}, expectedErr: "f() is not a type"},
+		{name: "syntax error", query: SignatureQuery{Results: []string{"map[int"}}, expectedErr: `invalid type "map[int"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			refs, err := finder.FindBySignature(tt.query)
+			if tt.expectedErr != "" {
+				assert.ErrorContains(t, err, tt.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			var got []string
+			for _, r := range refs {
+				if r.Package != ordersPkg {
+					continue // the fixture's greeter package
+				}
+				name := r.Name
+				if r.Receiver != "" {
+					name = strings.Replace(r.Receiver, ordersPkg+".", "", 1) + "." + name
+				}
+				got = append(got, name)
+			}
+			assert.Equal(t, tt.expected, got)
+		})
+	}
+}
@@ -0,0 +1,262 @@
+package indexer
+
+import (
+	"cmp"
+	"fmt"
+	"go/token"
+	"os"
+	"runtime"
+	"slices"
+	"strings"
+
+	"github.com/tender-barbarian/go-llm-lens/internal/symtab"
+	"golang.org/x/tools/go/packages"
+)
+
+// BuildConfig is a build configuration to load packages under: a target
+// platform and a set of build tags. Files whose build constraints do not match
+// the configuration are left out, just as go build would leave them out.
+type BuildConfig struct {
+	GOOS   string   // empty means $GOOS, or the host operating system
+	GOARCH string   // empty means $GOARCH, or the host architecture
+	Tags   []string // extra build tags, as passed to go build -tags
+}
+
+// ParseBuildConfig parses a configuration of the form "goos/goarch" or
+// "goos/goarch:tag1,tag2", as printed by BuildConfig.String. Either half of the
+// platform may be empty to use the default.
+func ParseBuildConfig(s string) (BuildConfig, error) {
+	platform, tags, _ := strings.Cut(s, ":")
+	goos, goarch, ok := strings.Cut(platform, "/")
+	if !ok {
+		return BuildConfig{}, fmt.Errorf("invalid build configuration %q: want goos/goarch or goos/goarch:tags", s)
+	}
+	return BuildConfig{GOOS: goos, GOARCH: goarch, Tags: SplitTags(tags)}, nil
+}
+
+// SplitTags splits a comma- or space-separated list of build tags, as accepted
+// by go build -tags.
+func SplitTags(s string) []string {
+	tags := strings.FieldsFunc(s, func(r rune) bool { return r == ',' || r == ' ' })
+	if len(tags) == 0 {
+		return nil
+	}
+	return tags
+}
+
+// String returns the configuration as "goos/goarch", followed by ":" and the
+// comma-separated tags when there are any. It is the name recorded in
+// symtab.FuncInfo.BuildConfigs and friends.
+func (c BuildConfig) String() string {
+	s := c.GOOS + "/" + c.GOARCH
+	if len(c.Tags) > 0 {
+		s += ":" + strings.Join(c.Tags, ",")
+	}
+	return s
+}
+
+// resolved returns c with the default platform filled in and its tags sorted
+// and de-duplicated, so that equal configurations have equal names.
+func (c BuildConfig) resolved() BuildConfig {
+	tags := slices.Clone(c.Tags)
+	slices.Sort(tags)
+	return BuildConfig{
+		GOOS:   cmp.Or(c.GOOS, os.Getenv("GOOS"), runtime.GOOS),
+		GOARCH: cmp.Or(c.GOARCH, os.Getenv("GOARCH"), runtime.GOARCH),
+		Tags:   slices.Compact(tags),
+	}
+}
+
+// WithBuildConfigs sets the build configurations to index. The first one is
+// primary: type information, and therefore find_implementations, comes from it.
+// Packages are loaded once more for every further configuration and their
+// symbols are merged in, each recording the configurations it exists under.
+// Without this option the host's default configuration is indexed.
+func WithBuildConfigs(configs ...BuildConfig) Option {
+	return func(idx *Indexer) {
+		idx.configs = nil
+		for _, c := range configs {
+			if c := c.resolved(); !slices.ContainsFunc(idx.configs, func(o BuildConfig) bool { return o.String() == c.String() }) {
+				idx.configs = append(idx.configs, c)
+			}
+		}
+	}
+}
+
+// BuildConfigs returns the build configurations the Indexer loads packages under,
+// primary first.
+func (idx *Indexer) BuildConfigs() []BuildConfig {
+	return slices.Clone(idx.configs)
+}
+
+// configNames returns the names of the configured build configurations.
+func (idx *Indexer) configNames() []string {
+	names := make([]string, len(idx.configs))
+	for i, c := range idx.configs {
+		names[i] = c.String()
+	}
+	return names
+}
+
+// packagesConfig returns a go/packages configuration that loads with mode from
+// dir under build configuration c, recording positions in fset.
+func (idx *Indexer) packagesConfig(dir string, c BuildConfig, mode packages.LoadMode, fset *token.FileSet) *packages.Config {
+	cfg := &packages.Config{
+		Mode:  mode,
+		Dir:   dir,
+		Fset:  fset,
+		Tests: true,
+		Env:   append(os.Environ(), "GOOS="+c.GOOS, "GOARCH="+c.GOARCH),
+	}
+	if len(c.Tags) > 0 {
+		cfg.BuildFlags = []string{"-tags=" + strings.Join(c.Tags, ",")}
+	}
+	return cfg
+}
+
+// mergeConfigs indexes the packages of lr matching patterns under every configuration
+// after the primary one and merges their symbols into pkgInfos, which holds the
+// same patterns as indexed under the primary configuration. Symbols are tagged
+// with the configurations they exist under; packages that only exist under a
+// secondary configuration are added, and their imports recorded in imports.
+// Secondary loads read dependencies from export data, since only their
+// symbols are kept. The caller must hold writeMu.
+//
+// With a single configuration, mergeConfigs does nothing and symbols are not tagged.
+func (idx *Indexer) mergeConfigs(lr *loadRoot, patterns []string, pkgInfos map[string]*symtab.PackageInfo, imports map[string][]string) error {
+	if len(idx.configs) < 2 {
+		return nil
+	}
+	primary := idx.configs[0].String()
+	for _, info := range pkgInfos {
+		tagPackage(info, primary)
+	}
+
+	for _, c := range idx.configs[1:] {
+		cfg := idx.packagesConfig(lr.dir, c, loadMode|packages.NeedTypes|packages.NeedTypesInfo, idx.fset)
+		pkgs, err := packages.Load(cfg, patterns...)
+		if err != nil {
+			return fmt.Errorf("loading packages for %s: %w", c, err)
+		}
+		for _, pkg := range rootPackages(lr, pkgs) {
+			info := idx.indexPackage(lr, pkg)
+			merged, ok := pkgInfos[pkg.PkgPath]
+			switch {
+			// A package whose files the configurations so far all exclude only
+			// holds the error saying so, which no longer applies.
+			case !ok, len(merged.Files) == 0 && len(info.Files) > 0:
+				tagPackage(info, c.String())
+				pkgInfos[pkg.PkgPath] = info
+				imports[pkg.PkgPath] = importPaths(pkg)
+			case len(info.Files) == 0:
+				// Excluded under c, but not under an earlier configuration.
+			default:
+				mergePackage(merged, info, c.String())
+				imports[pkg.PkgPath] = mergeSorted(imports[pkg.PkgPath], importPaths(pkg))
+			}
+		}
+	}
+	return nil
+}
+
+// tagPackage records config as the only build configuration of every symbol in info.
+func tagPackage(info *symtab.PackageInfo, config string) {
+	for i := range info.Funcs {
+		info.Funcs[i].BuildConfigs = []string{config}
+	}
+	for i := range info.Types {
+		t := &info.Types[i]
+		t.BuildConfigs = []string{config}
+		for j := range t.Methods {
+			t.Methods[j].BuildConfigs = []string{config}
+		}
+	}
+	for i := range info.Vars {
+		info.Vars[i].BuildConfigs = []string{config}
+	}
+}
+
+// mergePackage folds info, the same package indexed under config, into merged.
+// Symbols already in merged gain config in their BuildConfigs; where their
+// definitions differ between configurations, the one from merged is kept.
+// Symbols that only exist under config are appended. Files, errors, and
+// references not yet in merged are added too.
+func mergePackage(merged, info *symtab.PackageInfo, config string) {
+	merged.Funcs = mergeFuncs(merged.Funcs, info.Funcs, config)
+	for _, t := range info.Types {
+		i := slices.IndexFunc(merged.Types, func(m symtab.TypeInfo) bool { return m.Name == t.Name })
+		if i < 0 {
+			tagType(&t, config)
+			merged.Types = append(merged.Types, t)
+			continue
+		}
+		m := &merged.Types[i]
+		m.BuildConfigs = append(m.BuildConfigs, config)
+		m.Methods = mergeFuncs(m.Methods, t.Methods, config)
+	}
+	for _, v := range info.Vars {
+		i := slices.IndexFunc(merged.Vars, func(m symtab.VarInfo) bool { return m.Name == v.Name })
+		if i < 0 {
+			v.BuildConfigs = []string{config}
+			merged.Vars = append(merged.Vars, v)
+			continue
+		}
+		merged.Vars[i].BuildConfigs = append(merged.Vars[i].BuildConfigs, config)
+	}
+	slices.SortStableFunc(merged.Funcs, func(a, b symtab.FuncInfo) int { return strings.Compare(a.Name, b.Name) })
+	slices.SortStableFunc(merged.Types, func(a, b symtab.TypeInfo) int { return strings.Compare(a.Name, b.Name) })
+	slices.SortStableFunc(merged.Vars, func(a, b symtab.VarInfo) int { return strings.Compare(a.Name, b.Name) })
+	merged.Files = mergeSorted(merged.Files, info.Files)
+	merged.Imports = mergeSorted(merged.Imports, info.Imports)
+	merged.TestImports = slices.DeleteFunc(mergeSorted(merged.TestImports, info.TestImports), func(path string) bool {
+		_, found := slices.BinarySearch(merged.Imports, path)
+		return found
+	})
+	for _, e := range info.Errors {
+		if !slices.Contains(merged.Errors, e) {
+			merged.Errors = append(merged.Errors, e)
+		}
+	}
+	for key, refs := range info.Refs {
+		if merged.Refs == nil {
+			merged.Refs = make(map[string][]symtab.Reference)
+		}
+		for _, ref := range refs {
+			// Uses are the same use when they are at the same position.
+			if !slices.ContainsFunc(merged.Refs[key], func(r symtab.Reference) bool { return r.Location == ref.Location }) {
+				merged.Refs[key] = append(merged.Refs[key], ref)
+			}
+		}
+	}
+}
+
+// mergeFuncs merges funcs indexed under config into merged, matching functions
+// by name, and returns the result.
+func mergeFuncs(merged, funcs []symtab.FuncInfo, config string) []symtab.FuncInfo {
+	for _, fn := range funcs {
+		i := slices.IndexFunc(merged, func(m symtab.FuncInfo) bool { return m.Name == fn.Name })
+		if i < 0 {
+			fn.BuildConfigs = []string{config}
+			merged = append(merged, fn)
+			continue
+		}
+		merged[i].BuildConfigs = append(merged[i].BuildConfigs, config)
+	}
+	return merged
+}
+
+// tagType records config as the only build configuration of t and its methods.
+func tagType(t *symtab.TypeInfo, config string) {
+	t.BuildConfigs = []string{config}
+	t.Methods = slices.Clone(t.Methods)
+	for i := range t.Methods {
+		t.Methods[i].BuildConfigs = []string{config}
+	}
+}
+
+// mergeSorted returns the sorted union of a and b without duplicates.
+func mergeSorted(a, b []string) []string {
+	result := slices.Concat(a, b)
+	slices.Sort(result)
+	return slices.Compact(result)
+}
@@ -0,0 +1,188 @@
+package indexer
+
+import (
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tender-barbarian/go-llm-lens/internal/symtab"
+)
+
+func TestParseBuildConfig(t *testing.T) {
+	tests := []struct {
+		input       string
+		expected    BuildConfig
+		expectedErr string
+	}{
+		{input: "linux/amd64", expected: BuildConfig{GOOS: "linux", GOARCH: "amd64"}},
+		{input: "windows/arm64:integration,e2e", expected: BuildConfig{GOOS: "windows", GOARCH: "arm64", Tags: []string{"integration", "e2e"}}},
+		{input: "/:integration", expected: BuildConfig{Tags: []string{"integration"}}},
+		{input: "linux", expectedErr: `invalid build configuration "linux"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			actual, err := ParseBuildConfig(tt.input)
+			if tt.expectedErr != "" {
+				assert.ErrorContains(t, err, tt.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, actual)
+		})
+	}
+}
+
+func TestWithBuildConfigs(t *testing.T) {
+	t.Setenv("GOOS", "")
+	t.Setenv("GOARCH", "")
+	host := runtime.GOOS + "/" + runtime.GOARCH
+
+	tests := []struct {
+		name     string
+		configs  []BuildConfig
+		expected []string
+	}{
+		{name: "default", expected: []string{host}},
+		{name: "tags are sorted", configs: []BuildConfig{{Tags: []string{"b", "a", "b"}}}, expected: []string{host + ":a,b"}},
+		{
+			name:     "duplicates are dropped",
+			configs:  []BuildConfig{{GOOS: "linux", GOARCH: "arm64"}, {GOOS: "windows", GOARCH: "amd64"}, {GOOS: "linux", GOARCH: "arm64"}},
+			expected: []string{"linux/arm64", "windows/amd64"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var opts []Option
+			if tt.configs != nil {
+				opts = append(opts, WithBuildConfigs(tt.configs...))
+			}
+			idx, err := New(t.TempDir(), opts...)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, idx.configNames())
+		})
+	}
+}
+
+func TestIndexBuildConfigs(t *testing.T) {
+	root := newFixtureCopy(t)
+	dir := filepath.Join(root, "greeter")
+	writeFile(t, filepath.Join(dir, "integration.go"), "//go:build integration\n\npackage greeter\n\n// Integration only builds with the integration tag.\nfunc Integration() {}\n\n// Mode differs between configurations.\nconst Mode = \"integration\"\n")
+	writeFile(t, filepath.Join(dir, "unit.go"), "//go:build !integration\n\npackage greeter\n\n// Unit only builds without the integration tag.\nfunc Unit() {}\n\n// Mode differs between configurations.\nconst Mode = \"unit\"\n")
+
+	configs := func(t *testing.T, idx *Indexer) map[string][]string {
+		t.Helper()
+		pkg := idx.PkgInfos()[greeterPkg]
+		require.NotNil(t, pkg)
+		result := make(map[string][]string)
+		for _, fn := range pkg.Funcs {
+			result[fn.Name] = fn.BuildConfigs
+		}
+		for _, v := range pkg.Vars {
+			result[v.Name] = v.BuildConfigs
+		}
+		return result
+	}
+
+	t.Run("single configuration", func(t *testing.T) {
+		idx, err := New(root, WithBuildConfigs(BuildConfig{Tags: []string{"integration"}}))
+		require.NoError(t, err)
+		require.NoError(t, idx.Index())
+
+		actual := configs(t, idx)
+		assert.Contains(t, actual, "Integration")
+		assert.NotContains(t, actual, "Unit")
+		assert.Nil(t, actual["New"], "symbols are not tagged when only one configuration is indexed")
+	})
+
+	t.Run("merged configurations", func(t *testing.T) {
+		unit, integration := BuildConfig{}, BuildConfig{Tags: []string{"integration"}}
+		idx, err := New(root, WithBuildConfigs(unit, integration))
+		require.NoError(t, err)
+		require.NoError(t, idx.Index())
+		names := idx.configNames()
+
+		check := func(t *testing.T, idx *Indexer) {
+			t.Helper()
+			actual := configs(t, idx)
+			assert.Equal(t, names, actual["New"])
+			assert.Equal(t, names, actual["Mode"])
+			assert.Equal(t, names[:1], actual["Unit"])
+			assert.Equal(t, names[1:], actual["Integration"])
+
+			pkg := idx.PkgInfos()[greeterPkg]
+			assert.Contains(t, pkg.Files, filepath.Join(dir, "integration.go"))
+			assert.Contains(t, pkg.Files, filepath.Join(dir, "unit.go"))
+			for _, v := range pkg.Vars {
+				if v.Name == "Mode" {
+					// The primary configuration's definition wins.
+					assert.Equal(t, filepath.Join(dir, "unit.go"), v.Location.File)
+				}
+			}
+			for _, ti := range pkg.Types {
+				assert.Equal(t, names, ti.BuildConfigs, ti.Name)
+				for _, m := range ti.Methods {
+					assert.Equal(t, names, m.BuildConfigs, ti.Name+"."+m.Name)
+				}
+			}
+			// Only the primary configuration provides type information.
+			assert.Nil(t, idx.TypePkgs()[greeterPkg].Scope().Lookup("Integration"))
+		}
+		check(t, idx)
+
+		// Incremental updates merge the secondary configurations the same way.
+		appendFile(t, filepath.Join(dir, "integration.go"), "\n// Later is new.\nfunc Later() {}\n")
+		actual, err := idx.Update()
+		require.NoError(t, err)
+		assert.Equal(t, []string{greeterPkg, welcomePkg}, actual)
+		check(t, idx)
+		assert.Equal(t, names[1:], configs(t, idx)["Later"])
+	})
+}
+
+func TestMergePackage(t *testing.T) {
+	merged := &symtab.PackageInfo{
+		Files: []string{"a.go", "b.go"},
+		Funcs: []symtab.FuncInfo{{Name: "Both", BuildConfigs: []string{"p"}}, {Name: "Primary", BuildConfigs: []string{"p"}}},
+		Types: []symtab.TypeInfo{{Name: "T", BuildConfigs: []string{"p"}, Methods: []symtab.FuncInfo{{Name: "M", BuildConfigs: []string{"p"}}}}},
+	}
+	info := &symtab.PackageInfo{
+		Files:  []string{"a.go", "c.go"},
+		Funcs:  []symtab.FuncInfo{{Name: "Both"}, {Name: "Extra"}},
+		Types:  []symtab.TypeInfo{{Name: "T", Methods: []symtab.FuncInfo{{Name: "M"}, {Name: "N"}}}, {Name: "U", Methods: []symtab.FuncInfo{{Name: "M"}}}},
+		Vars:   []symtab.VarInfo{{Name: "V"}},
+		Errors: []symtab.LoadError{{Message: "broken"}},
+	}
+
+	mergePackage(merged, info, "s")
+	assert.Equal(t, &symtab.PackageInfo{
+		Files: []string{"a.go", "b.go", "c.go"},
+		Funcs: []symtab.FuncInfo{
+			{Name: "Both", BuildConfigs: []string{"p", "s"}},
+			{Name: "Extra", BuildConfigs: []string{"s"}},
+			{Name: "Primary", BuildConfigs: []string{"p"}},
+		},
+		Types: []symtab.TypeInfo{
+			{Name: "T", BuildConfigs: []string{"p", "s"}, Methods: []symtab.FuncInfo{{Name: "M", BuildConfigs: []string{"p", "s"}}, {Name: "N", BuildConfigs: []string{"s"}}}},
+			{Name: "U", BuildConfigs: []string{"s"}, Methods: []symtab.FuncInfo{{Name: "M", BuildConfigs: []string{"s"}}}},
+		},
+		Vars:   []symtab.VarInfo{{Name: "V", BuildConfigs: []string{"s"}}},
+		Errors: []symtab.LoadError{{Message: "broken"}},
+	}, merged)
+	assert.Nil(t, info.Types[1].Methods[0].BuildConfigs, "info must not be modified")
+}
+
+func TestCacheBuildConfigs(t *testing.T) {
+	cacheDir := t.TempDir()
+	idx, root := indexFixture(t, nil, WithCacheDir(cacheDir))
+	require.NoError(t, idx.SaveCache())
+
+	other, err := New(root, WithCacheDir(cacheDir), WithBuildConfigs(BuildConfig{Tags: []string{"integration"}}))
+	require.NoError(t, err)
+	hit, err := other.LoadCache()
+	require.NoError(t, err)
+	assert.False(t, hit, "a cache built under another configuration must not be used")
+}
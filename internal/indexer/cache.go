@@ -0,0 +1,356 @@
+package indexer
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go/token"
+	"go/types"
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/tender-barbarian/go-llm-lens/internal/symtab"
+	"golang.org/x/tools/go/packages"
+)
+
+// cacheFormat is bumped whenever the layout of cacheFile or symtab.PackageInfo
+// changes incompatibly, so that stale caches are ignored rather than misread.
+const cacheFormat = 9
+
+// cacheFile is the on-disk representation of an index.
+type cacheFile struct {
+	Format     int             `json:"format"`
+	GoVersion  string          `json:"go_version"`
+	Configs    []string        `json:"configs"` // names of the indexed build configurations, primary first
+	Roots      []string        `json:"roots"`
+	ModuleHash string          `json:"module_hash"` // hash of go.mod, go.sum, go.work, and go.work.sum
+	Packages   []cachedPackage `json:"packages"`
+}
+
+// cachedPackage is one indexed package together with the inputs it was built from.
+type cachedPackage struct {
+	Info    *symtab.PackageInfo `json:"info"`
+	Imports []string            `json:"imports"`
+	Files   map[string]string   `json:"files"` // hex SHA-256 of every tracked Go file in the package directory
+}
+
+// cachePath returns the cache file used for idx.roots. Each set of roots gets its
+// own file so that several servers can share one cache directory.
+func (idx *Indexer) cachePath() string {
+	sum := sha256.Sum256([]byte(strings.Join(idx.roots, "\x00")))
+	return filepath.Join(idx.cacheDir, hex.EncodeToString(sum[:8])+".json")
+}
+
+// ClearCache removes the cache file for this Indexer's roots, if any.
+func (idx *Indexer) ClearCache() error {
+	if idx.cacheDir == "" {
+		return nil
+	}
+	if err := os.Remove(idx.cachePath()); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("removing index cache: %w", err)
+	}
+	return nil
+}
+
+// SaveCache writes the current snapshot to the cache directory. It does nothing
+// when the cache is disabled or the current snapshot is partial.
+func (idx *Indexer) SaveCache() error {
+	if idx.cacheDir == "" {
+		return nil
+	}
+	// Writing the cache is not a rebuild, so it does not mark one in progress.
+	idx.writeMu.Lock()
+	defer idx.writeMu.Unlock()
+
+	snap := idx.Snapshot()
+	if snap.generation == 0 || snap.partial {
+		return nil
+	}
+
+	dirFiles := dirHashes(idx.stamps)
+	cf := cacheFile{
+		Format:     cacheFormat,
+		GoVersion:  runtime.Version(),
+		Configs:    idx.configNames(),
+		Roots:      idx.roots,
+		ModuleHash: moduleHash(idx.stamps),
+		Packages:   make([]cachedPackage, 0, len(snap.pkgInfos)),
+	}
+	for _, path := range slices.Sorted(maps.Keys(snap.pkgInfos)) {
+		info := snap.pkgInfos[path]
+		cf.Packages = append(cf.Packages, cachedPackage{
+			Info:    info,
+			Imports: idx.imports[path],
+			Files:   dirFiles[info.Dir],
+		})
+	}
+
+	data, err := json.Marshal(cf)
+	if err != nil {
+		return fmt.Errorf("encoding index cache: %w", err)
+	}
+	if err := os.MkdirAll(idx.cacheDir, 0o700); err != nil {
+		return fmt.Errorf("creating cache directory: %w", err)
+	}
+	// Write to a temporary file and rename it so that a concurrent reader
+	// never sees a half-written cache.
+	tmp, err := os.CreateTemp(idx.cacheDir, "index-*.tmp")
+	if err != nil {
+		return fmt.Errorf("writing index cache: %w", err)
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("writing index cache: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("writing index cache: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), idx.cachePath()); err != nil {
+		return fmt.Errorf("writing index cache: %w", err)
+	}
+	return nil
+}
+
+// LoadCache restores the index from the cache directory and publishes it as a
+// partial snapshot, so that symbol queries can be answered before type checking
+// finishes. Packages whose files changed since the cache was written, packages
+// that import them, and packages that are new are re-checked from source first;
+// everything else is taken from the cache as is.
+//
+// LoadCache reports false, without error, when the cache is disabled, missing,
+// unreadable, or was built with a different Go version or different go.mod,
+// go.sum, or go.work contents. Callers should follow a successful LoadCache with
+// LoadTypes to restore type information.
+func (idx *Indexer) LoadCache() (bool, error) {
+	if idx.cacheDir == "" {
+		return false, nil
+	}
+	idx.writeMu.Lock()
+	defer idx.writeMu.Unlock()
+	start := time.Now()
+
+	data, err := os.ReadFile(idx.cachePath())
+	if errors.Is(err, fs.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("reading index cache: %w", err)
+	}
+	var cf cacheFile
+	if err := json.Unmarshal(data, &cf); err != nil {
+		return false, nil // corrupt or from an incompatible version: rebuild from scratch
+	}
+
+	loadRoots, err := discoverRoots(idx.roots)
+	if err != nil {
+		return false, err
+	}
+	stamps, err := scanFiles(sourceDirs(loadRoots), nil)
+	if err != nil {
+		return false, fmt.Errorf("scanning source files: %w", err)
+	}
+	if cf.Format != cacheFormat || cf.GoVersion != runtime.Version() || !slices.Equal(cf.Configs, idx.configNames()) ||
+		!slices.Equal(cf.Roots, idx.roots) || cf.ModuleHash != moduleHash(stamps) {
+		return false, nil
+	}
+
+	idx.loadRoots = loadRoots
+	pkgInfos, imports, staleDirs := freshPackages(cf.Packages, dirHashes(stamps))
+	if len(staleDirs) > 0 {
+		ok, err := idx.checkStale(staleDirs, pkgInfos, imports)
+		if !ok || err != nil {
+			return false, err
+		}
+	}
+
+	idx.imports = imports
+	idx.stamps = stamps
+	idx.publish(&Snapshot{pkgInfos: pkgInfos, partial: true}, start)
+	return true, nil
+}
+
+// LoadTypes adds type information to the partial snapshot LoadCache restored,
+// publishing a complete snapshot with the same symbols, after which Update
+// re-checks only the packages that later changes affect. The indexed packages
+// are type-checked from source, since export data leaves out unexported
+// declarations, but their dependencies are read from the export data in the go
+// command's build cache and no symbols are extracted, so it costs a fraction of
+// a full Index. Only the primary build configuration is type-checked, as with
+// Index.
+//
+// LoadTypes does nothing when the current snapshot is not partial, for example
+// because an Index ran in the meantime.
+func (idx *Indexer) LoadTypes() error {
+	defer idx.beginWrite()()
+	prev := idx.Snapshot()
+	if !prev.partial {
+		return nil
+	}
+	start := time.Now()
+
+	fset := token.NewFileSet()
+	typePkgs := make(map[string]*types.Package)
+	testTypePkgs := make(map[string]*types.Package)
+	for _, lr := range idx.loadRoots {
+		cfg := idx.packagesConfig(lr.dir, idx.configs[0], loadMode|packages.NeedTypes, fset)
+		pkgs, err := packages.Load(cfg, lr.patterns...)
+		if err != nil {
+			return fmt.Errorf("loading packages: %w", err)
+		}
+		lr.types = make(map[string]*types.Package, len(pkgs))
+		lr.testTypes = make(map[string]*types.Package)
+		recordTypes(lr.types, lr.testTypes, pkgs)
+		recordDeps(lr.types, lr.testTypes)
+		addMissing(typePkgs, lr.types)
+		addMissing(testTypePkgs, lr.testTypes)
+	}
+
+	idx.fset = fset
+	idx.publish(&Snapshot{pkgInfos: prev.pkgInfos, typePkgs: typePkgs, testTypePkgs: testTypePkgs, fset: fset}, start)
+	return nil
+}
+
+// recordDeps adds every package that the packages in typePkgs and testTypePkgs
+// import, directly or not, to typePkgs. A load that reads dependencies from
+// export data only returns type information for the packages it matched, so
+// their dependencies are found through the imports of their types instead.
+func recordDeps(typePkgs, testTypePkgs map[string]*types.Package) {
+	queue := slices.Concat(slices.Collect(maps.Values(typePkgs)), slices.Collect(maps.Values(testTypePkgs)))
+	for len(queue) > 0 {
+		pkg := queue[len(queue)-1]
+		queue = queue[:len(queue)-1]
+		for _, imp := range pkg.Imports() {
+			if _, ok := typePkgs[imp.Path()]; !ok {
+				typePkgs[imp.Path()] = imp
+				queue = append(queue, imp)
+			}
+		}
+	}
+}
+
+// freshPackages splits cached packages into those whose inputs are unchanged and
+// the directories that need re-checking: packages whose files changed, packages
+// that transitively import a changed package, and directories with Go files
+// that the cache knows nothing about.
+func freshPackages(cached []cachedPackage, current map[string]map[string]string) (map[string]*symtab.PackageInfo, map[string][]string, []string) {
+	byPath := make(map[string]cachedPackage, len(cached))
+	allImports := make(map[string][]string, len(cached))
+	known := make(map[string]bool, len(cached))
+	var changed []string
+	for _, cp := range cached {
+		if cp.Info == nil {
+			continue
+		}
+		byPath[cp.Info.ImportPath] = cp
+		allImports[cp.Info.ImportPath] = cp.Imports
+		known[cp.Info.Dir] = true
+		if !maps.Equal(cp.Files, current[cp.Info.Dir]) {
+			changed = append(changed, cp.Info.ImportPath)
+		}
+	}
+
+	stale := make(map[string]bool)
+	for _, path := range reverseDeps(allImports, changed) {
+		stale[path] = true
+	}
+
+	pkgInfos := make(map[string]*symtab.PackageInfo, len(byPath))
+	imports := make(map[string][]string, len(byPath))
+	var staleDirs []string
+	for path, cp := range byPath {
+		if !stale[path] {
+			pkgInfos[path] = cp.Info
+			imports[path] = cp.Imports
+			continue
+		}
+		if len(current[cp.Info.Dir]) > 0 {
+			staleDirs = append(staleDirs, cp.Info.Dir)
+		}
+	}
+	for dir := range current {
+		if !known[dir] {
+			staleDirs = append(staleDirs, dir)
+		}
+	}
+	slices.Sort(staleDirs)
+	return pkgInfos, imports, slices.Compact(staleDirs)
+}
+
+// checkStale loads and type-checks the packages in dirs from source and adds
+// them to pkgInfos and imports. Dependencies are loaded from export data rather
+// than source, which keeps the cost proportional to the number of stale packages.
+// It reports false if a directory is not covered by any root, in which case the
+// cache cannot be used.
+func (idx *Indexer) checkStale(dirs []string, pkgInfos map[string]*symtab.PackageInfo, imports map[string][]string) (bool, error) {
+	groups, err := idx.groupDirs(dirs)
+	if errors.Is(err, errNeedFullIndex) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	idx.fset = token.NewFileSet()
+	stale := make(map[string]*symtab.PackageInfo)
+	for _, lr := range idx.loadRoots {
+		patterns := groups[lr]
+		if len(patterns) == 0 {
+			continue
+		}
+		cfg := idx.packagesConfig(lr.dir, idx.configs[0], loadMode|packages.NeedTypes|packages.NeedTypesInfo, idx.fset)
+		pkgs, err := packages.Load(cfg, patterns...)
+		if err != nil {
+			return false, fmt.Errorf("loading packages: %w", err)
+		}
+		infos := make(map[string]*symtab.PackageInfo)
+		for _, pkg := range rootPackages(lr, pkgs) {
+			infos[pkg.PkgPath] = idx.indexPackage(lr, pkg)
+			imports[pkg.PkgPath] = importPaths(pkg)
+		}
+		if err := idx.mergeConfigs(lr, patterns, infos, imports); err != nil {
+			return false, err
+		}
+		addMissing(stale, infos)
+	}
+	maps.Copy(pkgInfos, stale)
+	return true, nil
+}
+
+// dirHashes groups the hex content hashes of tracked Go files by directory.
+func dirHashes(stamps map[string]fileStamp) map[string]map[string]string {
+	dirs := make(map[string]map[string]string)
+	for path, st := range stamps {
+		if !strings.HasSuffix(path, ".go") {
+			continue
+		}
+		dir := filepath.Dir(path)
+		if dirs[dir] == nil {
+			dirs[dir] = make(map[string]string)
+		}
+		dirs[dir][path] = hex.EncodeToString(st.hash[:])
+	}
+	return dirs
+}
+
+// moduleHash combines the hashes of the module files under root, which affect
+// how every package resolves, into one digest.
+func moduleHash(stamps map[string]fileStamp) string {
+	h := sha256.New()
+	for _, path := range slices.Sorted(maps.Keys(stamps)) {
+		if !moduleFiles[filepath.Base(path)] {
+			continue
+		}
+		st := stamps[path]
+		fmt.Fprintf(h, "%s\x00%x\x00", path, st.hash)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
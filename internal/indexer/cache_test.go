@@ -0,0 +1,182 @@
+package indexer
+
+import (
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCache(t *testing.T) {
+	tests := []struct {
+		name          string
+		edit          func(t *testing.T, root string)
+		expectedHit   bool
+		expectedFuncs map[string]int // function count per package after LoadCache
+	}{
+		{
+			name:          "unchanged tree is restored from cache",
+			edit:          func(*testing.T, string) {},
+			expectedHit:   true,
+			expectedFuncs: map[string]int{greeterPkg: 6, welcomePkg: 1, standalonePkg: 0},
+		},
+		{
+			name: "changed package and its importers are re-checked",
+			edit: func(t *testing.T, root string) {
+				appendFile(t, filepath.Join(root, "greeter", "greeter.go"), "\n// Added is new.\nfunc Added() {}\n")
+			},
+			expectedHit:   true,
+			expectedFuncs: map[string]int{greeterPkg: 7, welcomePkg: 1, standalonePkg: 0},
+		},
+		{
+			name: "new package is picked up",
+			edit: func(t *testing.T, root string) {
+				writeFile(t, filepath.Join(root, "extra", "extra.go"), "package extra\n\n// Extra is new.\nfunc Extra() {}\n")
+			},
+			expectedHit:   true,
+			expectedFuncs: map[string]int{greeterPkg: 6, welcomePkg: 1, standalonePkg: 0, "example.com/testdata/extra": 1},
+		},
+		{
+			name: "removed package is dropped",
+			edit: func(t *testing.T, root string) {
+				require.NoError(t, os.RemoveAll(filepath.Join(root, "standalone")))
+			},
+			expectedHit:   true,
+			expectedFuncs: map[string]int{greeterPkg: 6, welcomePkg: 1},
+		},
+		{
+			name: "go.mod change invalidates the cache",
+			edit: func(t *testing.T, root string) {
+				appendFile(t, filepath.Join(root, "go.mod"), "\n")
+			},
+		},
+		{
+			name: "cleared cache is a miss",
+			edit: func(t *testing.T, root string) {
+				idx, err := New(root, WithCacheDir(filepath.Join(filepath.Dir(root), "cache")))
+				require.NoError(t, err)
+				require.NoError(t, idx.ClearCache())
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := newFixtureCopy(t)
+			cacheDir := filepath.Join(filepath.Dir(root), "cache")
+
+			idx, err := New(root, WithCacheDir(cacheDir))
+			require.NoError(t, err)
+			require.NoError(t, idx.Index())
+			require.NoError(t, idx.SaveCache())
+
+			tt.edit(t, root)
+
+			restored, err := New(root, WithCacheDir(cacheDir))
+			require.NoError(t, err)
+			hit, err := restored.LoadCache()
+			require.NoError(t, err)
+			require.Equal(t, tt.expectedHit, hit)
+			if !hit {
+				assert.Zero(t, restored.Snapshot().Generation())
+				return
+			}
+
+			snap := restored.Snapshot()
+			assert.True(t, snap.Partial())
+			assert.Empty(t, snap.TypePkgs())
+			actual := make(map[string]int, len(snap.PkgInfos()))
+			for path, pkg := range snap.PkgInfos() {
+				actual[path] = len(pkg.Funcs)
+			}
+			assert.Equal(t, tt.expectedFuncs, actual)
+
+			// A partial snapshot is upgraded by the next update.
+			_, err = restored.Update()
+			require.NoError(t, err)
+			assert.False(t, restored.Snapshot().Partial())
+			assert.NotEmpty(t, restored.Snapshot().TypePkgs())
+		})
+	}
+}
+
+func TestCacheRoundTrip(t *testing.T) {
+	cacheDir := t.TempDir()
+	idx, root := indexFixture(t, nil, WithCacheDir(cacheDir))
+	require.NoError(t, idx.SaveCache())
+
+	restored, err := New(root, WithCacheDir(cacheDir))
+	require.NoError(t, err)
+	hit, err := restored.LoadCache()
+	require.NoError(t, err)
+	require.True(t, hit)
+
+	assert.Equal(t, idx.PkgInfos(), restored.PkgInfos())
+}
+
+func TestCacheDisabled(t *testing.T) {
+	idx, _ := indexFixture(t, nil)
+	require.NoError(t, idx.SaveCache())
+	require.NoError(t, idx.ClearCache())
+
+	hit, err := idx.LoadCache()
+	require.NoError(t, err)
+	assert.False(t, hit)
+}
+
+func TestLoadTypes(t *testing.T) {
+	cacheDir := t.TempDir()
+	idx, root := indexFixture(t, nil, WithCacheDir(cacheDir))
+	require.NoError(t, idx.SaveCache())
+
+	restored, err := New(root, WithCacheDir(cacheDir))
+	require.NoError(t, err)
+	hit, err := restored.LoadCache()
+	require.NoError(t, err)
+	require.True(t, hit)
+	require.NoError(t, restored.LoadTypes())
+
+	snap := restored.Snapshot()
+	assert.False(t, snap.Partial())
+	assert.Equal(t, idx.PkgInfos(), snap.PkgInfos())
+	for _, path := range []string{greeterPkg, welcomePkg, standalonePkg, "sync"} {
+		assert.Contains(t, snap.TypePkgs(), path)
+	}
+	assert.Equal(t, slices.Sorted(maps.Keys(idx.Snapshot().TestTypePkgs())), slices.Sorted(maps.Keys(snap.TestTypePkgs())))
+	// Indexed packages are checked from source, so unexported declarations are there too.
+	assert.Equal(t, idx.TypePkgs()[greeterPkg].Scope().Names(), snap.TypePkgs()[greeterPkg].Scope().Names())
+
+	// Only a partial snapshot is upgraded.
+	require.NoError(t, restored.LoadTypes())
+	assert.Equal(t, snap.Generation(), restored.Snapshot().Generation())
+
+	// Updates after LoadTypes are incremental.
+	appendFile(t, filepath.Join(root, "standalone", "standalone.go"), "\n// Added is new.\nfunc Added() {}\n")
+	actual, err := restored.Update()
+	require.NoError(t, err)
+	assert.Equal(t, []string{standalonePkg}, actual)
+}
+
+func TestCacheIONotBuilding(t *testing.T) {
+	idx, _ := indexFixture(t, nil, WithCacheDir(t.TempDir()))
+
+	for name, cacheIO := range map[string]func() error{
+		"save": idx.SaveCache,
+		"load": func() error { _, err := idx.LoadCache(); return err },
+	} {
+		t.Run(name, func(t *testing.T) {
+			idx.writeMu.Lock()
+			done := make(chan error)
+			go func() { done <- cacheIO() }()
+			time.Sleep(50 * time.Millisecond) // let it wait for writeMu
+			assert.False(t, idx.Building(), "cache I/O is not a rebuild")
+			idx.writeMu.Unlock()
+			require.NoError(t, <-done)
+		})
+	}
+}
@@ -0,0 +1,240 @@
+package indexer
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"go/ast"
+	"go/build"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// maxCheckedFiles caps how many checked files CheckFile keeps for reuse.
+const maxCheckedFiles = 8
+
+// CheckedFile is a source file type-checked on demand together with the rest
+// of its package, for questions about positions inside function bodies that
+// the index does not record.
+type CheckedFile struct {
+	File *ast.File
+	Pkg  *types.Package
+	Info *types.Info
+
+	fset *token.FileSet // positions of File and the objects of Pkg
+	snap *Snapshot      // the snapshot Pkg's imports were resolved from
+
+	path string            // the absolute path of File
+	sum  [sha256.Size]byte // hash of the names and contents of the checked files
+}
+
+// checkCache keeps the files CheckFile checked most recently against one
+// snapshot, since questions about positions come in bursts about one file.
+type checkCache struct {
+	mu    sync.Mutex
+	snap  *Snapshot
+	files []*CheckedFile // most recently used first
+}
+
+// CheckFile parses and type-checks the package containing path as it is on
+// disk now, resolving its imports from the current snapshot. path is absolute,
+// or relative and matched against the ends of the indexed file paths. Only the
+// package's files that the first build configuration matching path selects are
+// checked; _test.go files are included when path is one. Type errors are
+// ignored, so whatever could be resolved is still available. The result is
+// reused while the snapshot and the checked files stay the same, and must not
+// be modified.
+func (idx *Indexer) CheckFile(path string) (*CheckedFile, error) {
+	snap := idx.Snapshot()
+	if snap.Partial() {
+		return nil, fmt.Errorf("type information is not available until indexing completes")
+	}
+	abs, pkgPath, err := findFile(snap, path)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, ok := idx.matchingContext(abs)
+	if !ok {
+		return nil, fmt.Errorf("file %s is excluded by every build configuration", abs)
+	}
+	withTests := isTestFile(abs)
+	var names []string
+	var srcs [][]byte
+	h := sha256.New()
+	for _, name := range snap.PkgInfos()[pkgPath].Files {
+		if isTestFile(name) && !withTests {
+			continue
+		}
+		if match, err := ctx.MatchFile(filepath.Dir(name), filepath.Base(name)); err != nil || !match {
+			continue
+		}
+		src, err := os.ReadFile(name) // #nosec G304 -- name is an indexed source file
+		if err != nil {
+			if name == abs {
+				return nil, fmt.Errorf("reading %s: %w", name, err)
+			}
+			continue
+		}
+		fmt.Fprintf(h, "%s\x00%d\x00", name, len(src))
+		h.Write(src)
+		names = append(names, name)
+		srcs = append(srcs, src)
+	}
+	var sum [sha256.Size]byte
+	h.Sum(sum[:0])
+	if cf := idx.checked.get(snap, abs, sum); cf != nil {
+		return cf, nil
+	}
+
+	fset := token.NewFileSet()
+	var files []*ast.File
+	var target *ast.File
+	for i, name := range names {
+		file, err := parser.ParseFile(fset, name, srcs[i], parser.ParseComments|parser.SkipObjectResolution)
+		if file == nil {
+			if name == abs {
+				return nil, fmt.Errorf("parsing %s: %w", name, err)
+			}
+			continue
+		}
+		files = append(files, file)
+		if name == abs {
+			target = file
+		}
+	}
+
+	conf := types.Config{
+		Importer: importerFunc(func(path string) (*types.Package, error) {
+			if path == "unsafe" {
+				return types.Unsafe, nil
+			}
+			// An external test package imports the package under test together
+			// with its _test.go files, such as an export_test.go.
+			if tp, ok := snap.TestTypePkgs()[path]; ok && path+"_test" == pkgPath {
+				return tp, nil
+			}
+			if tp, ok := snap.TypePkgs()[path]; ok {
+				return tp, nil
+			}
+			return nil, fmt.Errorf("package %s not loaded", path)
+		}),
+		Sizes: types.SizesFor("gc", ctx.GOARCH),
+		Error: func(error) {},
+	}
+	info := newTypesInfo()
+	tp, _ := conf.Check(pkgPath, fset, files, info)
+	cf := &CheckedFile{File: target, Pkg: tp, Info: info, fset: fset, snap: snap, path: abs, sum: sum}
+	idx.checked.put(cf)
+	return cf, nil
+}
+
+// get returns the file at path checked against snap from files whose hash is
+// sum, or nil if there is none.
+func (c *checkCache) get(snap *Snapshot, path string, sum [sha256.Size]byte) *CheckedFile {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.snap != snap {
+		return nil
+	}
+	i := slices.IndexFunc(c.files, func(cf *CheckedFile) bool { return cf.path == path && cf.sum == sum })
+	if i < 0 {
+		return nil
+	}
+	cf := c.files[i]
+	copy(c.files[1:i+1], c.files[:i])
+	c.files[0] = cf
+	return cf
+}
+
+// put adds cf to the cache, dropping the files of other snapshots, an older
+// check of the same file, and the least recently used file beyond
+// maxCheckedFiles.
+func (c *checkCache) put(cf *CheckedFile) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.snap != cf.snap {
+		c.snap, c.files = cf.snap, nil
+	}
+	c.files = slices.DeleteFunc(c.files, func(old *CheckedFile) bool { return old.path == cf.path })
+	c.files = slices.Insert(c.files, 0, cf)
+	if len(c.files) > maxCheckedFiles {
+		c.files = c.files[:maxCheckedFiles]
+	}
+}
+
+// findFile returns the indexed file path names, and the import path of its
+// package. A relative path matches the indexed file it is a suffix of, and
+// must match only one.
+func findFile(snap *Snapshot, path string) (file, pkgPath string, err error) {
+	var matches []string
+	for _, pkg := range snap.PkgInfos() {
+		for _, name := range pkg.Files {
+			if name == path || !filepath.IsAbs(path) && strings.HasSuffix(name, string(filepath.Separator)+filepath.Clean(path)) {
+				file, pkgPath = name, pkg.ImportPath
+				matches = append(matches, name)
+			}
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return "", "", fmt.Errorf("file %s is not part of any indexed package", path)
+	case 1:
+		return file, pkgPath, nil
+	}
+	slices.Sort(matches)
+	return "", "", fmt.Errorf("file %s is ambiguous, matching %s", path, strings.Join(matches, ", "))
+}
+
+// matchingContext returns the build context of the first indexed configuration
+// whose build constraints select path.
+func (idx *Indexer) matchingContext(path string) (build.Context, bool) {
+	for _, c := range idx.configs {
+		ctx := build.Default
+		if c.GOOS != "" {
+			ctx.GOOS = c.GOOS
+		}
+		if c.GOARCH != "" {
+			ctx.GOARCH = c.GOARCH
+		}
+		ctx.BuildTags = c.Tags
+		if match, err := ctx.MatchFile(filepath.Dir(path), filepath.Base(path)); err == nil && match {
+			return ctx, true
+		}
+	}
+	return build.Context{}, false
+}
+
+// Pos returns the position in the file of the given 1-based line and byte
+// column.
+func (cf *CheckedFile) Pos(line, column int) (token.Pos, error) {
+	tf := cf.fset.File(cf.File.FileStart)
+	if line < 1 || line > tf.LineCount() {
+		return token.NoPos, fmt.Errorf("line %d is outside %s, which has %d lines", line, tf.Name(), tf.LineCount())
+	}
+	start := tf.LineStart(line)
+	end := token.Pos(tf.Base() + tf.Size())
+	if line < tf.LineCount() {
+		end = tf.LineStart(line + 1)
+	}
+	pos := start + token.Pos(column-1)
+	if column < 1 || pos >= end {
+		return token.NoPos, fmt.Errorf("column %d is outside line %d", column, line)
+	}
+	return pos, nil
+}
+
+// Position returns the source position of pos, a position in the file or of
+// an object declared in obj's package. Objects of other packages were
+// declared in the snapshot's packages, and are resolved there.
+func (cf *CheckedFile) Position(obj types.Object, pos token.Pos) token.Position {
+	if obj != nil && obj.Pkg() != cf.Pkg {
+		return cf.snap.Position(pos)
+	}
+	return cf.fset.Position(pos)
+}
@@ -0,0 +1,35 @@
+package indexer
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckFileCache(t *testing.T) {
+	idx, root := indexFixture(t, nil)
+	greeter := filepath.Join(root, "greeter", "greeter.go")
+
+	first, err := idx.CheckFile(greeter)
+	require.NoError(t, err)
+	again, err := idx.CheckFile(filepath.Join("greeter", "greeter.go"))
+	require.NoError(t, err)
+	assert.Same(t, first, again, "an unchanged file is not checked again")
+
+	other, err := idx.CheckFile(filepath.Join(root, "welcome", "welcome.go"))
+	require.NoError(t, err)
+	assert.NotSame(t, first, other)
+
+	appendFile(t, greeter, "\n// Added is new.\nfunc Added() {}\n")
+	edited, err := idx.CheckFile(greeter)
+	require.NoError(t, err)
+	assert.NotSame(t, first, edited, "an edited file is checked again")
+	assert.NotNil(t, edited.Pkg.Scope().Lookup("Added"))
+
+	require.NoError(t, idx.Index())
+	reindexed, err := idx.CheckFile(greeter)
+	require.NoError(t, err)
+	assert.NotSame(t, edited, reindexed, "a new snapshot checks the file again")
+}
@@ -0,0 +1,77 @@
+package indexer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tender-barbarian/go-llm-lens/internal/symtab"
+)
+
+func TestIndexGenerics(t *testing.T) {
+	const genericPkg = "example.com/testdata/generic"
+
+	idx, _ := indexFixture(t, map[string]string{"generic/generic.go": `package generic
+
+// Number is satisfied by integer and floating-point types.
+type Number interface {
+	~int | ~int64 | ~float64
+}
+
+// Key can index a map and print itself.
+type Key interface {
+	comparable
+	String() string
+}
+
+// Max returns the larger of a and b.
+func Max[T Number](a, b T) T {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+// Pair holds two values.
+type Pair[K comparable, V any] struct {
+	Key   K
+	Value V
+}
+
+// Swap returns the pair with its values exchanged.
+func (p Pair[K, V]) Swap() Pair[V, K] { return Pair[V, K]{p.Value, p.Key} }
+
+// Ints pairs integers.
+type Ints = Pair[int, int]
+`})
+
+	pkg := idx.PkgInfos()[genericPkg]
+	require.NotNil(t, pkg)
+	types := make(map[string]symtab.TypeInfo)
+	for _, ti := range pkg.Types {
+		types[ti.Name] = ti
+	}
+
+	number := types["Number"]
+	assert.Equal(t, symtab.TypeKindConstraint, number.Kind)
+	assert.Equal(t, []string{"~int | ~int64 | ~float64"}, number.TypeSet)
+	assert.Empty(t, number.Embeds)
+
+	key := types["Key"]
+	assert.Equal(t, symtab.TypeKindConstraint, key.Kind)
+	assert.Equal(t, []string{"comparable"}, key.Embeds)
+	assert.Empty(t, key.TypeSet)
+
+	pair := types["Pair"]
+	assert.Equal(t, symtab.TypeKindStruct, pair.Kind)
+	assert.Equal(t, []symtab.TypeParam{{Name: "K", Constraint: "comparable"}, {Name: "V", Constraint: "any"}}, pair.TypeParams)
+	require.Len(t, pair.Methods, 1)
+	assert.Empty(t, pair.Methods[0].TypeParams)
+
+	assert.Equal(t, symtab.TypeKindAlias, types["Ints"].Kind)
+	assert.Empty(t, types["Ints"].TypeParams)
+
+	require.Len(t, pkg.Funcs, 1)
+	assert.Equal(t, "func Max[T example.com/testdata/generic.Number](a T, b T) T", pkg.Funcs[0].Signature)
+	assert.Equal(t, []symtab.TypeParam{{Name: "T", Constraint: "example.com/testdata/generic.Number"}}, pkg.Funcs[0].TypeParams)
+}
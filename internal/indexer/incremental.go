@@ -0,0 +1,450 @@
+package indexer
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/types"
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/tender-barbarian/go-llm-lens/internal/symtab"
+	"golang.org/x/tools/go/packages"
+)
+
+// moduleFiles are files whose changes can affect how every package resolves,
+// so any edit to one of them forces a full re-index.
+var moduleFiles = map[string]bool{
+	"go.mod":      true,
+	"go.sum":      true,
+	"go.work":     true,
+	"go.work.sum": true,
+}
+
+// errNeedFullIndex signals that an incremental update cannot be applied safely
+// and the whole tree has to be reloaded instead.
+var errNeedFullIndex = errors.New("incremental update not possible")
+
+// fileStamp records the state of a file at the time it was last indexed.
+// Size and modification time are compared first; the content hash decides
+// whether a file whose metadata changed was actually modified.
+type fileStamp struct {
+	size    int64
+	modTime time.Time
+	hash    [sha256.Size]byte
+}
+
+// Update re-indexes only the packages affected by source changes since the last
+// Index or Update call: packages with added, removed, or modified files, plus every
+// indexed package that transitively imports one of them. Unaffected packages keep
+// their existing entries and type information.
+//
+// Update falls back to a full Index when there is no previous index or only a
+// partial one restored from the cache, when go.mod, go.sum, or go.work changed,
+// when a package disappeared, or when the changed code imports a package that was
+// not loaded before.
+// It returns the sorted import paths of the re-indexed packages.
+func (idx *Indexer) Update() ([]string, error) {
+	defer idx.beginWrite()()
+	return idx.update(nil)
+}
+
+// Reindex forces a rebuild. With no paths it re-indexes everything, like Index.
+// Otherwise it re-indexes the named packages and every indexed package that
+// transitively imports them, together with anything Update would pick up.
+// It returns the sorted import paths of the re-indexed packages.
+func (idx *Indexer) Reindex(paths []string) ([]string, error) {
+	defer idx.beginWrite()()
+	if len(paths) == 0 {
+		return idx.fullIndex()
+	}
+	pkgInfos := idx.PkgInfos()
+	for _, path := range paths {
+		if _, ok := pkgInfos[path]; !ok {
+			return nil, fmt.Errorf("package %q not found in index", path)
+		}
+	}
+	return idx.update(paths)
+}
+
+// update implements Update and Reindex. The packages in force are re-indexed
+// even if none of their files changed. The caller must hold writeMu.
+func (idx *Indexer) update(force []string) ([]string, error) {
+	prev := idx.Snapshot()
+	if prev.generation == 0 || prev.partial {
+		return idx.fullIndex()
+	}
+
+	start := time.Now()
+	stamps, err := scanFiles(sourceDirs(idx.loadRoots), idx.stamps)
+	if err != nil {
+		return nil, fmt.Errorf("scanning source files: %w", err)
+	}
+
+	changed := changedFiles(idx.stamps, stamps)
+	if len(changed) == 0 && len(force) == 0 {
+		idx.stamps = stamps
+		return nil, nil
+	}
+
+	paths, err := idx.updatePackages(prev, changed, force, stamps, start)
+	if errors.Is(err, errNeedFullIndex) {
+		return idx.fullIndex()
+	}
+	if err != nil {
+		return nil, err
+	}
+	idx.stamps = stamps
+	return paths, nil
+}
+
+// fullIndex rebuilds the whole index and returns the sorted import paths of all
+// indexed packages. The caller must hold writeMu.
+func (idx *Indexer) fullIndex() ([]string, error) {
+	if err := idx.index(); err != nil {
+		return nil, err
+	}
+	return slices.Sorted(maps.Keys(idx.PkgInfos())), nil
+}
+
+// updatePackages reloads and re-checks the packages touched by changed files,
+// the packages in force, and their reverse dependencies, then publishes a patched
+// copy of prev as the next generation. The caller must hold writeMu.
+func (idx *Indexer) updatePackages(prev *Snapshot, changed, force []string, stamps map[string]fileStamp, start time.Time) ([]string, error) {
+	// A directory can hold both a package and its external test package.
+	dirPkgs := make(map[string][]string, len(prev.pkgInfos))
+	for path, info := range prev.pkgInfos {
+		dirPkgs[info.Dir] = append(dirPkgs[info.Dir], path)
+	}
+
+	seeds := slices.Clone(force)
+	var newDirs []string
+	for _, dir := range changedDirs(changed) {
+		if dir == "" {
+			return nil, errNeedFullIndex
+		}
+		paths, known := dirPkgs[dir]
+		switch {
+		case !hasGoFiles(dir, stamps):
+			if known {
+				return nil, errNeedFullIndex // package removed; importers need a full reload
+			}
+		case known:
+			seeds = append(seeds, paths...)
+		default:
+			newDirs = append(newDirs, dir)
+		}
+	}
+
+	for _, path := range reverseDeps(idx.imports, seeds) {
+		newDirs = append(newDirs, prev.pkgInfos[path].Dir)
+	}
+	slices.Sort(newDirs)
+	groups, err := idx.groupDirs(slices.Compact(newDirs))
+	if err != nil {
+		return nil, err
+	}
+	if len(groups) == 0 {
+		return nil, nil
+	}
+
+	// Patch copies so that readers holding prev are unaffected.
+	pkgInfos := maps.Clone(prev.pkgInfos)
+	typePkgs := maps.Clone(prev.typePkgs)
+	testTypePkgs := maps.Clone(prev.testTypePkgs)
+	imports := maps.Clone(idx.imports)
+	reloaded := make(map[string]*symtab.PackageInfo)
+	universes := make(map[*loadRoot][2]map[string]*types.Package, len(groups))
+
+	for _, lr := range idx.loadRoots {
+		patterns := groups[lr]
+		if len(patterns) == 0 {
+			continue
+		}
+		cfg := idx.packagesConfig(lr.dir, idx.configs[0], loadMode|packages.NeedTypesSizes, idx.fset)
+		pkgs, err := packages.Load(cfg, patterns...)
+		if err != nil {
+			return nil, fmt.Errorf("loading packages: %w", err)
+		}
+
+		checked, err := idx.checkPackages(lr.types, pkgs)
+		if err != nil {
+			return nil, err
+		}
+		lrTypes, lrTestTypes := maps.Clone(lr.types), maps.Clone(lr.testTypes)
+		recordTypes(lrTypes, lrTestTypes, checked)
+		recordTypes(typePkgs, testTypePkgs, checked)
+		universes[lr] = [2]map[string]*types.Package{lrTypes, lrTestTypes}
+
+		infos := make(map[string]*symtab.PackageInfo)
+		for _, pkg := range rootPackages(lr, checked) {
+			infos[pkg.PkgPath] = idx.indexPackage(lr, pkg)
+			imports[pkg.PkgPath] = importPaths(pkg)
+		}
+		if err := idx.mergeConfigs(lr, patterns, infos, imports); err != nil {
+			return nil, err
+		}
+		addMissing(reloaded, infos)
+	}
+	maps.Copy(pkgInfos, reloaded)
+	paths := slices.Sorted(maps.Keys(reloaded))
+
+	for lr, u := range universes {
+		lr.types, lr.testTypes = u[0], u[1]
+	}
+	idx.imports = imports
+	idx.publish(&Snapshot{pkgInfos: pkgInfos, typePkgs: typePkgs, testTypePkgs: testTypePkgs, fset: idx.fset}, start)
+	return paths, nil
+}
+
+// checkPackages type-checks pkgs in dependency order. Imports outside pkgs are
+// resolved from universe, the packages of the load root that pkgs belong to as
+// of the last load, so unaffected packages keep sharing the same
+// *types.Package values and cross-package identity checks stay valid.
+// Packages are keyed by ID rather than import path because test variants share
+// the import path of the package they extend.
+func (idx *Indexer) checkPackages(universe map[string]*types.Package, pkgs []*packages.Package) ([]*packages.Package, error) {
+	byID := make(map[string]*packages.Package, len(pkgs))
+	for _, pkg := range pkgs {
+		byID[pkg.ID] = pkg
+	}
+
+	var order []*packages.Package
+	state := make(map[string]int, len(pkgs)) // 0 = unvisited, 1 = in progress, 2 = done
+
+	var check func(pkg *packages.Package) error
+	check = func(pkg *packages.Package) error {
+		switch state[pkg.ID] {
+		case 1:
+			return fmt.Errorf("import cycle through %q: %w", pkg.ID, errNeedFullIndex)
+		case 2:
+			return nil
+		}
+		state[pkg.ID] = 1
+
+		var importErr error
+		importer := importerFunc(func(path string) (*types.Package, error) {
+			if path == "unsafe" {
+				return types.Unsafe, nil
+			}
+			imp, ok := pkg.Imports[path]
+			if !ok {
+				importErr = errNeedFullIndex
+				return nil, fmt.Errorf("no metadata for %s", path)
+			}
+			if dep, ok := byID[imp.ID]; ok {
+				if err := check(dep); err != nil {
+					importErr = err
+					return nil, err
+				}
+				return dep.Types, nil
+			}
+			// universe only holds plain packages; a test variant of a dependency
+			// has to be rebuilt along with the rest of the import graph.
+			if tp, ok := universe[imp.ID]; ok && imp.ID == imp.PkgPath {
+				return tp, nil
+			}
+			importErr = errNeedFullIndex
+			return nil, fmt.Errorf("package %s not loaded", imp.ID)
+		})
+
+		conf := types.Config{
+			Importer: importer,
+			Sizes:    pkg.TypesSizes,
+			// Keep going after errors, recording them the way packages.Load does:
+			// partial type information is still useful.
+			Error: func(err error) {
+				var terr types.Error
+				if !errors.As(err, &terr) {
+					return
+				}
+				pkg.TypeErrors = append(pkg.TypeErrors, terr)
+				pkg.Errors = append(pkg.Errors, packages.Error{
+					Pos:  terr.Fset.Position(terr.Pos).String(),
+					Msg:  terr.Msg,
+					Kind: packages.TypeError,
+				})
+			},
+		}
+		info := newTypesInfo()
+		tp, _ := conf.Check(pkg.PkgPath, idx.fset, pkg.Syntax, info)
+		if importErr != nil {
+			return importErr
+		}
+
+		pkg.Types = tp
+		pkg.TypesInfo = info
+		pkg.Fset = idx.fset
+		state[pkg.ID] = 2
+		order = append(order, pkg)
+		return nil
+	}
+
+	for _, pkg := range pkgs {
+		if err := check(pkg); err != nil {
+			return nil, err
+		}
+	}
+	return order, nil
+}
+
+// reverseDeps returns the sorted union of seeds and every package in imports
+// that transitively imports one of them.
+func reverseDeps(imports map[string][]string, seeds []string) []string {
+	importers := make(map[string][]string)
+	for path, deps := range imports {
+		for _, dep := range deps {
+			importers[dep] = append(importers[dep], path)
+		}
+	}
+
+	seen := make(map[string]bool, len(seeds))
+	queue := slices.Clone(seeds)
+	for len(queue) > 0 {
+		path := queue[0]
+		queue = queue[1:]
+		if seen[path] {
+			continue
+		}
+		seen[path] = true
+		queue = append(queue, importers[path]...)
+	}
+	return slices.Sorted(maps.Keys(seen))
+}
+
+// changedFiles returns the sorted paths that were added, removed, or modified between two scans.
+func changedFiles(prev, cur map[string]fileStamp) []string {
+	var changed []string
+	for path, st := range cur {
+		if old, ok := prev[path]; !ok || old.hash != st.hash {
+			changed = append(changed, path)
+		}
+	}
+	for path := range prev {
+		if _, ok := cur[path]; !ok {
+			changed = append(changed, path)
+		}
+	}
+	slices.Sort(changed)
+	return changed
+}
+
+// changedDirs returns the sorted set of directories containing the given Go files.
+// A changed module file is reported as an empty directory, which callers treat as
+// a request for a full re-index.
+func changedDirs(files []string) []string {
+	dirs := make(map[string]bool)
+	for _, f := range files {
+		if moduleFiles[filepath.Base(f)] {
+			dirs[""] = true
+			continue
+		}
+		dirs[filepath.Dir(f)] = true
+	}
+	return slices.Sorted(maps.Keys(dirs))
+}
+
+// hasGoFiles reports whether stamps contains any Go source file directly in dir.
+func hasGoFiles(dir string, stamps map[string]fileStamp) bool {
+	for path := range stamps {
+		if filepath.Dir(path) == dir && strings.HasSuffix(path, ".go") {
+			return true
+		}
+	}
+	return false
+}
+
+// scanFiles walks each of dirs and stamps every file that can influence the
+// index: Go sources in directories matched by "./...", plus the module files.
+// Hashes from prev are reused for files whose size and modification time are unchanged.
+func scanFiles(dirs []string, prev map[string]fileStamp) (map[string]fileStamp, error) {
+	stamps := make(map[string]fileStamp, len(prev))
+	for _, top := range dirs {
+		if err := scanDir(top, prev, stamps); err != nil {
+			return nil, err
+		}
+	}
+	return stamps, nil
+}
+
+// scanDir stamps the tracked files beneath top into stamps, as scanFiles does.
+func scanDir(top string, prev, stamps map[string]fileStamp) error {
+	return filepath.WalkDir(top, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if path != top && SkipDir(path) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !IsTrackedFile(d.Name()) {
+			return nil
+		}
+		fi, err := d.Info()
+		if err != nil {
+			return err
+		}
+		st := fileStamp{size: fi.Size(), modTime: fi.ModTime()}
+		if old, ok := prev[path]; ok && old.size == st.size && old.modTime.Equal(st.modTime) {
+			st.hash = old.hash
+		} else if st.hash, err = hashFile(path); err != nil {
+			return err
+		}
+		stamps[path] = st
+		return nil
+	})
+}
+
+// SkipDir reports whether the go command ignores dir when expanding "./...":
+// hidden and underscore-prefixed directories, testdata, vendor, and nested modules.
+func SkipDir(dir string) bool {
+	name := filepath.Base(dir)
+	if strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_") || name == "testdata" || name == "vendor" {
+		return true
+	}
+	_, err := os.Stat(filepath.Join(dir, "go.mod"))
+	return err == nil
+}
+
+// IsTrackedFile reports whether a file with the given base name is tracked for
+// changes: Go source and test files, and module files.
+func IsTrackedFile(name string) bool {
+	return moduleFiles[name] || strings.HasSuffix(name, ".go")
+}
+
+// hashFile returns the SHA-256 digest of the file at path.
+func hashFile(path string) ([sha256.Size]byte, error) {
+	data, err := os.ReadFile(path) // #nosec G304 -- path comes from walking the indexed root
+	if err != nil {
+		return [sha256.Size]byte{}, err
+	}
+	return sha256.Sum256(data), nil
+}
+
+// newTypesInfo returns a types.Info with every map populated, matching what
+// packages.Load produces for NeedTypesInfo.
+func newTypesInfo() *types.Info {
+	return &types.Info{
+		Types:        make(map[ast.Expr]types.TypeAndValue),
+		Instances:    make(map[*ast.Ident]types.Instance),
+		Defs:         make(map[*ast.Ident]types.Object),
+		Uses:         make(map[*ast.Ident]types.Object),
+		Implicits:    make(map[ast.Node]types.Object),
+		Selections:   make(map[*ast.SelectorExpr]*types.Selection),
+		Scopes:       make(map[ast.Node]*types.Scope),
+		FileVersions: make(map[*ast.File]string),
+	}
+}
+
+// importerFunc adapts a function to the types.Importer interface.
+type importerFunc func(path string) (*types.Package, error)
+
+func (f importerFunc) Import(path string) (*types.Package, error) { return f(path) }
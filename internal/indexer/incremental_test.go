@@ -0,0 +1,225 @@
+package indexer
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	greeterPkg    = "example.com/testdata/greeter"
+	welcomePkg    = "example.com/testdata/welcome"
+	standalonePkg = "example.com/testdata/standalone"
+)
+
+// newFixtureCopy copies the shared fixture module into a temporary directory,
+// adds a welcome package that imports greeter and a standalone package that
+// imports nothing, and returns the copy's root.
+func newFixtureCopy(t *testing.T) string {
+	t.Helper()
+	root := t.TempDir()
+	require.NoError(t, os.CopyFS(root, os.DirFS("../../tests/testdata")))
+	writeFile(t, filepath.Join(root, "welcome", "welcome.go"), `package welcome
+
+import "example.com/testdata/greeter"
+
+// Welcome greets a newcomer.
+func Welcome(name string) string { return greeter.New("Welcome, ").Greet(name) }
+`)
+	writeFile(t, filepath.Join(root, "standalone", "standalone.go"), "package standalone\n\n// Answer is unrelated to greeter.\nconst Answer = 42\n")
+	return root
+}
+
+// indexFixture indexes a copy of the fixture module, made by newFixtureCopy,
+// with the extra files added, keyed by their path relative to the root. It
+// returns the indexer and the copy's root.
+func indexFixture(t *testing.T, extra map[string]string, opts ...Option) (*Indexer, string) {
+	t.Helper()
+	root := newFixtureCopy(t)
+	for name, content := range extra {
+		writeFile(t, filepath.Join(root, filepath.FromSlash(name)), content)
+	}
+	idx, err := New(root, opts...)
+	require.NoError(t, err)
+	require.NoError(t, idx.Index())
+	return idx, root
+}
+
+// writeFile writes content to path and bumps its modification time so that
+// change detection does not depend on the file system's timestamp granularity.
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o750))
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	future := time.Now().Add(time.Hour)
+	require.NoError(t, os.Chtimes(path, future, future))
+}
+
+// appendFile appends content to the file at path.
+func appendFile(t *testing.T, path, content string) {
+	t.Helper()
+	data, err := os.ReadFile(path) // #nosec G304 -- test fixture path
+	require.NoError(t, err)
+	writeFile(t, path, string(data)+content)
+}
+
+func TestUpdate(t *testing.T) {
+	tests := []struct {
+		name     string
+		edit     func(t *testing.T, root string)
+		expected []string
+		check    func(t *testing.T, idx *Indexer)
+	}{
+		{
+			name:     "no changes",
+			edit:     func(*testing.T, string) {},
+			expected: nil,
+		},
+		{
+			name: "touch without content change",
+			edit: func(t *testing.T, root string) {
+				future := time.Now().Add(time.Hour)
+				require.NoError(t, os.Chtimes(filepath.Join(root, "greeter", "greeter.go"), future, future))
+			},
+			expected: nil,
+		},
+		{
+			name: "leaf package change reloads only that package",
+			edit: func(t *testing.T, root string) {
+				appendFile(t, filepath.Join(root, "welcome", "welcome.go"), "\n// Farewell says goodbye.\nfunc Farewell() {}\n")
+			},
+			expected: []string{welcomePkg},
+			check: func(t *testing.T, idx *Indexer) {
+				assert.Len(t, idx.PkgInfos()[welcomePkg].Funcs, 2)
+			},
+		},
+		{
+			name: "dependency change reloads importers",
+			edit: func(t *testing.T, root string) {
+				appendFile(t, filepath.Join(root, "greeter", "greeter.go"), "\n// Added is new.\nfunc Added() {}\n")
+			},
+			expected: []string{greeterPkg, welcomePkg},
+			check: func(t *testing.T, idx *Indexer) {
+				assert.Len(t, idx.PkgInfos()[greeterPkg].Funcs, 7)
+				assert.Len(t, idx.PkgInfos(), 3)
+				// The reloaded importer must see the reloaded greeter package, not the old one.
+				welcome := idx.TypePkgs()[welcomePkg]
+				for _, imp := range welcome.Imports() {
+					if imp.Path() == greeterPkg {
+						assert.Same(t, idx.TypePkgs()[greeterPkg], imp)
+					}
+				}
+			},
+		},
+		{
+			name: "new package is added",
+			edit: func(t *testing.T, root string) {
+				writeFile(t, filepath.Join(root, "extra", "extra.go"), "package extra\n\n// Extra is new.\nfunc Extra() {}\n")
+			},
+			expected: []string{"example.com/testdata/extra"},
+		},
+		{
+			name: "go.mod change falls back to full index",
+			edit: func(t *testing.T, root string) {
+				appendFile(t, filepath.Join(root, "go.mod"), "\n")
+			},
+			expected: []string{greeterPkg, standalonePkg, welcomePkg},
+		},
+		{
+			name: "new import of unloaded package falls back to full index",
+			edit: func(t *testing.T, root string) {
+				writeFile(t, filepath.Join(root, "welcome", "extra.go"), "package welcome\n\nimport \"net/url\"\n\nvar Parse = url.Parse\n")
+			},
+			expected: []string{greeterPkg, standalonePkg, welcomePkg},
+			check: func(t *testing.T, idx *Indexer) {
+				assert.Contains(t, idx.TypePkgs(), "net/url")
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			idx, root := indexFixture(t, nil)
+
+			tt.edit(t, root)
+			actual, err := idx.Update()
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, actual)
+			if tt.check != nil {
+				tt.check(t, idx)
+			}
+
+			// A second update with no further edits is a no-op.
+			again, err := idx.Update()
+			require.NoError(t, err)
+			assert.Empty(t, again)
+		})
+	}
+}
+
+func TestUpdateWithoutIndex(t *testing.T) {
+	idx, err := New(newFixtureCopy(t))
+	require.NoError(t, err)
+
+	actual, err := idx.Update()
+	require.NoError(t, err)
+	assert.Equal(t, []string{greeterPkg, standalonePkg, welcomePkg}, actual)
+}
+
+func TestChangedFiles(t *testing.T) {
+	a := fileStamp{hash: [32]byte{1}}
+	b := fileStamp{hash: [32]byte{2}}
+
+	tests := []struct {
+		name     string
+		prev     map[string]fileStamp
+		cur      map[string]fileStamp
+		expected []string
+	}{
+		{"unchanged", map[string]fileStamp{"x.go": a}, map[string]fileStamp{"x.go": a}, nil},
+		{"modified", map[string]fileStamp{"x.go": a}, map[string]fileStamp{"x.go": b}, []string{"x.go"}},
+		{"added", map[string]fileStamp{}, map[string]fileStamp{"x.go": a}, []string{"x.go"}},
+		{"removed", map[string]fileStamp{"x.go": a}, map[string]fileStamp{}, []string{"x.go"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, changedFiles(tt.prev, tt.cur))
+		})
+	}
+}
+
+func TestReindex(t *testing.T) {
+	tests := []struct {
+		name        string
+		paths       []string
+		expected    []string
+		expectedErr string
+	}{
+		{name: "full", expected: []string{greeterPkg, standalonePkg, welcomePkg}},
+		{name: "leaf package", paths: []string{welcomePkg}, expected: []string{welcomePkg}},
+		{name: "package with importers", paths: []string{greeterPkg}, expected: []string{greeterPkg, welcomePkg}},
+		{name: "unknown package", paths: []string{"no/such/pkg"}, expectedErr: `package "no/such/pkg" not found in index`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			idx, _ := indexFixture(t, nil)
+
+			actual, err := idx.Reindex(tt.paths)
+			if tt.expectedErr != "" {
+				assert.ErrorContains(t, err, tt.expectedErr)
+				assert.Equal(t, uint64(1), idx.Snapshot().Generation())
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, actual)
+			assert.Equal(t, uint64(2), idx.Snapshot().Generation())
+			assert.False(t, idx.Building())
+		})
+	}
+}
@@ -4,95 +4,314 @@ import (
 	"bytes"
 	"fmt"
 	"go/ast"
+	"go/constant"
 	"go/printer"
 	"go/token"
 	"go/types"
+	"maps"
 	"path/filepath"
+	"slices"
+	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+	"unicode"
+	"unicode/utf8"
 
 	"github.com/tender-barbarian/go-llm-lens/internal/symtab"
 	"golang.org/x/tools/go/packages"
 )
 
 // Indexer holds the fully type-checked in-memory index of a Go codebase.
+//
+// The index is published as a sequence of immutable Snapshots. Index and Update
+// build the next generation off to the side and swap it in when it is complete,
+// so readers keep seeing the previous index while a rebuild runs.
 type Indexer struct {
-	root     string
-	fset     *token.FileSet
-	pkgInfos map[string]*symtab.PackageInfo
-	typePkgs map[string]*types.Package // all loaded packages, including deps, for Implements checks
+	roots    []string      // absolute root directories, in the order given
+	cacheDir string        // empty disables the on-disk cache
+	configs  []BuildConfig // build configurations to index, primary first
+	current  atomic.Pointer[Snapshot]
+	building atomic.Int32 // number of Index, Update, Reindex, and LoadTypes calls running or waiting for writeMu
+	checked  checkCache   // files CheckFile checked recently
+
+	// writeMu serialises Index and Update. The fields below it are only
+	// touched while writeMu is held.
+	writeMu   sync.Mutex
+	fset      *token.FileSet
+	loadRoots []*loadRoot          // what each root covers, as of the last full index
+	imports   map[string][]string  // import paths imported by each indexed package, for reverse-dependency lookups
+	stamps    map[string]fileStamp // source and module files under the roots as of the last index, for change detection
+}
+
+// Snapshot returns the current generation of the index. Callers that need
+// several lookups to agree with each other should take one Snapshot and use it
+// throughout, rather than calling PkgInfos and TypePkgs separately.
+func (idx *Indexer) Snapshot() *Snapshot {
+	if s := idx.current.Load(); s != nil {
+		return s
+	}
+	return emptySnapshot
+}
+
+// Building reports whether a rebuild of the index is in progress.
+func (idx *Indexer) Building() bool {
+	return idx.building.Load() > 0
 }
 
-// TypePkgs returns the map of all type-checked packages keyed by import path.
-// It includes transitive dependencies, not just packages under the root.
+// TypePkgs returns the type-checked packages of the current snapshot.
+// See Snapshot.TypePkgs.
 func (idx *Indexer) TypePkgs() map[string]*types.Package {
-	return idx.typePkgs
+	return idx.Snapshot().TypePkgs()
 }
 
-// PkgInfos returns the map of all indexed packages keyed by import path.
+// PkgInfos returns the indexed packages of the current snapshot.
+// See Snapshot.PkgInfos.
 func (idx *Indexer) PkgInfos() map[string]*symtab.PackageInfo {
-	return idx.pkgInfos
+	return idx.Snapshot().PkgInfos()
+}
+
+// Root returns the absolute path of the first root directory.
+func (idx *Indexer) Root() string {
+	return idx.roots[0]
+}
+
+// Roots returns the absolute paths of all root directories.
+func (idx *Indexer) Roots() []string {
+	return slices.Clone(idx.roots)
+}
+
+// Option configures an Indexer.
+type Option func(*Indexer)
+
+// WithCacheDir enables the on-disk index cache in dir. See LoadCache and SaveCache.
+func WithCacheDir(dir string) Option {
+	return func(idx *Indexer) {
+		idx.cacheDir = dir
+	}
+}
+
+// WithRoots adds further root directories to index alongside the one passed to
+// New. Each root is loaded on its own, so type relationships between packages
+// of different roots, such as an interface in one implemented in another, are
+// only found when both belong to the same go.work workspace.
+func WithRoots(paths ...string) Option {
+	return func(idx *Indexer) {
+		idx.roots = append(idx.roots, paths...)
+	}
 }
 
 // New creates an Indexer rooted at rootPath. Call Index to load and scan packages.
-func New(rootPath string) (*Indexer, error) {
-	absRoot, err := filepath.Abs(rootPath)
-	if err != nil {
-		return nil, fmt.Errorf("resolving root path: %w", err)
+// A root containing a go.work file indexes every module of the workspace.
+func New(rootPath string, opts ...Option) (*Indexer, error) {
+	idx := &Indexer{roots: []string{rootPath}}
+	for _, opt := range opts {
+		opt(idx)
 	}
-	return &Indexer{root: absRoot}, nil
+	roots := make([]string, 0, len(idx.roots))
+	for _, root := range idx.roots {
+		absRoot, err := filepath.Abs(root)
+		if err != nil {
+			return nil, fmt.Errorf("resolving root path: %w", err)
+		}
+		if !slices.Contains(roots, absRoot) {
+			roots = append(roots, absRoot)
+		}
+	}
+	idx.roots = roots
+	if len(idx.configs) == 0 {
+		idx.configs = []BuildConfig{BuildConfig{}.resolved()}
+	}
+	return idx, nil
 }
 
-// Index loads all packages under the root and rebuilds the symbol index.
+// Index loads all packages under the roots and rebuilds the symbol index.
 // It can be called again to re-scan after source changes.
 func (idx *Indexer) Index() error {
-	fset := token.NewFileSet()
-	cfg := &packages.Config{
-		Mode: packages.NeedName |
-			packages.NeedFiles |
-			packages.NeedSyntax |
-			packages.NeedTypes |
-			packages.NeedTypesInfo |
-			packages.NeedDeps |
-			packages.NeedImports,
-		Dir:  idx.root,
-		Fset: fset,
-	}
-
-	pkgs, err := packages.Load(cfg, "./...")
+	defer idx.beginWrite()()
+	return idx.index()
+}
+
+// beginWrite marks a rebuild as in progress and acquires writeMu.
+// The returned function releases both.
+func (idx *Indexer) beginWrite() func() {
+	idx.building.Add(1)
+	idx.writeMu.Lock()
+	return func() {
+		idx.writeMu.Unlock()
+		idx.building.Add(-1)
+	}
+}
+
+// index implements Index. The caller must hold writeMu.
+func (idx *Indexer) index() error {
+	start := time.Now()
+	loadRoots, err := discoverRoots(idx.roots)
+	if err != nil {
+		return err
+	}
+
+	// Scan before loading so that edits made while packages.Load runs are
+	// picked up by the next Update rather than silently missed.
+	stamps, err := scanFiles(sourceDirs(loadRoots), nil)
 	if err != nil {
-		return fmt.Errorf("loading packages: %w", err)
+		return fmt.Errorf("scanning source files: %w", err)
 	}
 
-	idx.fset = fset
-	idx.pkgInfos = make(map[string]*symtab.PackageInfo, len(pkgs))
-	idx.typePkgs = make(map[string]*types.Package, len(pkgs))
+	idx.fset = token.NewFileSet()
+	pkgInfos := make(map[string]*symtab.PackageInfo)
+	typePkgs := make(map[string]*types.Package)
+	testTypePkgs := make(map[string]*types.Package)
+	imports := make(map[string][]string)
+
+	for _, lr := range loadRoots {
+		cfg := idx.packagesConfig(lr.dir, idx.configs[0], loadMode|packages.NeedTypes|packages.NeedTypesInfo|packages.NeedDeps, idx.fset)
+		pkgs, err := packages.Load(cfg, lr.patterns...)
+		if err != nil {
+			return fmt.Errorf("loading packages: %w", err)
+		}
+
+		// Visit the whole import graph, not just the matched packages, so that
+		// dependencies are available for Implements checks and incremental updates.
+		var all []*packages.Package
+		packages.Visit(pkgs, nil, func(pkg *packages.Package) {
+			all = append(all, pkg)
+		})
+		lr.types = make(map[string]*types.Package, len(all))
+		lr.testTypes = make(map[string]*types.Package)
+		recordTypes(lr.types, lr.testTypes, all)
+
+		// Packages with errors are kept: their errors are recorded alongside
+		// whatever symbols the type checker could still resolve.
+		infos := make(map[string]*symtab.PackageInfo)
+		for _, pkg := range rootPackages(lr, pkgs) {
+			infos[pkg.PkgPath] = idx.indexPackage(lr, pkg)
+			imports[pkg.PkgPath] = importPaths(pkg)
+		}
+		if err := idx.mergeConfigs(lr, lr.patterns, infos, imports); err != nil {
+			return err
+		}
 
+		// A package reachable from several roots, such as a shared local
+		// replacement, is indexed from the first root that loads it.
+		addMissing(pkgInfos, infos)
+		addMissing(typePkgs, lr.types)
+		addMissing(testTypePkgs, lr.testTypes)
+	}
+
+	idx.loadRoots = loadRoots
+	idx.imports = imports
+	idx.stamps = stamps
+	idx.publish(&Snapshot{pkgInfos: pkgInfos, typePkgs: typePkgs, testTypePkgs: testTypePkgs, fset: idx.fset}, start)
+	return nil
+}
+
+// loadMode is what every load needs to index packages from syntax. Loads add
+// type information, or the sizes to type-check with, on top.
+const loadMode = packages.NeedName |
+	packages.NeedFiles |
+	packages.NeedSyntax |
+	packages.NeedImports |
+	packages.NeedModule
+
+// addMissing copies the entries of src whose keys dst lacks into dst.
+func addMissing[V any](dst, src map[string]V) {
+	for k, v := range src {
+		if _, ok := dst[k]; !ok {
+			dst[k] = v
+		}
+	}
+}
+
+// rootPackages picks the packages to index from the result of a load with
+// Tests enabled. Only packages whose source files live in a directory lr
+// indexes are kept, which drops dependencies and generated test mains. When a
+// package has a test variant, the variant is kept instead of the package
+// itself, since it also contains the in-package _test.go files. A package
+// without Go files, such as one whose files build constraints all exclude, is
+// kept if it has errors, so that they are recorded.
+func rootPackages(lr *loadRoot, pkgs []*packages.Package) []*packages.Package {
+	chosen := make(map[string]*packages.Package, len(pkgs))
 	for _, pkg := range pkgs {
-		if pkg.Types == nil {
+		if len(pkg.GoFiles) == 0 && len(pkg.Errors) == 0 {
 			continue
 		}
-		// Store every loaded package for type-checking (needed for Implements checks).
-		idx.typePkgs[pkg.PkgPath] = pkg.Types
+		if dir := packageDir(pkg); dir == "" || !lr.contains(dir) {
+			continue
+		}
+		if cur, ok := chosen[pkg.PkgPath]; ok && len(cur.GoFiles) >= len(pkg.GoFiles) {
+			continue
+		}
+		chosen[pkg.PkgPath] = pkg
+	}
+	return slices.SortedFunc(maps.Values(chosen), func(a, b *packages.Package) int {
+		return strings.Compare(a.PkgPath, b.PkgPath)
+	})
+}
 
-		// Only index packages whose source files live under the root directory.
-		if len(pkg.GoFiles) > 0 && isUnderRoot(pkg.GoFiles[0], idx.root) {
-			idx.indexPackage(pkg)
+// packageDir returns the directory of pkg's source files. Its Go files decide
+// when it has any, which places a generated test main in the build cache rather
+// than beside the package it tests. Otherwise any other file of pkg does.
+func packageDir(pkg *packages.Package) string {
+	for _, files := range [][]string{pkg.GoFiles, pkg.CompiledGoFiles, pkg.OtherFiles, pkg.IgnoredFiles} {
+		if len(files) > 0 {
+			return filepath.Dir(files[0])
 		}
 	}
+	return pkg.Dir
+}
 
-	return nil
+// recordTypes adds the type-checked packages in pkgs to typePkgs, keyed by import
+// path. A test variant such as "foo [foo.test]" shares its import path with the
+// plain package but is a distinct *types.Package; the plain package wins because
+// it is the one importers were checked against. Variants are recorded only for
+// paths with no plain package in pkgs, which covers external test packages.
+// A package's own test variant goes to testTypePkgs instead, replacing any
+// previous entry for a package being reloaded.
+func recordTypes(typePkgs, testTypePkgs map[string]*types.Package, pkgs []*packages.Package) {
+	plain := make(map[string]bool, len(pkgs))
+	for _, pkg := range pkgs {
+		if pkg.ID == pkg.PkgPath {
+			plain[pkg.PkgPath] = true
+			delete(testTypePkgs, pkg.PkgPath)
+		}
+	}
+	for _, pkg := range pkgs {
+		switch {
+		case pkg.Types == nil || isTestMain(pkg):
+		case pkg.ID == pkg.PkgPath+" ["+pkg.PkgPath+".test]":
+			testTypePkgs[pkg.PkgPath] = pkg.Types
+		case pkg.ID != pkg.PkgPath && plain[pkg.PkgPath]:
+		default:
+			typePkgs[pkg.PkgPath] = pkg.Types
+		}
+	}
 }
 
-// indexPackage processes a single package and adds it to the index.
-func (idx *Indexer) indexPackage(pkg *packages.Package) {
+// isTestMain reports whether pkg is the main package generated by go test.
+func isTestMain(pkg *packages.Package) bool {
+	return pkg.Name == "main" && strings.HasSuffix(pkg.PkgPath, ".test")
+}
+
+// publish makes next visible to readers as the following generation, filling in
+// its generation number and timing. start is when the rebuild began.
+// The caller must hold writeMu.
+func (idx *Indexer) publish(next *Snapshot, start time.Time) {
+	next.generation = idx.Snapshot().generation + 1
+	next.builtAt = time.Now()
+	next.duration = next.builtAt.Sub(start)
+	idx.current.Store(next)
+}
+
+// indexPackage extracts the symbols of a single package of lr, and the uses it
+// makes of symbols declared in lr.
+func (idx *Indexer) indexPackage(lr *loadRoot, pkg *packages.Package) *symtab.PackageInfo {
 	docs := idx.buildDocMap(pkg.Syntax)
 	fieldDocs := idx.buildFieldDocMap(pkg.Syntax)
+	groupDocs := idx.buildGroupDocMap(pkg.Syntax)
 	bodies := idx.buildBodyMap(pkg.Syntax)
-
-	dir := ""
-	if len(pkg.GoFiles) > 0 {
-		dir = filepath.Dir(pkg.GoFiles[0])
-	}
+	decls := buildDeclMap(pkg.Syntax)
 
 	files := make([]string, len(pkg.GoFiles))
 	copy(files, pkg.GoFiles)
@@ -100,94 +319,157 @@ func (idx *Indexer) indexPackage(pkg *packages.Package) {
 	info := &symtab.PackageInfo{
 		ImportPath: pkg.PkgPath,
 		Name:       pkg.Name,
-		Dir:        dir,
+		Dir:        packageDir(pkg),
+		IsTest:     isExternalTest(files),
 		Files:      files,
+		Errors:     loadErrors(pkg),
+	}
+	info.Imports, info.TestImports = fileImports(pkg)
+	if pkg.Module != nil {
+		info.Module = pkg.Module.Path
 	}
+	if pkg.Types == nil {
+		return info
+	}
+	info.Refs = idx.references(lr, pkg)
 
 	scope := pkg.Types.Scope()
 	for _, name := range scope.Names() {
 		obj := scope.Lookup(name)
 		switch o := obj.(type) {
 		case *types.Func:
-			info.Funcs = append(info.Funcs, idx.funcInfo(o, pkg.PkgPath, docs, bodies))
+			fi := idx.funcInfo(o, pkg.PkgPath, docs, bodies, decls)
+			if fi.IsTest {
+				fi.TestKind = testKind(o)
+			}
+			info.Funcs = append(info.Funcs, fi)
 		case *types.TypeName:
-			info.Types = append(info.Types, idx.typeInfo(o, pkg, docs, fieldDocs, bodies))
+			info.Types = append(info.Types, idx.typeInfo(o, pkg, docs, fieldDocs, bodies, decls))
 		case *types.Var:
-			info.Vars = append(info.Vars, idx.varInfo(o, pkg.PkgPath, docs, false))
+			info.Vars = append(info.Vars, idx.varInfo(o, pkg.PkgPath, docs, groupDocs, decls))
 		case *types.Const:
-			info.Vars = append(info.Vars, idx.varInfo(o, pkg.PkgPath, docs, true))
+			vi := idx.varInfo(o, pkg.PkgPath, docs, groupDocs, decls)
+			vi.IsConst = true
+			vi.Value = ConstValue(o.Val())
+			info.Vars = append(info.Vars, vi)
 		}
 	}
 
-	idx.pkgInfos[pkg.PkgPath] = info
+	return info
 }
 
 // funcInfo extracts symtab.funcInfo from a *types.Func.
-func (idx *Indexer) funcInfo(fn *types.Func, pkgPath string, docs, bodies map[token.Pos]string) symtab.FuncInfo {
+func (idx *Indexer) funcInfo(fn *types.Func, pkgPath string, docs, bodies map[token.Pos]string, decls map[token.Pos]ast.Node) symtab.FuncInfo {
 	sig, ok := fn.Type().(*types.Signature)
 	if !ok {
 		return symtab.FuncInfo{}
 	}
 	pos := idx.fset.Position(fn.Pos())
 	return symtab.FuncInfo{
-		Name:      fn.Name(),
-		Package:   pkgPath,
-		Receiver:  idx.receiverString(sig),
-		Signature: idx.buildSignature(fn.Name(), sig.Recv(), sig),
-		Doc:       docs[fn.Pos()],
-		Body:      bodies[fn.Pos()],
-		Location:  symtab.Location{File: pos.Filename, Line: pos.Line},
+		Name:       fn.Name(),
+		Package:    pkgPath,
+		Receiver:   idx.receiverString(sig),
+		Signature:  idx.buildSignature(fn.Name(), sig.Recv(), sig),
+		TypeParams: typeParams(sig.TypeParams()),
+		Doc:        docs[fn.Pos()],
+		IsTest:     isTestFile(pos.Filename),
+		Body:       bodies[fn.Pos()],
+		Location:   idx.location(fn, decls),
 	}
 }
 
 // typeInfo extracts symtab.typeInfo from a *types.TypeName.
-func (idx *Indexer) typeInfo(tn *types.TypeName, pkg *packages.Package, docs, fieldDocs, bodies map[token.Pos]string) symtab.TypeInfo {
+func (idx *Indexer) typeInfo(tn *types.TypeName, pkg *packages.Package, docs, fieldDocs, bodies map[token.Pos]string, decls map[token.Pos]ast.Node) symtab.TypeInfo {
 	pos := idx.fset.Position(tn.Pos())
 	ti := symtab.TypeInfo{
 		Name:     tn.Name(),
 		Package:  pkg.PkgPath,
+		IsTest:   isTestFile(pos.Filename),
 		Doc:      docs[tn.Pos()],
-		Location: symtab.Location{File: pos.Filename, Line: pos.Line},
+		Location: idx.location(tn, decls),
 	}
 
 	named, ok := tn.Type().(*types.Named)
 	if !ok {
 		ti.Kind = symtab.TypeKindAlias
+		if alias, ok := tn.Type().(*types.Alias); ok {
+			ti.TypeParams = typeParams(alias.TypeParams())
+		}
 		return ti
 	}
+	ti.TypeParams = typeParams(named.TypeParams())
 
 	switch u := named.Underlying().(type) {
 	case *types.Struct:
 		ti.Kind = symtab.TypeKindStruct
 		ti.Fields, ti.Embeds = idx.structFields(u, fieldDocs)
-		ti.Methods = idx.namedMethods(named, pkg.PkgPath, docs, bodies)
+		ti.Methods = idx.namedMethods(named, pkg.PkgPath, docs, bodies, decls)
 	case *types.Interface:
 		ti.Kind = symtab.TypeKindInterface
-		ti.Methods = idx.interfaceMethods(u, pkg.PkgPath, docs, bodies)
-		ti.Embeds = idx.interfaceEmbeds(u)
+		if !u.IsMethodSet() {
+			ti.Kind = symtab.TypeKindConstraint
+		}
+		ti.Methods = idx.interfaceMethods(u, pkg.PkgPath, docs, bodies, decls)
+		ti.Embeds, ti.TypeSet = idx.interfaceEmbeds(u)
 	default:
 		if tn.IsAlias() {
 			ti.Kind = symtab.TypeKindAlias
 		} else {
 			ti.Kind = symtab.TypeKindOther
 		}
-		ti.Methods = idx.namedMethods(named, pkg.PkgPath, docs, bodies)
+		ti.Methods = idx.namedMethods(named, pkg.PkgPath, docs, bodies, decls)
 	}
 
 	return ti
 }
 
 // varInfo extracts VarInfo from a types.Object (variable or constant).
-func (idx *Indexer) varInfo(obj types.Object, pkgPath string, docs map[token.Pos]string, isConst bool) symtab.VarInfo {
+func (idx *Indexer) varInfo(obj types.Object, pkgPath string, docs, groupDocs map[token.Pos]string, decls map[token.Pos]ast.Node) symtab.VarInfo {
 	pos := idx.fset.Position(obj.Pos())
 	return symtab.VarInfo{
 		Name:     obj.Name(),
 		Package:  pkgPath,
 		Type:     types.TypeString(obj.Type(), nil),
-		IsConst:  isConst,
+		IsTest:   isTestFile(pos.Filename),
 		Doc:      docs[obj.Pos()],
-		Location: symtab.Location{File: pos.Filename, Line: pos.Line},
+		GroupDoc: groupDocs[obj.Pos()],
+		Location: idx.location(obj, decls),
+	}
+}
+
+// location returns where obj is declared. The ranges of its name and whole
+// declaration are only known for objects declared in the syntax decls was
+// built from.
+func (idx *Indexer) location(obj types.Object, decls map[token.Pos]ast.Node) symtab.Location {
+	pos := idx.fset.Position(obj.Pos())
+	loc := symtab.Location{File: pos.Filename, Line: pos.Line}
+	decl, ok := decls[obj.Pos()]
+	if !ok {
+		return loc
+	}
+	loc.Column = pos.Column
+	loc.Name = idx.span(obj.Pos(), obj.Pos()+token.Pos(len(obj.Name())))
+	loc.Decl = idx.span(decl.Pos(), decl.End())
+	return loc
+}
+
+// span returns the range of source from start to end.
+func (idx *Indexer) span(start, end token.Pos) *symtab.Range {
+	position := func(pos token.Pos) symtab.Position {
+		p := idx.fset.Position(pos)
+		return symtab.Position{Line: p.Line, Column: p.Column, Offset: p.Offset}
+	}
+	return &symtab.Range{Start: position(start), End: position(end)}
+}
+
+// ConstValue formats the value of a constant as Go source would write it.
+// Floating-point values are rounded to a readable decimal rather than written
+// as the exact fraction.
+func ConstValue(val constant.Value) string {
+	if val.Kind() == constant.Float {
+		return val.String()
 	}
+	return val.ExactString()
 }
 
 // structFields separates a struct's named fields from its embedded types.
@@ -211,7 +493,7 @@ func (idx *Indexer) structFields(s *types.Struct, fieldDocs map[token.Pos]string
 // namedMethods returns all methods on a named type, including promoted ones.
 // Promoted methods (accessed through an embedded field) are marked with IsPromoted=true.
 // types.MethodSet stores selections sorted by method name, so iteration order is deterministic.
-func (idx *Indexer) namedMethods(named *types.Named, pkgPath string, docs, bodies map[token.Pos]string) []symtab.FuncInfo {
+func (idx *Indexer) namedMethods(named *types.Named, pkgPath string, docs, bodies map[token.Pos]string, decls map[token.Pos]ast.Node) []symtab.FuncInfo {
 	mset := types.NewMethodSet(types.NewPointer(named))
 	result := make([]symtab.FuncInfo, 0, mset.Len())
 	for sel := range mset.Methods() {
@@ -219,7 +501,7 @@ func (idx *Indexer) namedMethods(named *types.Named, pkgPath string, docs, bodie
 		if !ok {
 			continue
 		}
-		fi := idx.funcInfo(fn, pkgPath, docs, bodies)
+		fi := idx.funcInfo(fn, pkgPath, docs, bodies, decls)
 		if len(sel.Index()) > 1 {
 			fi.IsPromoted = true
 		}
@@ -234,28 +516,42 @@ func (idx *Indexer) namedMethods(named *types.Named, pkgPath string, docs, bodie
 //
 // Methods inherited from embedded interfaces keep their original receiver type,
 // so we detect them by comparing the method's receiver against named.
-func (idx *Indexer) interfaceMethods(iface *types.Interface, pkgPath string, docs, bodies map[token.Pos]string) []symtab.FuncInfo {
+func (idx *Indexer) interfaceMethods(iface *types.Interface, pkgPath string, docs, bodies map[token.Pos]string, decls map[token.Pos]ast.Node) []symtab.FuncInfo {
 	result := make([]symtab.FuncInfo, 0, iface.NumMethods())
 	explicit := make(map[*types.Func]bool, iface.NumExplicitMethods())
 	for m := range iface.ExplicitMethods() {
 		explicit[m] = true
 	}
 	for m := range iface.Methods() {
-		fi := idx.funcInfo(m, pkgPath, docs, bodies)
+		fi := idx.funcInfo(m, pkgPath, docs, bodies, decls)
 		fi.IsPromoted = !explicit[m]
 		result = append(result, fi)
 	}
 	return result
 }
 
-// interfaceEmbeds returns the type strings of types embedded in an interface.
-func (idx *Indexer) interfaceEmbeds(iface *types.Interface) []string {
-	if iface.NumEmbeddeds() == 0 {
+// interfaceEmbeds returns the type strings of types embedded in an interface,
+// and separately those of the unions of type terms embedded in a constraint.
+func (idx *Indexer) interfaceEmbeds(iface *types.Interface) (embeds, typeSet []string) {
+	for t := range iface.EmbeddedTypes() {
+		switch t.(type) {
+		case *types.Union:
+			typeSet = append(typeSet, types.TypeString(t, nil))
+		default:
+			embeds = append(embeds, types.TypeString(t, nil))
+		}
+	}
+	return embeds, typeSet
+}
+
+// typeParams describes the type parameters in list, or returns nil if there are none.
+func typeParams(list *types.TypeParamList) []symtab.TypeParam {
+	if list.Len() == 0 {
 		return nil
 	}
-	result := make([]string, 0, iface.NumEmbeddeds())
-	for t := range iface.EmbeddedTypes() {
-		result = append(result, types.TypeString(t, nil))
+	result := make([]symtab.TypeParam, 0, list.Len())
+	for tp := range list.TypeParams() {
+		result = append(result, symtab.TypeParam{Name: tp.Obj().Name(), Constraint: types.TypeString(tp.Constraint(), nil)})
 	}
 	return result
 }
@@ -269,6 +565,177 @@ func (idx *Indexer) receiverString(sig *types.Signature) string {
 	return types.TypeString(recv.Type(), nil)
 }
 
+// isTestFile reports whether path names a Go test file.
+func isTestFile(path string) bool {
+	return strings.HasSuffix(path, "_test.go")
+}
+
+// isExternalTest reports whether files, a package's Go files, are all test
+// files, which is only the case for an external test package.
+func isExternalTest(files []string) bool {
+	for _, f := range files {
+		if !isTestFile(f) {
+			return false
+		}
+	}
+	return len(files) > 0
+}
+
+// testKind classifies fn the way go test does: TestXxx(*testing.T),
+// BenchmarkXxx(*testing.B), FuzzXxx(*testing.F), and ExampleXxx() with no
+// parameters or results. It returns "" for any other function, including TestMain.
+func testKind(fn *types.Func) symtab.TestKind {
+	sig, ok := fn.Type().(*types.Signature)
+	if !ok || sig.Recv() != nil || sig.TypeParams().Len() > 0 {
+		return ""
+	}
+	name := fn.Name()
+	switch {
+	case hasTestPrefix(name, "Test") && isTestingParam(sig, "T"):
+		return symtab.TestKindTest
+	case hasTestPrefix(name, "Benchmark") && isTestingParam(sig, "B"):
+		return symtab.TestKindBenchmark
+	case hasTestPrefix(name, "Fuzz") && isTestingParam(sig, "F"):
+		return symtab.TestKindFuzz
+	case hasTestPrefix(name, "Example") && sig.Params().Len() == 0 && sig.Results().Len() == 0:
+		return symtab.TestKindExample
+	}
+	return ""
+}
+
+// hasTestPrefix reports whether name is prefix alone or prefix followed by
+// something other than a lower-case letter, so that "Testify" is not a test.
+func hasTestPrefix(name, prefix string) bool {
+	rest, ok := strings.CutPrefix(name, prefix)
+	if !ok {
+		return false
+	}
+	r, _ := utf8.DecodeRuneInString(rest)
+	return rest == "" || !unicode.IsLower(r)
+}
+
+// isTestingParam reports whether sig takes a single *testing.<name> and returns nothing.
+func isTestingParam(sig *types.Signature, name string) bool {
+	if sig.Params().Len() != 1 || sig.Results().Len() != 0 {
+		return false
+	}
+	ptr, ok := sig.Params().At(0).Type().(*types.Pointer)
+	if !ok {
+		return false
+	}
+	named, ok := ptr.Elem().(*types.Named)
+	if !ok {
+		return false
+	}
+	obj := named.Obj()
+	return obj.Pkg() != nil && obj.Pkg().Path() == "testing" && obj.Name() == name
+}
+
+// loadErrors converts the errors go/packages recorded for pkg, including parse
+// and type errors, into LoadErrors with their source positions split out.
+func loadErrors(pkg *packages.Package) []symtab.LoadError {
+	if len(pkg.Errors) == 0 {
+		return nil
+	}
+	result := make([]symtab.LoadError, 0, len(pkg.Errors))
+	for _, e := range pkg.Errors {
+		le := symtab.LoadError{
+			Package: pkg.PkgPath,
+			Kind:    loadErrorKind(e.Kind),
+			Message: e.Msg,
+		}
+		le.File, le.Line, le.Column = splitPos(e.Pos)
+		result = append(result, le)
+	}
+	return result
+}
+
+// loadErrorKind maps a go/packages error kind to a symtab.LoadErrorKind.
+func loadErrorKind(kind packages.ErrorKind) symtab.LoadErrorKind {
+	switch kind {
+	case packages.ListError:
+		return symtab.LoadErrorList
+	case packages.ParseError:
+		return symtab.LoadErrorParse
+	case packages.TypeError:
+		return symtab.LoadErrorType
+	default:
+		return symtab.LoadErrorUnknown
+	}
+}
+
+// splitPos splits a go/packages position of the form "file:line:col" or
+// "file:line" into its parts. The file name may itself contain colons (e.g.
+// Windows drive letters), so only numeric trailing segments are treated as
+// line and column. Positions without a line number, such as "-", yield an
+// empty file and zero line and column.
+func splitPos(pos string) (file string, line, col int) {
+	rest, n, ok := cutTrailingNumber(pos)
+	if !ok {
+		return "", 0, 0
+	}
+	if file, m, ok := cutTrailingNumber(rest); ok {
+		return file, m, n
+	}
+	return rest, n, 0
+}
+
+// cutTrailingNumber splits s at its last colon and parses what follows as a number.
+func cutTrailingNumber(s string) (before string, n int, ok bool) {
+	i := strings.LastIndexByte(s, ':')
+	if i < 0 {
+		return "", 0, false
+	}
+	n, err := strconv.Atoi(s[i+1:])
+	if err != nil {
+		return "", 0, false
+	}
+	return s[:i], n, true
+}
+
+// importPaths returns the sorted package paths directly imported by pkg.
+func importPaths(pkg *packages.Package) []string {
+	paths := make([]string, 0, len(pkg.Imports))
+	for _, imp := range pkg.Imports {
+		paths = append(paths, imp.PkgPath)
+	}
+	slices.Sort(paths)
+	return paths
+}
+
+// fileImports returns the sorted package paths imported by pkg's non-test
+// files, and those imported only by its _test.go files.
+func fileImports(pkg *packages.Package) (imports, testImports []string) {
+	var inTests []string
+	for _, file := range pkg.Syntax {
+		test := isTestFile(pkg.Fset.Position(file.Package).Filename)
+		for _, spec := range file.Imports {
+			path, err := strconv.Unquote(spec.Path.Value)
+			if err != nil {
+				continue
+			}
+			// Vendored packages are imported by a path that differs from their own.
+			if imp, ok := pkg.Imports[path]; ok {
+				path = imp.PkgPath
+			}
+			if test {
+				inTests = append(inTests, path)
+			} else {
+				imports = append(imports, path)
+			}
+		}
+	}
+	slices.Sort(imports)
+	imports = slices.Compact(imports)
+	for _, path := range inTests {
+		if _, found := slices.BinarySearch(imports, path); !found {
+			testImports = append(testImports, path)
+		}
+	}
+	slices.Sort(testImports)
+	return imports, slices.Compact(testImports)
+}
+
 // isUnderRoot reports whether path is within root (both should be absolute).
 func isUnderRoot(path, root string) bool {
 	rel, err := filepath.Rel(root, path)
@@ -325,6 +792,28 @@ func (idx *Indexer) buildDocMap(files []*ast.File) map[token.Pos]string {
 	return docs
 }
 
+// buildGroupDocMap extracts the doc comments of parenthesized var and const
+// blocks with several specs, keyed by the position of each name declared in
+// them. specDoc leaves these out of the names' own docs.
+func (idx *Indexer) buildGroupDocMap(files []*ast.File) map[token.Pos]string {
+	docs := make(map[token.Pos]string)
+	for _, f := range files {
+		for _, decl := range f.Decls {
+			d, ok := decl.(*ast.GenDecl)
+			if !ok || d.Doc == nil || len(d.Specs) < 2 || d.Tok != token.CONST && d.Tok != token.VAR {
+				continue
+			}
+			doc := strings.TrimSpace(d.Doc.Text())
+			for _, spec := range d.Specs {
+				for _, name := range spec.(*ast.ValueSpec).Names {
+					docs[name.Pos()] = doc
+				}
+			}
+		}
+	}
+	return docs
+}
+
 // buildFieldDocMap extracts comments for struct fields, keyed by field name position.
 func (idx *Indexer) buildFieldDocMap(files []*ast.File) map[token.Pos]string {
 	docs := make(map[token.Pos]string)
@@ -351,6 +840,49 @@ func (idx *Indexer) buildFieldDocMap(files []*ast.File) map[token.Pos]string {
 	return docs
 }
 
+// buildDeclMap finds the declaration of every package-level name and
+// interface method, keyed by the name's position. A declaration is the whole
+// func, type, var, or const declaration, or only the spec naming it within a
+// parenthesized group, without the doc comment. An interface method's
+// declaration is its line in the interface.
+func buildDeclMap(files []*ast.File) map[token.Pos]ast.Node {
+	decls := make(map[token.Pos]ast.Node)
+	for _, f := range files {
+		for _, decl := range f.Decls {
+			switch d := decl.(type) {
+			case *ast.FuncDecl:
+				decls[d.Name.Pos()] = d
+			case *ast.GenDecl:
+				for _, spec := range d.Specs {
+					var node ast.Node = spec
+					if !d.Lparen.IsValid() {
+						node = d
+					}
+					switch s := spec.(type) {
+					case *ast.TypeSpec:
+						decls[s.Name.Pos()] = node
+					case *ast.ValueSpec:
+						for _, name := range s.Names {
+							decls[name.Pos()] = node
+						}
+					}
+				}
+			}
+		}
+		ast.Inspect(f, func(n ast.Node) bool {
+			if it, ok := n.(*ast.InterfaceType); ok {
+				for _, field := range it.Methods.List {
+					for _, name := range field.Names {
+						decls[name.Pos()] = field
+					}
+				}
+			}
+			return true
+		})
+	}
+	return decls
+}
+
 // buildBodyMap extracts the full source text of each function declaration,
 // keyed by the name's position (matching types.Func.Pos()).
 func (idx *Indexer) buildBodyMap(files []*ast.File) map[token.Pos]string {
@@ -4,6 +4,8 @@ import (
 	"go/ast"
 	"go/token"
 	"go/types"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -222,3 +224,330 @@ func TestIndex(t *testing.T) {
 	assert.Equal(t, "Greet", formalEnglish.Methods[0].Name)
 	assert.True(t, formalEnglish.Methods[0].IsPromoted)
 }
+
+func TestSplitPos(t *testing.T) {
+	tests := []struct {
+		pos          string
+		expectedFile string
+		expectedLine int
+		expectedCol  int
+	}{
+		{"/src/a.go:12:5", "/src/a.go", 12, 5},
+		{"/src/a.go:12", "/src/a.go", 12, 0},
+		{`C:\src\a.go:3:1`, `C:\src\a.go`, 3, 1},
+		{"-", "", 0, 0},
+		{"", "", 0, 0},
+		{"/src/a.go", "", 0, 0},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.pos, func(t *testing.T) {
+			file, line, col := splitPos(tc.pos)
+			assert.Equal(t, tc.expectedFile, file)
+			assert.Equal(t, tc.expectedLine, line)
+			assert.Equal(t, tc.expectedCol, col)
+		})
+	}
+}
+
+func TestIndexBrokenPackage(t *testing.T) {
+	const brokenPkg = "example.com/testdata/broken"
+
+	idx, root := indexFixture(t, map[string]string{
+		"broken/ok.go":  "package broken\n\n// Fine still compiles.\nfunc Fine() int { return 1 }\n",
+		"broken/bad.go": "package broken\n\nfunc Bad() int { return \"oops\" }\n",
+	})
+
+	check := func(t *testing.T, idx *Indexer, expectedFuncs int) {
+		t.Helper()
+		pkg := idx.PkgInfos()[brokenPkg]
+		require.NotNil(t, pkg, "broken package must still be indexed")
+		assert.Len(t, pkg.Funcs, expectedFuncs)
+		require.Len(t, pkg.Errors, 1)
+		e := pkg.Errors[0]
+		assert.Equal(t, brokenPkg, e.Package)
+		assert.Equal(t, symtab.LoadErrorType, e.Kind)
+		assert.Equal(t, filepath.Join(root, "broken", "bad.go"), e.File)
+		assert.Equal(t, 3, e.Line)
+		assert.Equal(t, 25, e.Column)
+		assert.Contains(t, e.Message, "cannot use")
+
+		assert.Empty(t, idx.PkgInfos()[greeterPkg].Errors)
+	}
+	check(t, idx, 2)
+
+	// The incremental path records type errors the same way.
+	appendFile(t, filepath.Join(root, "broken", "ok.go"), "\n// Another is new.\nfunc Another() {}\n")
+	_, err := idx.Update()
+	require.NoError(t, err)
+	check(t, idx, 3)
+}
+
+func TestIndexExcludedPackage(t *testing.T) {
+	const excluded = "//go:build integration\n\npackage standalone\n\n// Answer is unrelated to greeter.\nconst Answer = 42\n"
+
+	t.Run("errors are recorded", func(t *testing.T) {
+		idx, root := indexFixture(t, nil)
+
+		writeFile(t, filepath.Join(root, "standalone", "standalone.go"), excluded)
+		actual, err := idx.Update()
+		require.NoError(t, err)
+		assert.Equal(t, []string{standalonePkg}, actual)
+
+		pkg := idx.PkgInfos()[standalonePkg]
+		require.NotNil(t, pkg)
+		assert.Equal(t, filepath.Join(root, "standalone"), pkg.Dir)
+		assert.Empty(t, pkg.Files)
+		assert.Empty(t, pkg.Vars)
+		require.Len(t, pkg.Errors, 1)
+		assert.Equal(t, symtab.LoadErrorList, pkg.Errors[0].Kind)
+		assert.Contains(t, pkg.Errors[0].Message, "build constraints exclude all Go files")
+	})
+
+	t.Run("another configuration includes it", func(t *testing.T) {
+		idx, root := indexFixture(t, nil, WithBuildConfigs(BuildConfig{}, BuildConfig{Tags: []string{"integration"}}))
+
+		writeFile(t, filepath.Join(root, "standalone", "standalone.go"), excluded)
+		_, err := idx.Update()
+		require.NoError(t, err)
+
+		pkg := idx.PkgInfos()[standalonePkg]
+		require.NotNil(t, pkg)
+		assert.Empty(t, pkg.Errors)
+		require.Len(t, pkg.Vars, 1)
+		assert.Equal(t, idx.configNames()[1:], pkg.Vars[0].BuildConfigs)
+	})
+}
+
+func TestIndexTestFiles(t *testing.T) {
+	const greeterTestPkg = greeterPkg + "_test"
+
+	idx, root := indexFixture(t, map[string]string{
+		"greeter/greeter_test.go": `package greeter
+
+import "testing"
+
+type fakeGreeter struct{}
+
+func (fakeGreeter) Greet(string) string { return "" }
+
+func TestGreet(t *testing.T) {}
+
+func BenchmarkGreet(b *testing.B) {}
+
+func Testify(t *testing.T) {}
+
+func helper() {}
+`,
+		"greeter/example_test.go": `package greeter_test
+
+import (
+	"testing"
+
+	"example.com/testdata/greeter"
+)
+
+func ExampleNew() { _ = greeter.New("Hi, ") }
+
+func FuzzGreet(f *testing.F) {}
+
+func TestMain(m *testing.M) {}
+`,
+	})
+
+	check := func(t *testing.T, idx *Indexer, expectedKinds map[string]symtab.TestKind) {
+		t.Helper()
+		kinds := func(pkg *symtab.PackageInfo) map[string]symtab.TestKind {
+			result := make(map[string]symtab.TestKind)
+			for _, fn := range pkg.Funcs {
+				if fn.IsTest {
+					result[fn.Name] = fn.TestKind
+				}
+			}
+			return result
+		}
+
+		pkg := idx.PkgInfos()[greeterPkg]
+		require.NotNil(t, pkg)
+		assert.False(t, pkg.IsTest)
+		assert.Len(t, pkg.Files, 2)
+		assert.Equal(t, expectedKinds, kinds(pkg))
+		for _, ti := range pkg.Types {
+			assert.Equal(t, ti.Name == "fakeGreeter", ti.IsTest, ti.Name)
+		}
+
+		ext := idx.PkgInfos()[greeterTestPkg]
+		require.NotNil(t, ext)
+		assert.True(t, ext.IsTest)
+		assert.Equal(t, "greeter_test", ext.Name)
+		assert.Equal(t, map[string]symtab.TestKind{
+			"ExampleNew": symtab.TestKindExample,
+			"FuzzGreet":  symtab.TestKindFuzz,
+			"TestMain":   "",
+		}, kinds(ext))
+
+		// Importers keep seeing the plain greeter package, not its test variant.
+		assert.Nil(t, idx.TypePkgs()[greeterPkg].Scope().Lookup("fakeGreeter"))
+		assert.Contains(t, idx.TypePkgs(), greeterTestPkg)
+		for _, imp := range idx.TypePkgs()[welcomePkg].Imports() {
+			if imp.Path() == greeterPkg {
+				assert.Same(t, idx.TypePkgs()[greeterPkg], imp)
+			}
+		}
+	}
+	expectedKinds := map[string]symtab.TestKind{
+		"TestGreet":      symtab.TestKindTest,
+		"BenchmarkGreet": symtab.TestKindBenchmark,
+		"Testify":        "",
+		"helper":         "",
+	}
+	check(t, idx, expectedKinds)
+
+	// Editing a test file re-checks the package, its external tests, and its importers.
+	appendFile(t, filepath.Join(root, "greeter", "greeter_test.go"), "\nfunc TestAdded(t *testing.T) {}\n")
+	actual, err := idx.Update()
+	require.NoError(t, err)
+	assert.Equal(t, []string{greeterPkg, greeterTestPkg, welcomePkg}, actual)
+	expectedKinds["TestAdded"] = symtab.TestKindTest
+	check(t, idx, expectedKinds)
+}
+
+func TestIndexConstants(t *testing.T) {
+	const statusPkg = "example.com/testdata/status"
+
+	idx, _ := indexFixture(t, map[string]string{"status/status.go": `package status
+
+// Status is the state of an account.
+type Status int
+
+// Account states, in the order accounts move through them.
+const (
+	// StatusPending awaits confirmation.
+	StatusPending Status = iota + 1
+	StatusActive
+)
+
+// Limits of an account.
+const (
+	Name    = "account"
+	Ratio   = 1.0 / 3
+	Max     = 1 << 40
+	Enabled = true
+)
+
+// Timeout is a standalone constant.
+const Timeout = 30
+
+// Single is the only var in its block.
+var (
+	Single = StatusActive
+)
+`})
+
+	vars := make(map[string]symtab.VarInfo)
+	for _, v := range idx.PkgInfos()[statusPkg].Vars {
+		vars[v.Name] = v
+	}
+	tests := []struct {
+		name     string
+		value    string
+		doc      string
+		groupDoc string
+	}{
+		{name: "StatusPending", value: "1", doc: "StatusPending awaits confirmation.", groupDoc: "Account states, in the order accounts move through them."},
+		{name: "StatusActive", value: "2", groupDoc: "Account states, in the order accounts move through them."},
+		{name: "Name", value: `"account"`, groupDoc: "Limits of an account."},
+		{name: "Ratio", value: "0.333333", groupDoc: "Limits of an account."},
+		{name: "Max", value: "1099511627776", groupDoc: "Limits of an account."},
+		{name: "Enabled", value: "true", groupDoc: "Limits of an account."},
+		{name: "Timeout", value: "30", doc: "Timeout is a standalone constant."},
+		{name: "Single", doc: "Single is the only var in its block."},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v, ok := vars[tt.name]
+			require.True(t, ok)
+			assert.Equal(t, tt.value, v.Value)
+			assert.Equal(t, tt.doc, v.Doc)
+			assert.Equal(t, tt.groupDoc, v.GroupDoc)
+		})
+	}
+}
+
+func TestIndexRanges(t *testing.T) {
+	const rangesPkg = "example.com/testdata/ranges"
+
+	src := `package ranges
+
+import "sync"
+
+// Shape has an area.
+type Shape interface {
+	// Area returns the area.
+	Area() float64
+}
+
+// Square is a Shape.
+type Square struct{ sync.Mutex }
+
+// Area implements Shape.
+func (s *Square) Area() float64 {
+	return 1
+}
+
+const (
+	// Small is small.
+	Small, Large = 1, 2
+)
+
+var Unit = Square{}
+`
+	idx, root := indexFixture(t, map[string]string{"ranges/ranges.go": src})
+	file := filepath.Join(root, "ranges", "ranges.go")
+	pkg := idx.PkgInfos()[rangesPkg]
+	require.NotNil(t, pkg)
+
+	text := func(t *testing.T, r *symtab.Range) string {
+		t.Helper()
+		require.NotNil(t, r)
+		return src[r.Start.Offset:r.End.Offset]
+	}
+	check := func(t *testing.T, loc symtab.Location, name, decl string) {
+		t.Helper()
+		assert.Equal(t, file, loc.File)
+		assert.Equal(t, name, text(t, loc.Name))
+		assert.Equal(t, decl, text(t, loc.Decl))
+		assert.Equal(t, loc.Line, loc.Name.Start.Line)
+		assert.Equal(t, loc.Column, loc.Name.Start.Column)
+	}
+
+	types := make(map[string]symtab.TypeInfo)
+	for _, ti := range pkg.Types {
+		types[ti.Name] = ti
+	}
+	check(t, types["Shape"].Location, "Shape", "type Shape interface {\n\t// Area returns the area.\n\tArea() float64\n}")
+	check(t, types["Shape"].Methods[0].Location, "Area", "Area() float64")
+	check(t, types["Square"].Location, "Square", "type Square struct{ sync.Mutex }")
+
+	methods := make(map[string]symtab.FuncInfo)
+	for _, m := range types["Square"].Methods {
+		methods[m.Name] = m
+	}
+	area := methods["Area"].Location
+	check(t, area, "Area", "func (s *Square) Area() float64 {\n\treturn 1\n}")
+	assert.Equal(t, symtab.Position{Line: 15, Column: 18, Offset: strings.Index(src, "Area() float64 {")}, area.Name.Start)
+	assert.Equal(t, symtab.Position{Line: 17, Column: 2, Offset: strings.Index(src, "}\n\nconst") + 1}, area.Decl.End)
+
+	// Methods promoted from a dependency have no syntax to take ranges from.
+	lock := methods["Lock"].Location
+	assert.Nil(t, lock.Name)
+	assert.Nil(t, lock.Decl)
+
+	vars := make(map[string]symtab.VarInfo)
+	for _, v := range pkg.Vars {
+		vars[v.Name] = v
+	}
+	check(t, vars["Small"].Location, "Small", "Small, Large = 1, 2")
+	check(t, vars["Large"].Location, "Large", "Small, Large = 1, 2")
+	check(t, vars["Unit"].Location, "Unit", "var Unit = Square{}")
+}
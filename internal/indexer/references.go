@@ -0,0 +1,289 @@
+package indexer
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"strings"
+
+	"github.com/tender-barbarian/go-llm-lens/internal/symtab"
+	"golang.org/x/tools/go/packages"
+	"golang.org/x/tools/go/types/objectpath"
+)
+
+// SymbolKey returns the key under which uses of obj are recorded in
+// symtab.PackageInfo.Refs: its package path and its object path within that
+// package. Keys stay the same when the package is type-checked again, so they
+// can be compared across loads and stored in the cache. It fails for objects
+// that are neither declared at package level nor a field or method of such a
+// declaration, such as local variables.
+func SymbolKey(obj types.Object) (string, error) {
+	var enc objectpath.Encoder
+	return symbolKey(&enc, obj)
+}
+
+// Object returns the object a SymbolKey names, looking in the package's test
+// variant too for objects declared in _test.go files.
+func (s *Snapshot) Object(key string) (types.Object, error) {
+	pkgPath, path, ok := strings.Cut(key, "#")
+	if !ok {
+		return nil, fmt.Errorf("invalid symbol key %q", key)
+	}
+	found := false
+	for _, tp := range []*types.Package{s.typePkgs[pkgPath], s.testTypePkgs[pkgPath]} {
+		if tp == nil {
+			continue
+		}
+		found = true
+		if obj, err := objectpath.Object(tp, objectpath.Path(path)); err == nil {
+			return obj, nil
+		}
+	}
+	if !found {
+		return nil, fmt.Errorf("package %q not found in index", pkgPath)
+	}
+	return nil, fmt.Errorf("symbol %q not found in package %q", path, pkgPath)
+}
+
+// symbolKey implements SymbolKey, sharing enc's lookups across calls.
+func symbolKey(enc *objectpath.Encoder, obj types.Object) (string, error) {
+	if obj.Pkg() == nil {
+		return "", fmt.Errorf("%s is predeclared", obj.Name())
+	}
+	// The path of a package-level object is its name, but objectpath only
+	// produces it for exported objects and types.
+	if obj.Parent() == obj.Pkg().Scope() {
+		return obj.Pkg().Path() + "#" + obj.Name(), nil
+	}
+	path, err := enc.For(origin(obj))
+	if err != nil {
+		return "", err
+	}
+	return obj.Pkg().Path() + "#" + string(path), nil
+}
+
+// origin returns the generic declaration an instantiated method or field
+// comes from, or obj itself.
+func origin(obj types.Object) types.Object {
+	switch o := obj.(type) {
+	case *types.Func:
+		return o.Origin()
+	case *types.Var:
+		return o.Origin()
+	}
+	return obj
+}
+
+// identRole is how the syntax around an identifier uses it.
+type identRole int
+
+const (
+	roleRead     identRole = iota
+	roleCall               // the function of a call expression
+	roleWrite              // assigned to, or incremented or decremented
+	roleFieldKey           // a key in a composite literal, which writes it if it is a struct field
+)
+
+// references collects the uses pkg makes of symbols declared in directories lr
+// indexes, keyed by SymbolKey. Declarations themselves are not uses.
+func (idx *Indexer) references(lr *loadRoot, pkg *packages.Package) map[string][]symtab.Reference {
+	if pkg.TypesInfo == nil {
+		return nil
+	}
+	var enc objectpath.Encoder
+	keys := make(map[types.Object]string) // "" for objects whose uses are not recorded
+	refs := make(map[string][]symtab.Reference)
+	for _, file := range pkg.Syntax {
+		roles := identRoles(file)
+		for _, decl := range file.Decls {
+			fn := enclosingFunc(decl)
+			// The receiver of a method of a generic type names the type with
+			// the method's own type parameters, which instantiates nothing.
+			recv := recvTypeIdent(decl)
+			ast.Inspect(decl, func(n ast.Node) bool {
+				id, ok := n.(*ast.Ident)
+				if !ok {
+					return true
+				}
+				obj := pkg.TypesInfo.Uses[id]
+				if obj == nil {
+					return true
+				}
+				obj = origin(obj)
+				key, seen := keys[obj]
+				if !seen {
+					key = idx.recordedKey(lr, &enc, obj)
+					keys[obj] = key
+				}
+				if key == "" {
+					return true
+				}
+				pos := idx.fset.Position(id.Pos())
+				ref := symtab.Reference{
+					Package:  pkg.PkgPath,
+					Func:     fn,
+					Kind:     refKind(obj, roles[id]),
+					IsTest:   isTestFile(pos.Filename),
+					Location: symtab.Location{File: pos.Filename, Line: pos.Line, Column: pos.Column},
+				}
+				if id != recv {
+					ref.TypeArgs = typeArgs(pkg.TypesInfo.Instances[id].TypeArgs)
+				}
+				refs[key] = append(refs[key], ref)
+				return true
+			})
+		}
+	}
+	if len(refs) == 0 {
+		return nil
+	}
+	return refs
+}
+
+// recordedKey returns the SymbolKey of obj if its uses are recorded, or "" if
+// obj is local, predeclared, an import name, or declared outside lr.
+func (idx *Indexer) recordedKey(lr *loadRoot, enc *objectpath.Encoder, obj types.Object) string {
+	if _, ok := obj.(*types.PkgName); ok || obj.Pkg() == nil {
+		return ""
+	}
+	if obj.Parent() != nil && obj.Parent() != obj.Pkg().Scope() {
+		return "" // function-scoped
+	}
+	if !lr.contains(idx.fset.Position(obj.Pos()).Filename) {
+		return ""
+	}
+	key, err := symbolKey(enc, obj)
+	if err != nil {
+		return ""
+	}
+	return key
+}
+
+// typeArgs formats the type arguments of an instantiation, or returns nil if
+// there are none.
+func typeArgs(list *types.TypeList) []string {
+	if list.Len() == 0 {
+		return nil
+	}
+	result := make([]string, 0, list.Len())
+	for t := range list.Types() {
+		result = append(result, types.TypeString(t, nil))
+	}
+	return result
+}
+
+// refKind classifies a use of obj by an identifier with role.
+func refKind(obj types.Object, role identRole) symtab.RefKind {
+	if _, ok := obj.(*types.TypeName); ok {
+		return symtab.RefKindType
+	}
+	switch role {
+	case roleCall:
+		return symtab.RefKindCall
+	case roleWrite:
+		return symtab.RefKindWrite
+	case roleFieldKey:
+		if v, ok := obj.(*types.Var); ok && v.IsField() {
+			return symtab.RefKindWrite
+		}
+	}
+	return symtab.RefKindRead
+}
+
+// identRoles finds the identifiers in file that are called or written to.
+// Identifiers missing from the result are read.
+func identRoles(file *ast.File) map[*ast.Ident]identRole {
+	roles := make(map[*ast.Ident]identRole)
+	mark := func(expr ast.Expr, role identRole) {
+		if id := targetIdent(expr); id != nil {
+			roles[id] = role
+		}
+	}
+	ast.Inspect(file, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.CallExpr:
+			fun := ast.Unparen(n.Fun)
+			// Explicitly instantiated generic functions: f[int](x).
+			switch f := fun.(type) {
+			case *ast.IndexExpr:
+				fun = f.X
+			case *ast.IndexListExpr:
+				fun = f.X
+			}
+			mark(fun, roleCall)
+		case *ast.AssignStmt:
+			for _, lhs := range n.Lhs {
+				mark(lhs, roleWrite)
+			}
+		case *ast.IncDecStmt:
+			mark(n.X, roleWrite)
+		case *ast.RangeStmt:
+			if n.Tok == token.ASSIGN {
+				mark(n.Key, roleWrite)
+				mark(n.Value, roleWrite)
+			}
+		case *ast.CompositeLit:
+			for _, elt := range n.Elts {
+				if kv, ok := elt.(*ast.KeyValueExpr); ok {
+					mark(kv.Key, roleFieldKey)
+				}
+			}
+		}
+		return true
+	})
+	return roles
+}
+
+// targetIdent returns the identifier naming what expr denotes: expr itself or
+// the selected name of a selector expression. It returns nil for anything else.
+func targetIdent(expr ast.Expr) *ast.Ident {
+	switch e := ast.Unparen(expr).(type) {
+	case *ast.Ident:
+		return e
+	case *ast.SelectorExpr:
+		return e.Sel
+	}
+	return nil
+}
+
+// enclosingFunc returns the name of the function decl declares, as
+// TypeName.MethodName for methods, or "" if decl is not a function.
+func enclosingFunc(decl ast.Decl) string {
+	fd, ok := decl.(*ast.FuncDecl)
+	if !ok {
+		return ""
+	}
+	if recv := recvTypeIdent(fd); recv != nil {
+		return recv.Name + "." + fd.Name.Name
+	}
+	return fd.Name.Name
+}
+
+// recvTypeIdent returns the identifier naming the receiver type of the method
+// decl declares, or nil if decl is not a method.
+func recvTypeIdent(decl ast.Decl) *ast.Ident {
+	fd, ok := decl.(*ast.FuncDecl)
+	if !ok || fd.Recv == nil || len(fd.Recv.List) == 0 {
+		return nil
+	}
+	return typeIdent(fd.Recv.List[0].Type)
+}
+
+// typeIdent returns the identifier naming the type in a receiver type
+// expression such as *T or T[K, V], or nil if there is none.
+func typeIdent(expr ast.Expr) *ast.Ident {
+	switch e := expr.(type) {
+	case *ast.StarExpr:
+		return typeIdent(e.X)
+	case *ast.ParenExpr:
+		return typeIdent(e.X)
+	case *ast.IndexExpr:
+		return typeIdent(e.X)
+	case *ast.IndexListExpr:
+		return typeIdent(e.X)
+	case *ast.Ident:
+		return e
+	}
+	return nil
+}
@@ -0,0 +1,90 @@
+package indexer
+
+import (
+	"go/types"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tender-barbarian/go-llm-lens/internal/symtab"
+)
+
+func TestIndexReferences(t *testing.T) {
+	idx, root := indexFixture(t, map[string]string{"welcome/welcome.go": `package welcome
+
+import "example.com/testdata/greeter"
+
+// Welcome greets a newcomer.
+func Welcome(name string) string {
+	g := greeter.New("Welcome, ")
+	g.Prefix = "Hi, "
+	greeter.MaxLength++
+	return g.Greet(name)
+}
+
+var fallback = greeter.English{Prefix: greeter.DefaultPrefix}
+
+type box[T any] struct{ v T }
+
+func (b *box[T]) get() T { return b.v }
+
+func unbox() int { return (&box[int]{v: 1}).get() }
+
+var boxed = unbox()
+`})
+	file := filepath.Join(root, "welcome", "welcome.go")
+
+	refs := idx.PkgInfos()[welcomePkg].Refs
+	ref := func(line, column int, fn string, kind symtab.RefKind, typeArgs ...string) symtab.Reference {
+		return symtab.Reference{Package: welcomePkg, Func: fn, Kind: kind, TypeArgs: typeArgs, Location: symtab.Location{File: file, Line: line, Column: column}}
+	}
+	tests := []struct {
+		key      string
+		expected []symtab.Reference
+	}{
+		{key: greeterPkg + "#New", expected: []symtab.Reference{ref(7, 15, "Welcome", symtab.RefKindCall)}},
+		{key: greeterPkg + "#MaxLength", expected: []symtab.Reference{ref(9, 10, "Welcome", symtab.RefKindWrite)}},
+		{key: greeterPkg + "#DefaultPrefix", expected: []symtab.Reference{ref(13, 48, "", symtab.RefKindRead)}},
+		{key: greeterPkg + "#English", expected: []symtab.Reference{ref(13, 24, "", symtab.RefKindType)}},
+		{key: welcomePkg + "#unbox", expected: []symtab.Reference{ref(21, 13, "", symtab.RefKindCall)}},
+		{key: welcomePkg + "#box", expected: []symtab.Reference{ref(17, 10, "box.get", symtab.RefKindType), ref(19, 29, "unbox", symtab.RefKindType, "int")}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			assert.Equal(t, tt.expected, refs[tt.key])
+		})
+	}
+
+	t.Run("members", func(t *testing.T) {
+		english := idx.TypePkgs()[greeterPkg].Scope().Lookup("English")
+		require.NotNil(t, english)
+		prefix, err := SymbolKey(english.Type().Underlying().(*types.Struct).Field(0))
+		require.NoError(t, err)
+		assert.Equal(t, []symtab.Reference{
+			ref(8, 4, "Welcome", symtab.RefKindWrite),
+			ref(13, 32, "", symtab.RefKindWrite),
+		}, refs[prefix])
+	})
+
+	t.Run("generic members resolve to their origin", func(t *testing.T) {
+		var kinds []symtab.RefKind
+		for key, rs := range refs {
+			if key == welcomePkg+"#box" {
+				continue
+			}
+			for _, r := range rs {
+				if r.Func == "unbox" {
+					kinds = append(kinds, r.Kind)
+				}
+			}
+		}
+		assert.ElementsMatch(t, []symtab.RefKind{symtab.RefKindWrite, symtab.RefKindCall}, kinds)
+	})
+
+	t.Run("uses outside the roots are not recorded", func(t *testing.T) {
+		for key := range refs {
+			assert.Regexp(t, `^example\.com/testdata/`, key)
+		}
+	})
+}
@@ -0,0 +1,232 @@
+package indexer
+
+import (
+	"errors"
+	"fmt"
+	"go/types"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+
+	"golang.org/x/mod/modfile"
+)
+
+// loadRoot is one root directory together with everything loading it covers.
+// Each loadRoot is loaded by its own go/packages call, so its packages share
+// one type universe; packages of different loadRoots are type-checked separately.
+type loadRoot struct {
+	dir      string        // the root directory; go commands run here
+	patterns []string      // patterns that match every package to index
+	dirs     []string      // directories whose packages are indexed: dir, workspace modules, and local replacements
+	replaces []localModule // local replacement modules, whose packages are only reachable by import path
+
+	// types and testTypes hold the type-checked packages of the last load, keyed
+	// by import path, as recordTypes fills them in. Incremental updates resolve
+	// imports from them so that re-checked packages stay in the same universe.
+	types     map[string]*types.Package
+	testTypes map[string]*types.Package
+}
+
+// localModule is a module replaced by a directory on disk.
+type localModule struct {
+	path string // module path
+	dir  string // absolute directory
+}
+
+// discoverRoots works out what to load for each of roots.
+func discoverRoots(roots []string) ([]*loadRoot, error) {
+	result := make([]*loadRoot, 0, len(roots))
+	for _, root := range roots {
+		lr, err := discoverRoot(root)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, lr)
+	}
+	return result, nil
+}
+
+// discoverRoot works out what to load for dir. A directory with a go.work file
+// covers every module the workspace uses; any other directory covers the
+// packages beneath it, as "./..." does. Either way, modules replaced by local
+// directories in go.work or in a covered go.mod are covered too.
+func discoverRoot(dir string) (*loadRoot, error) {
+	lr := &loadRoot{dir: dir, dirs: []string{dir}}
+
+	workFile := filepath.Join(dir, "go.work")
+	data, err := os.ReadFile(workFile) // #nosec G304 -- path is under the indexed root
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		lr.patterns = []string{"./..."}
+		replaces, err := moduleReplaces(dir)
+		if err != nil {
+			return nil, err
+		}
+		lr.replaces = replaces
+	case err != nil:
+		return nil, fmt.Errorf("reading %s: %w", workFile, err)
+	default:
+		wf, err := modfile.ParseWork(workFile, data, nil)
+		if err != nil {
+			return nil, fmt.Errorf("parsing %s: %w", workFile, err)
+		}
+		for _, use := range wf.Use {
+			modDir := resolveDir(dir, use.Path)
+			modPath, err := modulePath(modDir)
+			if err != nil {
+				return nil, err
+			}
+			replaces, err := moduleReplaces(modDir)
+			if err != nil {
+				return nil, err
+			}
+			lr.dirs = append(lr.dirs, modDir)
+			lr.patterns = append(lr.patterns, modPath+"/...")
+			lr.replaces = append(lr.replaces, replaces...)
+		}
+		lr.replaces = append(lr.replaces, localReplaces(dir, wf.Replace)...)
+	}
+
+	for _, r := range lr.replaces {
+		lr.dirs = append(lr.dirs, r.dir)
+		lr.patterns = append(lr.patterns, r.path+"/...")
+	}
+	slices.Sort(lr.dirs)
+	lr.dirs = slices.Compact(lr.dirs)
+	return lr, nil
+}
+
+// modulePath returns the module path declared by the go.mod file in dir.
+func modulePath(dir string) (string, error) {
+	mf, err := parseModFile(dir)
+	if err != nil {
+		return "", err
+	}
+	if mf == nil || mf.Module == nil {
+		return "", fmt.Errorf("no module declared in %s", filepath.Join(dir, "go.mod"))
+	}
+	return mf.Module.Mod.Path, nil
+}
+
+// moduleReplaces returns the local replacements declared by the go.mod file in
+// dir, or nothing if dir has no go.mod.
+func moduleReplaces(dir string) ([]localModule, error) {
+	mf, err := parseModFile(dir)
+	if err != nil || mf == nil {
+		return nil, err
+	}
+	return localReplaces(dir, mf.Replace), nil
+}
+
+// parseModFile parses the go.mod file in dir. It returns nil without error if
+// there is none.
+func parseModFile(dir string) (*modfile.File, error) {
+	modFile := filepath.Join(dir, "go.mod")
+	data, err := os.ReadFile(modFile) // #nosec G304 -- path is under the indexed root or named by its go.work
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", modFile, err)
+	}
+	mf, err := modfile.Parse(modFile, data, nil)
+	if err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", modFile, err)
+	}
+	return mf, nil
+}
+
+// localReplaces returns the replacements among replaces that point at a
+// directory, resolved relative to dir.
+func localReplaces(dir string, replaces []*modfile.Replace) []localModule {
+	var result []localModule
+	for _, r := range replaces {
+		if r.New.Version == "" && modfile.IsDirectoryPath(r.New.Path) {
+			result = append(result, localModule{path: r.Old.Path, dir: resolveDir(dir, r.New.Path)})
+		}
+	}
+	return result
+}
+
+// resolveDir resolves a directory named in a go.mod or go.work file in dir.
+func resolveDir(dir, name string) string {
+	if filepath.IsAbs(name) {
+		return filepath.Clean(name)
+	}
+	return filepath.Join(dir, filepath.FromSlash(name))
+}
+
+// contains reports whether path lies in one of the directories lr indexes.
+func (lr *loadRoot) contains(path string) bool {
+	return slices.ContainsFunc(lr.dirs, func(dir string) bool { return isUnderRoot(path, dir) })
+}
+
+// pattern returns a package pattern that loads the package in dir. Packages of
+// local replacements are addressed by import path, since the go command only
+// accepts directory patterns inside main modules.
+func (lr *loadRoot) pattern(dir string) (string, error) {
+	for _, r := range lr.replaces {
+		if isUnderRoot(dir, r.dir) {
+			rel, err := filepath.Rel(r.dir, dir)
+			if err != nil {
+				return "", fmt.Errorf("resolving package directory: %w", err)
+			}
+			return path.Join(r.path, filepath.ToSlash(rel)), nil
+		}
+	}
+	return dir, nil
+}
+
+// ownerOf returns the load root that indexes dir, or nil if none does.
+func (idx *Indexer) ownerOf(dir string) *loadRoot {
+	for _, lr := range idx.loadRoots {
+		if lr.contains(dir) {
+			return lr
+		}
+	}
+	return nil
+}
+
+// groupDirs groups package directories by the load root that indexes them and
+// turns each into a package pattern. It returns errNeedFullIndex for a
+// directory no load root covers. The caller must hold writeMu.
+func (idx *Indexer) groupDirs(dirs []string) (map[*loadRoot][]string, error) {
+	groups := make(map[*loadRoot][]string)
+	for _, dir := range dirs {
+		lr := idx.ownerOf(dir)
+		if lr == nil {
+			return nil, errNeedFullIndex
+		}
+		pattern, err := lr.pattern(dir)
+		if err != nil {
+			return nil, err
+		}
+		groups[lr] = append(groups[lr], pattern)
+	}
+	return groups, nil
+}
+
+// sourceDirs returns the directories indexed by any of roots.
+func sourceDirs(roots []*loadRoot) []string {
+	var dirs []string
+	for _, lr := range roots {
+		dirs = append(dirs, lr.dirs...)
+	}
+	slices.Sort(dirs)
+	return slices.Compact(dirs)
+}
+
+// SourceDirs returns the directories whose packages are indexed: the roots,
+// the modules of any go.work workspace among them, and local replacement
+// modules. Each has to be watched separately, since SkipDir stops a walk at
+// nested modules. If a root's go.work or go.mod cannot be read, the roots
+// themselves are returned.
+func (idx *Indexer) SourceDirs() []string {
+	roots, err := discoverRoots(idx.roots)
+	if err != nil {
+		return slices.Clone(idx.roots)
+	}
+	return sourceDirs(roots)
+}
@@ -0,0 +1,142 @@
+package indexer
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newWorkspace writes a go.work workspace into a temporary directory and returns
+// its root and the directory of a module it uses from outside the root. The
+// workspace uses app and lib; app replaces example.com/local with a directory
+// that the workspace does not use.
+func newWorkspace(t *testing.T) (root, extern string) {
+	t.Helper()
+	// The sandboxed test environment may set -mod=mod, which workspace mode rejects.
+	t.Setenv("GOFLAGS", "")
+	base := t.TempDir()
+	root = filepath.Join(base, "ws")
+	extern = filepath.Join(base, "extern")
+
+	writeFile(t, filepath.Join(root, "go.work"), "go 1.21\n\nuse (\n\t./app\n\t./lib\n\t../extern\n)\n")
+	writeFile(t, filepath.Join(root, "lib", "go.mod"), "module example.com/lib\n\ngo 1.21\n")
+	writeFile(t, filepath.Join(root, "lib", "lib.go"), "package lib\n\n// Shape has an area.\ntype Shape interface{ Area() float64 }\n")
+	writeFile(t, filepath.Join(root, "app", "go.mod"), "module example.com/app\n\ngo 1.21\n\nrequire example.com/local v0.0.0\n\nreplace example.com/local => ../local\n")
+	writeFile(t, filepath.Join(root, "app", "app.go"), `package app
+
+import (
+	"example.com/lib"
+	"example.com/local"
+)
+
+// Square is a lib.Shape.
+type Square struct{ Side float64 }
+
+// Area implements lib.Shape.
+func (s Square) Area() float64 { return s.Side * s.Side * local.Scale }
+
+var _ lib.Shape = Square{}
+`)
+	writeFile(t, filepath.Join(root, "local", "go.mod"), "module example.com/local\n\ngo 1.21\n")
+	writeFile(t, filepath.Join(root, "local", "local.go"), "package local\n\n// Scale scales areas.\nconst Scale = 1.0\n")
+	writeFile(t, filepath.Join(extern, "go.mod"), "module example.com/extern\n\ngo 1.21\n")
+	writeFile(t, filepath.Join(extern, "extern.go"), "package extern\n\n// Extern lives outside the workspace root.\nfunc Extern() {}\n")
+	return root, extern
+}
+
+func TestIndexWorkspace(t *testing.T) {
+	root, extern := newWorkspace(t)
+
+	idx, err := New(root)
+	require.NoError(t, err)
+	require.NoError(t, idx.Index())
+
+	expected := map[string]string{
+		"example.com/app":    filepath.Join(root, "app"),
+		"example.com/lib":    filepath.Join(root, "lib"),
+		"example.com/local":  filepath.Join(root, "local"),
+		"example.com/extern": extern,
+	}
+	pkgs := idx.PkgInfos()
+	require.Len(t, pkgs, len(expected))
+	for path, dir := range expected {
+		pkg := pkgs[path]
+		require.NotNil(t, pkg, path)
+		assert.Equal(t, dir, pkg.Dir, path)
+		assert.Equal(t, path, pkg.Module, path)
+		assert.Empty(t, pkg.Errors, path)
+	}
+
+	// Workspace modules share one type universe.
+	typePkgs := idx.TypePkgs()
+	assert.Contains(t, typePkgs["example.com/app"].Imports(), typePkgs["example.com/lib"])
+
+	assert.Equal(t, []string{
+		extern,
+		root,
+		filepath.Join(root, "app"),
+		filepath.Join(root, "lib"),
+		filepath.Join(root, "local"),
+	}, idx.SourceDirs())
+
+	t.Run("update in replaced module", func(t *testing.T) {
+		appendFile(t, filepath.Join(root, "local", "local.go"), "\n// Offset is new.\nconst Offset = 2.0\n")
+		actual, err := idx.Update()
+		require.NoError(t, err)
+		assert.Equal(t, []string{"example.com/app", "example.com/local"}, actual)
+		assert.Contains(t, idx.TypePkgs()["example.com/app"].Imports(), idx.TypePkgs()["example.com/lib"])
+	})
+
+	t.Run("update in module outside root", func(t *testing.T) {
+		appendFile(t, filepath.Join(extern, "extern.go"), "\n// Later is new.\nfunc Later() {}\n")
+		actual, err := idx.Update()
+		require.NoError(t, err)
+		assert.Equal(t, []string{"example.com/extern"}, actual)
+		assert.Len(t, idx.PkgInfos()["example.com/extern"].Funcs, 2)
+	})
+}
+
+func TestIndexMultipleRoots(t *testing.T) {
+	fixture := newFixtureCopy(t)
+	other := t.TempDir()
+	writeFile(t, filepath.Join(other, "go.mod"), "module example.com/other\n\ngo 1.21\n")
+	writeFile(t, filepath.Join(other, "other.go"), "package other\n\n// Other is in a second root.\nfunc Other() {}\n")
+
+	idx, err := New(fixture, WithRoots(other, fixture))
+	require.NoError(t, err)
+	assert.Equal(t, []string{fixture, other}, idx.Roots())
+	require.NoError(t, idx.Index())
+
+	pkgs := idx.PkgInfos()
+	require.Contains(t, pkgs, greeterPkg)
+	require.Contains(t, pkgs, "example.com/other")
+	assert.Equal(t, "example.com/testdata", pkgs[greeterPkg].Module)
+	assert.Equal(t, "example.com/other", pkgs["example.com/other"].Module)
+
+	appendFile(t, filepath.Join(other, "other.go"), "\n// Later is new.\nfunc Later() {}\n")
+	actual, err := idx.Update()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"example.com/other"}, actual)
+
+	// The cache is keyed by the full set of roots.
+	cacheDir := t.TempDir()
+	idx, err = New(fixture, WithRoots(other), WithCacheDir(cacheDir))
+	require.NoError(t, err)
+	require.NoError(t, idx.Index())
+	require.NoError(t, idx.SaveCache())
+
+	single, err := New(fixture, WithCacheDir(cacheDir))
+	require.NoError(t, err)
+	ok, err := single.LoadCache()
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	both, err := New(fixture, WithRoots(other), WithCacheDir(cacheDir))
+	require.NoError(t, err)
+	ok, err = both.LoadCache()
+	require.NoError(t, err)
+	require.True(t, ok)
+	assert.Contains(t, both.PkgInfos(), "example.com/other")
+}
@@ -0,0 +1,84 @@
+package indexer
+
+import (
+	"go/token"
+	"go/types"
+	"time"
+
+	"github.com/tender-barbarian/go-llm-lens/internal/symtab"
+)
+
+// Snapshot is one immutable generation of the index. Index and Update never
+// modify a published Snapshot; they build a new one and swap it in atomically,
+// so a reader holding a Snapshot sees a consistent view for as long as it keeps it.
+type Snapshot struct {
+	generation uint64
+	builtAt    time.Time
+	duration   time.Duration
+	partial    bool // restored from the on-disk cache without type information
+	pkgInfos   map[string]*symtab.PackageInfo
+	typePkgs   map[string]*types.Package // all loaded packages, including deps, for Implements checks
+
+	// testTypePkgs holds the test variants of indexed packages, which add their
+	// in-package _test.go files, keyed by import path.
+	testTypePkgs map[string]*types.Package
+
+	fset *token.FileSet // positions of typePkgs and testTypePkgs
+}
+
+// emptySnapshot is returned before the first Index so that readers never see nil.
+var emptySnapshot = &Snapshot{}
+
+// Generation returns the sequence number of the snapshot. It starts at 1 for
+// the first index and increases by one on every rebuild; 0 means nothing has
+// been indexed yet.
+func (s *Snapshot) Generation() uint64 {
+	return s.generation
+}
+
+// BuiltAt returns when the snapshot was published. It is zero before the first index.
+func (s *Snapshot) BuiltAt() time.Time {
+	return s.builtAt
+}
+
+// Duration returns how long the rebuild that produced the snapshot took.
+func (s *Snapshot) Duration() time.Duration {
+	return s.duration
+}
+
+// Partial reports whether the snapshot was restored from the on-disk cache.
+// A partial snapshot has the symbols of every package but no type information
+// (TypePkgs is empty) until Indexer.LoadTypes or a full Index completes.
+func (s *Snapshot) Partial() bool {
+	return s.partial
+}
+
+// TypePkgs returns the map of all type-checked packages keyed by import path.
+// It includes transitive dependencies, not just packages under the root.
+// The returned map must not be modified.
+func (s *Snapshot) TypePkgs() map[string]*types.Package {
+	return s.typePkgs
+}
+
+// TestTypePkgs returns the test variants of indexed packages keyed by import path.
+// A test variant is type-checked together with the package's in-package _test.go
+// files, so it also declares the types and functions found there. Packages
+// without such files have no entry. The returned map must not be modified.
+func (s *Snapshot) TestTypePkgs() map[string]*types.Package {
+	return s.testTypePkgs
+}
+
+// PkgInfos returns the map of all indexed packages keyed by import path.
+// The returned map and its values must not be modified.
+func (s *Snapshot) PkgInfos() map[string]*symtab.PackageInfo {
+	return s.pkgInfos
+}
+
+// Position returns the source position of pos, a position of an object in
+// TypePkgs or TestTypePkgs.
+func (s *Snapshot) Position(pos token.Pos) token.Position {
+	if s.fset == nil {
+		return token.Position{}
+	}
+	return s.fset.Position(pos)
+}
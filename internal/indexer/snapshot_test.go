@@ -0,0 +1,48 @@
+package indexer
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSnapshot(t *testing.T) {
+	root := newFixtureCopy(t)
+	idx, err := New(root)
+	require.NoError(t, err)
+
+	empty := idx.Snapshot()
+	require.NotNil(t, empty)
+	assert.Zero(t, empty.Generation())
+	assert.Empty(t, empty.PkgInfos())
+
+	require.NoError(t, idx.Index())
+	first := idx.Snapshot()
+	assert.Equal(t, uint64(1), first.Generation())
+	greeter := first.PkgInfos()[greeterPkg]
+	require.NotNil(t, greeter)
+	funcCount := len(greeter.Funcs)
+
+	appendFile(t, filepath.Join(root, "greeter", "greeter.go"), "\n// Added is new.\nfunc Added() {}\n")
+	_, err = idx.Update()
+	require.NoError(t, err)
+
+	second := idx.Snapshot()
+	assert.Equal(t, uint64(2), second.Generation())
+	assert.Len(t, second.PkgInfos()[greeterPkg].Funcs, funcCount+1)
+
+	// The earlier snapshot is untouched by the update.
+	assert.Same(t, greeter, first.PkgInfos()[greeterPkg])
+	assert.Len(t, greeter.Funcs, funcCount)
+	assert.NotSame(t, first.TypePkgs()[greeterPkg], second.TypePkgs()[greeterPkg])
+
+	// Unaffected packages are shared between generations.
+	assert.Same(t, first.PkgInfos()[standalonePkg], second.PkgInfos()[standalonePkg])
+
+	// An update with nothing to do publishes no new generation.
+	_, err = idx.Update()
+	require.NoError(t, err)
+	assert.Same(t, second, idx.Snapshot())
+}
@@ -1,9 +1,34 @@
 package symtab
 
-// Location identifies the source position of a symbol.
+// Location identifies the source position of a symbol. Line and Column are
+// where its name starts.
+//
+// Symbols declared in the indexed packages also carry the exact ranges of
+// their name and of their whole declaration, without its doc comment. A
+// declaration in a parenthesized group is only its own spec. Symbols known
+// only from a dependency's export data, such as methods promoted from it,
+// have neither.
 type Location struct {
-	File string `json:"file"`
-	Line int    `json:"line"`
+	File   string `json:"file"`
+	Line   int    `json:"line"`
+	Column int    `json:"column,omitempty"`
+	Name   *Range `json:"name,omitempty"`
+	Decl   *Range `json:"decl,omitempty"`
+}
+
+// Position is a point in a source file. Line and Column start at 1, and
+// Column counts bytes, as in go/token. Offset is the 0-based byte offset
+// from the start of the file.
+type Position struct {
+	Line   int `json:"line"`
+	Column int `json:"column"`
+	Offset int `json:"offset"`
+}
+
+// Range is the span of source from Start up to, but not including, End.
+type Range struct {
+	Start Position `json:"start"`
+	End   Position `json:"end"`
 }
 
 // FieldInfo describes a single field of a struct type.
@@ -14,16 +39,42 @@ type FieldInfo struct {
 	Comment string `json:"comment,omitempty"`
 }
 
+// TypeParam is a type parameter of a generic function or type.
+type TypeParam struct {
+	Name       string `json:"name"`
+	Constraint string `json:"constraint"`
+}
+
+// TestKind classifies a function that the go test tool runs.
+type TestKind string
+
+const (
+	TestKindTest      TestKind = "test"
+	TestKindBenchmark TestKind = "benchmark"
+	TestKindFuzz      TestKind = "fuzz"
+	TestKindExample   TestKind = "example"
+)
+
 // FuncInfo describes a function or method.
+// IsTest is set for functions declared in _test.go files; TestKind is set
+// only for the test, benchmark, fuzz, and example functions among them.
 type FuncInfo struct {
-	Name       string   `json:"name"`
-	Package    string   `json:"package"`
-	Receiver   string   `json:"receiver,omitempty"`
-	Signature  string   `json:"signature"`
-	IsPromoted bool     `json:"is_promoted,omitempty"`
-	Doc        string   `json:"doc,omitempty"`
-	Body       string   `json:"body,omitempty"`
-	Location   Location `json:"location"`
+	Name      string `json:"name"`
+	Package   string `json:"package"`
+	Receiver  string `json:"receiver,omitempty"`
+	Signature string `json:"signature"`
+	// TypeParams lists the type parameters of a generic function. Methods
+	// have none of their own; those of their receiver are on its TypeInfo.
+	TypeParams []TypeParam `json:"type_params,omitempty"`
+	IsPromoted bool        `json:"is_promoted,omitempty"`
+	IsTest     bool        `json:"is_test,omitempty"`
+	TestKind   TestKind    `json:"test_kind,omitempty"`
+	// BuildConfigs lists the build configurations, such as "linux/amd64", the
+	// symbol exists under. It is only set when several configurations are indexed.
+	BuildConfigs []string `json:"build_configs,omitempty"`
+	Doc          string   `json:"doc,omitempty"`
+	Body         string   `json:"body,omitempty"`
+	Location     Location `json:"location"`
 }
 
 // TypeKind classifies a named type.
@@ -32,44 +83,207 @@ type TypeKind string
 const (
 	TypeKindStruct    TypeKind = "struct"
 	TypeKindInterface TypeKind = "interface"
-	TypeKindAlias     TypeKind = "alias"
-	TypeKindOther     TypeKind = "other"
+	// TypeKindConstraint is an interface that can only be used as a type
+	// constraint, because it has type terms such as ~int | ~float64 or embeds
+	// comparable.
+	TypeKindConstraint TypeKind = "constraint"
+	TypeKindAlias      TypeKind = "alias"
+	TypeKindOther      TypeKind = "other"
 )
 
 // TypeInfo describes a named type (struct, interface, or other).
 type TypeInfo struct {
-	Name     string      `json:"name"`
-	Package  string      `json:"package"`
-	Kind     TypeKind    `json:"kind"`
-	Fields   []FieldInfo `json:"fields,omitempty"`  // struct fields
-	Methods  []FuncInfo  `json:"methods,omitempty"` // declared and promoted methods
-	Embeds   []string    `json:"embeds,omitempty"`  // embedded type names
-	Doc      string      `json:"doc,omitempty"`
-	Location Location    `json:"location"`
+	Name       string      `json:"name"`
+	Package    string      `json:"package"`
+	Kind       TypeKind    `json:"kind"`
+	TypeParams []TypeParam `json:"type_params,omitempty"` // of a generic type
+	// TypeSet lists the unions of type terms of a constraint, such as
+	// "~int | ~float64". The types satisfying the constraint are those in
+	// every union.
+	TypeSet []string    `json:"type_set,omitempty"`
+	Fields  []FieldInfo `json:"fields,omitempty"`  // struct fields
+	Methods []FuncInfo  `json:"methods,omitempty"` // declared and promoted methods
+	Embeds  []string    `json:"embeds,omitempty"`  // embedded type names
+	IsTest  bool        `json:"is_test,omitempty"` // declared in a _test.go file
+	// BuildConfigs lists the build configurations the type exists under; see FuncInfo.
+	BuildConfigs []string `json:"build_configs,omitempty"`
+	Doc          string   `json:"doc,omitempty"`
+	Location     Location `json:"location"`
+}
+
+// InterfaceMatch is an interface that a type satisfies. Interfaces from
+// dependencies and the standard library carry no Doc.
+type InterfaceMatch struct {
+	Name    string `json:"name"`
+	Package string `json:"package,omitempty"` // empty for the predeclared error interface
+	// PointerOnly is set when only a pointer to the type satisfies the
+	// interface, because some of the methods have pointer receivers.
+	PointerOnly bool     `json:"pointer_only"`
+	IsTest      bool     `json:"is_test,omitempty"` // declared in a _test.go file
+	Doc         string   `json:"doc,omitempty"`
+	Location    Location `json:"location"`
+}
+
+// Constructor is a function that returns a value of a type, directly or as
+// an interface the type implements.
+type Constructor struct {
+	Name      string `json:"name"`
+	Package   string `json:"package"`
+	Signature string `json:"signature"`
+	// Interface is the interface the function returns, when it does not return
+	// the type itself.
+	Interface string   `json:"interface,omitempty"`
+	IsTest    bool     `json:"is_test,omitempty"` // declared in a _test.go file
+	Doc       string   `json:"doc,omitempty"`
+	Location  Location `json:"location"`
 }
 
 // VarInfo describes a package-level variable or constant.
 type VarInfo struct {
-	Name     string   `json:"name"`
-	Package  string   `json:"package"`
-	Type     string   `json:"type"`
-	IsConst  bool     `json:"is_const"`
-	Doc      string   `json:"doc,omitempty"`
+	Name    string `json:"name"`
+	Package string `json:"package"`
+	Type    string `json:"type"`
+	IsConst bool   `json:"is_const"`
+	// Value is the value of a constant as Go source writes it, such as 3,
+	// "text", or 0.1.
+	Value  string `json:"value,omitempty"`
+	IsTest bool   `json:"is_test,omitempty"` // declared in a _test.go file
+	// BuildConfigs lists the build configurations the variable exists under; see FuncInfo.
+	BuildConfigs []string `json:"build_configs,omitempty"`
+	Doc          string   `json:"doc,omitempty"`
+	// GroupDoc is the doc comment of the parenthesized var or const block
+	// the variable is declared in together with others.
+	GroupDoc string   `json:"group_doc,omitempty"`
 	Location Location `json:"location"`
 }
 
+// EnumInfo describes a named type together with the constants declared of it.
+type EnumInfo struct {
+	Name    string `json:"name"`
+	Package string `json:"package"`
+	// HasString is set when the type or a pointer to it has a String() string
+	// method, so that values print by name.
+	HasString bool      `json:"has_string"`
+	Doc       string    `json:"doc,omitempty"`
+	Consts    []VarInfo `json:"consts"` // in declaration order
+}
+
+// Hover describes the expression at a source position: its static type and,
+// for an identifier or selector, the object it names.
+type Hover struct {
+	Expression string `json:"expression"` // as Go source, such as g.Greet(name), with literal bodies elided
+	// Type is the static type of the expression, or the type it denotes when
+	// IsType is set. It is empty for package names.
+	Type   string `json:"type,omitempty"`
+	IsType bool   `json:"is_type,omitempty"`
+	Value  string `json:"value,omitempty"` // of a constant expression
+	// Methods lists the methods callable on a value of Type, including those
+	// of *Type when Type is not a pointer or interface, as Name(params) results.
+	Methods []string    `json:"methods,omitempty"`
+	Object  *Definition `json:"object,omitempty"`
+	// Doc is the doc comment of Object, for symbols of the indexed packages.
+	Doc string `json:"doc,omitempty"`
+}
+
+// LoadErrorKind classifies a LoadError by the stage that reported it.
+type LoadErrorKind string
+
+const (
+	LoadErrorList    LoadErrorKind = "list"
+	LoadErrorParse   LoadErrorKind = "parse"
+	LoadErrorType    LoadErrorKind = "type"
+	LoadErrorUnknown LoadErrorKind = "unknown"
+)
+
+// LoadError describes a problem reported while loading, parsing, or type-checking a package.
+// File, Line, and Column are empty when the error has no source position.
+type LoadError struct {
+	Package string        `json:"package"`
+	Kind    LoadErrorKind `json:"kind"`
+	Message string        `json:"message"`
+	File    string        `json:"file,omitempty"`
+	Line    int           `json:"line,omitempty"`
+	Column  int           `json:"column,omitempty"`
+}
+
 // PackageInfo holds all indexed symbols for a single Go package.
+// A package with errors is still indexed with whatever symbols could be
+// recovered; the errors are listed in Errors.
+//
+// Files and symbols include the package's in-package _test.go files. An
+// external test package (package foo_test) is indexed as a separate package
+// with its own import path, such as "example.com/foo_test", and IsTest set.
 type PackageInfo struct {
-	ImportPath string     `json:"import_path"`
-	Name       string     `json:"name"`
-	Dir        string     `json:"dir"`
-	Files      []string   `json:"files"`
-	Funcs      []FuncInfo `json:"funcs"`
-	Types      []TypeInfo `json:"types"`
-	Vars       []VarInfo  `json:"vars"`
+	ImportPath string   `json:"import_path"`
+	Name       string   `json:"name"`
+	Module     string   `json:"module,omitempty"` // path of the module that contains the package
+	Dir        string   `json:"dir"`
+	IsTest     bool     `json:"is_test,omitempty"`
+	Files      []string `json:"files"`
+	// Imports lists the import paths of the packages imported by the non-test
+	// files, and TestImports those imported only by _test.go files.
+	Imports     []string    `json:"imports,omitempty"`
+	TestImports []string    `json:"test_imports,omitempty"`
+	Funcs       []FuncInfo  `json:"funcs"`
+	Types       []TypeInfo  `json:"types"`
+	Vars        []VarInfo   `json:"vars"`
+	Errors      []LoadError `json:"errors,omitempty"`
+	// Refs holds the uses this package makes of symbols declared in indexed
+	// packages, keyed by the used symbol as indexer.SymbolKey returns it.
+	Refs map[string][]Reference `json:"refs,omitempty"`
+}
+
+// PackageDep is a package found while walking the import graph outward from
+// a starting package, toward its imports or its importers.
+type PackageDep struct {
+	Package string `json:"package"`
+	Depth   int    `json:"depth"`         // 1 for direct imports or importers
+	Via     string `json:"via,omitempty"` // the package one step closer to the start; empty at depth 1
+	// External is set for packages outside the index, such as the standard
+	// library and third-party dependencies, whose imports are not followed.
+	External bool `json:"external,omitempty"`
+	IsTest   bool `json:"is_test,omitempty"` // reached only through _test.go files
 }
 
-// SymbolKind classifies a symbol returned by FindSymbol.
+// RefKind classifies how a Reference uses its symbol.
+type RefKind string
+
+const (
+	RefKindRead  RefKind = "read"
+	RefKindWrite RefKind = "write"
+	RefKindCall  RefKind = "call"
+	RefKindType  RefKind = "type"
+)
+
+// CallEdge is a call from one function to another, found while walking the
+// static call graph outward from a starting function. Functions are named as
+// package.Name or package.TypeName.MethodName; package initialization, which
+// includes package-level variable initializers, is package.init.
+type CallEdge struct {
+	Caller string `json:"caller"`
+	Callee string `json:"callee"`
+	// Via is the interface method the call is made through when Callee is one of
+	// its implementations, as found by class hierarchy analysis.
+	Via   string     `json:"via,omitempty"`
+	Depth int        `json:"depth"` // 1 for calls to or from the starting function
+	Func  SymbolRef  `json:"func"`  // the function the edge reaches: the caller when walking callers, the callee when walking callees
+	Sites []Location `json:"sites"` // where Caller makes the call
+}
+
+// Reference is a single use of a symbol.
+type Reference struct {
+	Package string  `json:"package"`        // import path of the package containing the use
+	Func    string  `json:"func,omitempty"` // enclosing function, or TypeName.MethodName; empty outside functions
+	Kind    RefKind `json:"kind"`
+	// TypeArgs are the type arguments, explicit or inferred, when the use
+	// instantiates a generic function or type.
+	TypeArgs []string `json:"type_args,omitempty"`
+	IsTest   bool     `json:"is_test,omitempty"` // in a _test.go file
+	Location Location `json:"location"`
+}
+
+// SymbolKind classifies a symbol returned by FindSymbol, or any declaration
+// a Definition resolves to.
 type SymbolKind string
 
 const (
@@ -78,8 +292,33 @@ const (
 	SymbolKindType   SymbolKind = "type"
 	SymbolKindVar    SymbolKind = "var"
 	SymbolKindConst  SymbolKind = "const"
+
+	// Kinds that only a Definition has.
+	SymbolKindField     SymbolKind = "field"
+	SymbolKindParam     SymbolKind = "param" // including results and receivers
+	SymbolKindLocal     SymbolKind = "local" // a variable declared in a function
+	SymbolKindTypeParam SymbolKind = "type_param"
+	SymbolKindPackage   SymbolKind = "package" // an imported package name
+	SymbolKindLabel     SymbolKind = "label"
+	SymbolKindBuiltin   SymbolKind = "builtin" // predeclared, such as len or error
 )
 
+// Definition is the declaration an identifier at a source position resolves to.
+type Definition struct {
+	Name string     `json:"name"`
+	Kind SymbolKind `json:"kind"`
+	// Package is the import path of the declaring package, or of the
+	// imported package for a package name. It is empty for builtins.
+	Package string `json:"package,omitempty"`
+	// Symbol is the name to look the declaration up by with the other tools:
+	// Name, or TypeName.Member for methods and fields. It is empty for local
+	// declarations.
+	Symbol      string    `json:"symbol,omitempty"`
+	Declaration string    `json:"declaration"` // such as "var count int" or "func (*T) Close() error"
+	IsTest      bool      `json:"is_test,omitempty"`
+	Location    *Location `json:"location,omitempty"` // nil for builtins
+}
+
 // SymbolRef is a lightweight reference returned by cross-package symbol search.
 type SymbolRef struct {
 	Name      string     `json:"name"`
@@ -87,5 +326,14 @@ type SymbolRef struct {
 	Kind      SymbolKind `json:"kind"`
 	Receiver  string     `json:"receiver,omitempty"`
 	Signature string     `json:"signature,omitempty"`
+	IsTest    bool       `json:"is_test,omitempty"`
 	Location  Location   `json:"location"`
 }
+
+// SearchResult is a symbol found by ranked full-text search, with its
+// relevance score and the part of its doc comment that best matches the query.
+type SearchResult struct {
+	SymbolRef
+	Score   float64 `json:"score"`
+	Snippet string  `json:"snippet,omitempty"`
+}
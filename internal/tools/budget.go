@@ -0,0 +1,332 @@
+package tools
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// MinResponseBytes is the smallest response budget, server-wide or per call.
+const MinResponseBytes = 1024
+
+// bytesPerToken approximates how many bytes of JSON one model token covers,
+// to turn a max_tokens budget into bytes.
+const bytesPerToken = 4
+
+// budgetKey is the context key under which withBudget stores the response
+// budget of a call, in bytes.
+type budgetKey struct{}
+
+// withBudget wraps a handler so that its response is fitted to a size budget:
+// the smaller of the max_bytes and max_tokens arguments, and at most maxBytes,
+// the server-wide budget. A maxBytes of 0 sets no server-wide budget.
+func withBudget(maxBytes int, next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		budget := maxBytes
+		for _, arg := range []struct {
+			name  string
+			bytes int
+		}{
+			{"max_bytes", req.GetInt("max_bytes", 0)},
+			{"max_tokens", req.GetInt("max_tokens", 0) * bytesPerToken},
+		} {
+			if _, ok := req.GetArguments()[arg.name]; !ok {
+				continue
+			}
+			if arg.bytes < MinResponseBytes {
+				return nil, fmt.Errorf("%s must allow at least %d bytes (%d tokens)", arg.name, MinResponseBytes, MinResponseBytes/bytesPerToken)
+			}
+			if budget == 0 || arg.bytes < budget {
+				budget = arg.bytes
+			}
+		}
+		if budget > 0 {
+			ctx = context.WithValue(ctx, budgetKey{}, budget)
+		}
+		return next(ctx, req)
+	}
+}
+
+// truncation tells the client what was left out of a response to fit its
+// budget, and how to get it.
+type truncation struct {
+	Omitted []string `json:"omitted,omitempty"` // "bodies", "docs", or "entries"
+	Hints   []string `json:"hints"`
+}
+
+// entries describes the list in a response that may be cut short to fit a
+// budget: the top-level arrays holding it, in order, and the cursor of the
+// page that starts after its first n entries.
+type entries struct {
+	keys   []string
+	cursor func(n int) string
+}
+
+// omission is a kind of member fitResult can leave out of a response, with the
+// hint that tells the client how to get it back.
+type omission struct {
+	name string
+	keys []string
+	hint string
+}
+
+// listOmissions are what fitResult leaves out of a list of symbols, in order,
+// before cutting entries.
+var listOmissions = []omission{
+	{"bodies", []string{"body"}, "Function bodies were left out; get_function returns one function with its body."},
+	{"docs", []string{"doc", "group_doc", "comment"}, "Doc comments were left out; get_function and get_type return them for one symbol."},
+}
+
+// functionOmissions are what fitResult leaves out of a get_function response,
+// which is already the narrowest way to ask for a function.
+var functionOmissions = []omission{
+	{"bodies", []string{"body"}, "The function body was left out; raise max_bytes or max_tokens to get it."},
+	{"docs", []string{"doc"}, "The doc comment was left out; raise max_bytes or max_tokens to get it."},
+}
+
+// typeOmissions are what fitResult leaves out of a get_type response.
+var typeOmissions = []omission{
+	{"bodies", []string{"body"}, "Method bodies were left out; call get_function with TypeName.MethodName for each method."},
+	{"docs", []string{"doc", "group_doc", "comment"}, "Doc comments were left out; raise max_bytes or max_tokens to get them, or call get_function for a method's."},
+}
+
+// fitResult serialises v to JSON, like jsonResult, and fits it to the response
+// budget of ctx. A response over budget loses the members of each of omit in
+// turn, then the last entries of list, if any, until it fits, and says so in a
+// truncated member. At least one entry is kept, so the response can still be
+// over budget.
+func fitResult(ctx context.Context, v any, list *entries, omit []omission) (*mcp.CallToolResult, error) {
+	out, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("encoding response: %w", err)
+	}
+	budget, _ := ctx.Value(budgetKey{}).(int)
+	if budget == 0 || len(out) <= budget {
+		return mcp.NewToolResultText(string(out)), nil
+	}
+	dec := json.NewDecoder(bytes.NewReader(out))
+	dec.UseNumber()
+	tree, err := decodeJSON(dec)
+	if err != nil {
+		return nil, fmt.Errorf("decoding response: %w", err)
+	}
+	obj, ok := tree.(jsonObject)
+	if !ok {
+		return mcp.NewToolResultText(string(out)), nil
+	}
+
+	var t truncation
+	encode := func(obj jsonObject) ([]byte, error) {
+		return json.Marshal(append(obj[:len(obj):len(obj)], jsonMember{Key: "truncated", Value: t}))
+	}
+	for _, o := range omit {
+		stripped, found := omitKeys(obj, o.keys)
+		if !found {
+			continue
+		}
+		obj = stripped.(jsonObject)
+		t.Omitted = append(t.Omitted, o.name)
+		t.Hints = append(t.Hints, o.hint)
+		if out, err = encode(obj); err != nil {
+			return nil, fmt.Errorf("encoding response: %w", err)
+		}
+		if len(out) <= budget {
+			return mcp.NewToolResultText(string(out)), nil
+		}
+	}
+
+	if n := list.count(obj); n > 1 {
+		t.Omitted = append(t.Omitted, "entries")
+		t.Hints = append(t.Hints, "")
+		// Find the most entries that fit, keeping at least one.
+		lo, hi := 1, n-1
+		for lo < hi {
+			mid := (lo + hi + 1) / 2
+			t.Hints[len(t.Hints)-1] = entriesHint(mid, n)
+			if out, err = encode(list.cut(obj, mid)); err != nil {
+				return nil, fmt.Errorf("encoding response: %w", err)
+			}
+			if len(out) <= budget {
+				lo = mid
+			} else {
+				hi = mid - 1
+			}
+		}
+		t.Hints[len(t.Hints)-1] = entriesHint(lo, n)
+		obj = list.cut(obj, lo)
+	}
+	out, err = encode(obj)
+	if err == nil && len(out) > budget {
+		t.Hints = append(t.Hints, "The response is still over budget; raise max_bytes or max_tokens.")
+		out, err = encode(obj)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("encoding response: %w", err)
+	}
+	return mcp.NewToolResultText(string(out)), nil
+}
+
+// entriesHint explains that only kept of the n entries of a page were sent.
+func entriesHint(kept, n int) string {
+	return fmt.Sprintf("Only %d of the %d entries of this page fit; pass next_cursor as cursor to get the rest.", kept, n)
+}
+
+// count returns the number of entries of l in obj, or 0 if l is nil.
+func (l *entries) count(obj jsonObject) int {
+	if l == nil {
+		return 0
+	}
+	n := 0
+	for _, key := range l.keys {
+		arr, _ := obj.get(key).([]any)
+		n += len(arr)
+	}
+	return n
+}
+
+// cut returns obj with only the first n entries of l, and next_cursor set to
+// the page that starts after them.
+func (l *entries) cut(obj jsonObject, n int) jsonObject {
+	obj = obj.clone()
+	rest := n
+	for _, key := range l.keys {
+		arr, _ := obj.get(key).([]any)
+		keep := min(rest, len(arr))
+		obj.set(key, arr[:keep])
+		rest -= keep
+	}
+	obj.set("next_cursor", l.cursor(n))
+	return obj
+}
+
+// jsonObject is a decoded JSON object that keeps its members in order, so that
+// fitting a response to its budget does not reorder it.
+type jsonObject []jsonMember
+
+// jsonMember is a member of a jsonObject.
+type jsonMember struct {
+	Key   string
+	Value any
+}
+
+// MarshalJSON encodes the members of obj in order.
+func (obj jsonObject) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, m := range obj {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		key, err := json.Marshal(m.Key)
+		if err != nil {
+			return nil, err
+		}
+		val, err := json.Marshal(m.Value)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(val)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// get returns the value of the member key, or nil.
+func (obj jsonObject) get(key string) any {
+	for _, m := range obj {
+		if m.Key == key {
+			return m.Value
+		}
+	}
+	return nil
+}
+
+// set sets the value of the member key, adding it at the end if it is missing.
+func (obj *jsonObject) set(key string, value any) {
+	for i := range *obj {
+		if (*obj)[i].Key == key {
+			(*obj)[i].Value = value
+			return
+		}
+	}
+	*obj = append(*obj, jsonMember{Key: key, Value: value})
+}
+
+// clone returns a copy of obj whose members can be set without changing obj.
+func (obj jsonObject) clone() jsonObject {
+	return append(jsonObject(nil), obj...)
+}
+
+// decodeJSON decodes the next JSON value of dec into a jsonObject, []any, or
+// the token of a scalar.
+func decodeJSON(dec *json.Decoder) (any, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	switch tok {
+	case json.Delim('{'):
+		obj := jsonObject{}
+		for dec.More() {
+			key, err := dec.Token()
+			if err != nil {
+				return nil, err
+			}
+			val, err := decodeJSON(dec)
+			if err != nil {
+				return nil, err
+			}
+			obj = append(obj, jsonMember{Key: key.(string), Value: val})
+		}
+		_, err := dec.Token()
+		return obj, err
+	case json.Delim('['):
+		arr := []any{}
+		for dec.More() {
+			val, err := decodeJSON(dec)
+			if err != nil {
+				return nil, err
+			}
+			arr = append(arr, val)
+		}
+		_, err := dec.Token()
+		return arr, err
+	}
+	return tok, nil
+}
+
+// omitKeys returns v without the members named by keys in any of its objects,
+// and reports whether there were any.
+func omitKeys(v any, keys []string) (any, bool) {
+	found := false
+	switch v := v.(type) {
+	case jsonObject:
+		obj := make(jsonObject, 0, len(v))
+		for _, m := range v {
+			if slices.Contains(keys, m.Key) {
+				found = true
+				continue
+			}
+			val, f := omitKeys(m.Value, keys)
+			found = found || f
+			obj = append(obj, jsonMember{Key: m.Key, Value: val})
+		}
+		return obj, found
+	case []any:
+		arr := make([]any, len(v))
+		for i, elem := range v {
+			var f bool
+			arr[i], f = omitKeys(elem, keys)
+			found = found || f
+		}
+		return arr, found
+	}
+	return v, false
+}
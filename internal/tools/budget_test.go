@@ -0,0 +1,173 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"maps"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tender-barbarian/go-llm-lens/internal/finder"
+	"github.com/tender-barbarian/go-llm-lens/internal/indexer"
+	"github.com/tender-barbarian/go-llm-lens/internal/symtab"
+)
+
+// callBudgeted calls handler with a server-wide budget of maxBytes and returns
+// the response text.
+func callBudgeted(t *testing.T, maxBytes int, handler server.ToolHandlerFunc, args map[string]any) string {
+	t.Helper()
+	res, err := withBudget(maxBytes, handler)(context.Background(), mcp.CallToolRequest{Params: mcp.CallToolParams{Arguments: args}})
+	require.NoError(t, err)
+	content, ok := res.Content[0].(mcp.TextContent)
+	require.True(t, ok)
+	return content.Text
+}
+
+func TestResponseBudget(t *testing.T) {
+	idx, err := indexer.New(fixturePkgPath)
+	require.NoError(t, err)
+	require.NoError(t, idx.Index())
+	f := finder.New(idx)
+
+	symbols := getPackageSymbolsHandler(f)
+	symArgs := map[string]any{"package": fixturePkg, "include_unexported": true, "include_bodies": true}
+	whole := callBudgeted(t, 0, symbols, symArgs)
+	require.Contains(t, whole, `"body"`)
+
+	type result struct {
+		symbolsPage
+		Truncated *truncation `json:"truncated"`
+	}
+	decode := func(t *testing.T, text string) result {
+		t.Helper()
+		var r result
+		require.NoError(t, json.Unmarshal([]byte(text), &r))
+		return r
+	}
+
+	t.Run("under budget", func(t *testing.T) {
+		assert.Equal(t, whole, callBudgeted(t, len(whole), symbols, symArgs))
+	})
+
+	t.Run("bodies go first", func(t *testing.T) {
+		text := callBudgeted(t, len(whole)-1, symbols, symArgs)
+		assert.LessOrEqual(t, len(text), len(whole)-1)
+		assert.True(t, strings.HasPrefix(text, `{"funcs":[{"name":`), "members keep their order")
+		r := decode(t, text)
+		require.NotNil(t, r.Truncated)
+		assert.Equal(t, []string{"bodies"}, r.Truncated.Omitted)
+		assert.Len(t, r.Truncated.Hints, 1)
+		assert.Equal(t, r.Total, len(r.Funcs)+len(r.Types)+len(r.Vars))
+		assert.Empty(t, r.NextCursor)
+		for _, fn := range r.Funcs {
+			assert.Empty(t, fn.Body)
+		}
+		assert.NotEmpty(t, r.Funcs[0].Doc)
+	})
+
+	t.Run("then docs, then entries", func(t *testing.T) {
+		text := callBudgeted(t, MinResponseBytes, symbols, symArgs)
+		assert.LessOrEqual(t, len(text), MinResponseBytes)
+		r := decode(t, text)
+		require.NotNil(t, r.Truncated)
+		assert.Equal(t, []string{"bodies", "docs", "entries"}, r.Truncated.Omitted)
+		assert.Contains(t, r.Truncated.Hints[2], "pass next_cursor as cursor")
+		kept := len(r.Funcs) + len(r.Types) + len(r.Vars)
+		assert.Positive(t, kept)
+		assert.Less(t, kept, r.Total)
+		for _, fn := range r.Funcs {
+			assert.Empty(t, fn.Doc)
+		}
+
+		// The cursor picks up right after the entries that were cut.
+		next := maps.Clone(symArgs)
+		next["cursor"] = r.NextCursor
+		rest := decode(t, callBudgeted(t, 0, symbols, next))
+		assert.Nil(t, rest.Truncated)
+		assert.Equal(t, r.Total, kept+len(rest.Funcs)+len(rest.Types)+len(rest.Vars))
+	})
+
+	t.Run("ranked entries are cut from the end", func(t *testing.T) {
+		handler := findSymbolHandler(f)
+		args := map[string]any{"name": "e", "match": "contains"}
+		all := callList[symtab.SymbolRef](t, handler, args)
+
+		var p page[symtab.SymbolRef]
+		require.NoError(t, json.Unmarshal([]byte(callBudgeted(t, MinResponseBytes, handler, args)), &p))
+		require.NotEmpty(t, p.Items)
+		assert.Equal(t, all[:len(p.Items)], p.Items)
+		assert.Equal(t, len(all), p.Total)
+		assert.NotEmpty(t, p.NextCursor)
+	})
+
+	t.Run("calls can only lower the server-wide budget", func(t *testing.T) {
+		args := maps.Clone(symArgs)
+		args["max_bytes"] = len(whole) * 2
+		assert.Contains(t, callBudgeted(t, MinResponseBytes, symbols, args), `"truncated"`)
+
+		args = maps.Clone(symArgs)
+		args["max_tokens"] = MinResponseBytes / bytesPerToken
+		assert.Contains(t, callBudgeted(t, 0, symbols, args), `"truncated"`)
+	})
+
+	t.Run("single symbols get hints for their tool", func(t *testing.T) {
+		tests := []struct {
+			name            string
+			handler         server.ToolHandlerFunc
+			args            map[string]any
+			expectedOmitted []string
+			expectedHint    string
+		}{
+			{
+				name:            "get_function",
+				handler:         getFunctionHandler(f),
+				args:            map[string]any{"package": fixturePkg, "name": "New"},
+				expectedOmitted: []string{"bodies", "docs"},
+				expectedHint:    "raise max_bytes",
+			},
+			{
+				name:            "get_type",
+				handler:         getTypeHandler(f),
+				args:            map[string]any{"package": fixturePkg, "name": "English"},
+				expectedOmitted: []string{"bodies", "docs"},
+				expectedHint:    "call get_function with TypeName.MethodName",
+			},
+			{
+				name:    "get_hover",
+				handler: getHoverHandler(f),
+				args:    map[string]any{"file": filepath.Join("greeter", "greeter.go"), "line": 39, "column": 9},
+			},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				whole := callBudgeted(t, 0, tt.handler, tt.args)
+				var r struct {
+					Truncated *truncation `json:"truncated"`
+				}
+				require.NoError(t, json.Unmarshal([]byte(callBudgeted(t, 1, tt.handler, tt.args)), &r))
+				require.NotNil(t, r.Truncated)
+				assert.Equal(t, tt.expectedOmitted, r.Truncated.Omitted)
+				if tt.expectedOmitted == nil {
+					assert.Contains(t, callBudgeted(t, 1, tt.handler, tt.args), strings.TrimSuffix(whole, "}"), "nothing is left out")
+				} else {
+					assert.Contains(t, r.Truncated.Hints[0], tt.expectedHint)
+				}
+				for _, hint := range r.Truncated.Hints {
+					assert.NotContains(t, hint, tt.name, "hints do not send the client back to the same tool")
+				}
+			})
+		}
+	})
+
+	t.Run("too small", func(t *testing.T) {
+		for _, args := range []map[string]any{{"max_bytes": MinResponseBytes - 1}, {"max_tokens": 10}} {
+			_, err := withBudget(0, symbols)(context.Background(), mcp.CallToolRequest{Params: mcp.CallToolParams{Arguments: args}})
+			assert.ErrorContains(t, err, "must allow at least 1024 bytes")
+		}
+	})
+}
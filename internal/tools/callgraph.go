@@ -0,0 +1,57 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/tender-barbarian/go-llm-lens/internal/finder"
+	"github.com/tender-barbarian/go-llm-lens/internal/symtab"
+)
+
+// maxCallDepth caps how far get_callers and get_callees walk the call graph, so
+// that a single request cannot enumerate the whole codebase.
+const maxCallDepth = 5
+
+// callGraphWalk is a Finder method that walks the call graph from a function.
+type callGraphWalk func(pkgPath, symbol string, depth int, includeTests bool) ([]symtab.CallEdge, error)
+
+// getCallersHandler returns a handler for the get_callers tool.
+// It walks the static call graph from a function toward its callers.
+func getCallersHandler(f *finder.Finder) server.ToolHandlerFunc {
+	return callGraphHandler(f, "callers", f.GetCallers)
+}
+
+// getCalleesHandler returns a handler for the get_callees tool.
+// It walks the static call graph from a function toward the functions it calls.
+func getCalleesHandler(f *finder.Finder) server.ToolHandlerFunc {
+	return callGraphHandler(f, "callees", f.GetCallees)
+}
+
+// callGraphHandler implements get_callers and get_callees on top of walk, a
+// method of f.
+func callGraphHandler(f *finder.Finder, what string, walk callGraphWalk) server.ToolHandlerFunc {
+	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		pkgPath, err := req.RequireString("package")
+		if err != nil {
+			return nil, err
+		}
+		symbol, err := req.RequireString("symbol")
+		if err != nil {
+			return nil, err
+		}
+		depth := req.GetInt("depth", 1)
+		if depth < 1 || depth > maxCallDepth {
+			return nil, fmt.Errorf("depth must be between 1 and %d, got %d", maxCallDepth, depth)
+		}
+
+		return pageResult(ctx, f, req, defaultPage, func() ([]symtab.CallEdge, error) {
+			edges, err := walk(pkgPath, symbol, depth, req.GetBool("include_tests", false))
+			if err != nil {
+				return nil, fmt.Errorf("finding %s of %q: %w", what, symbol, err)
+			}
+			return edges, nil
+		})
+	}
+}
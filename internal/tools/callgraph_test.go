@@ -0,0 +1,61 @@
+package tools
+
+import (
+	"context"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tender-barbarian/go-llm-lens/internal/finder"
+	"github.com/tender-barbarian/go-llm-lens/internal/symtab"
+)
+
+func TestCallGraphHandlers(t *testing.T) {
+	idx, _ := indexFixture(t, map[string]string{"greeter/hello.go": `package greeter
+
+// Hello greets through the Greeter interface.
+func Hello(g Greeter) string { return g.Greet("world") }
+
+// HelloEnglish greets in English.
+func HelloEnglish() string { return Hello(New(DefaultPrefix)) }
+`})
+	f := finder.New(idx)
+
+	t.Run("get_callers", func(t *testing.T) {
+		edges := callList[symtab.CallEdge](t, getCallersHandler(f), map[string]any{"package": fixturePkg, "symbol": "Formal.Greet", "depth": 2})
+		require.Len(t, edges, 2)
+		assert.Equal(t, symtab.CallEdge{
+			Caller: fixturePkg + ".Hello",
+			Callee: fixturePkg + ".Formal.Greet",
+			Via:    fixturePkg + ".Greeter.Greet",
+			Depth:  1,
+			Func:   edges[0].Func,
+			Sites:  edges[0].Sites,
+		}, edges[0])
+		assert.Equal(t, "Hello", edges[0].Func.Name)
+		assert.Equal(t, fixturePkg+".HelloEnglish", edges[1].Caller)
+		assert.Equal(t, 2, edges[1].Depth)
+	})
+
+	t.Run("get_callees", func(t *testing.T) {
+		edges := callList[symtab.CallEdge](t, getCalleesHandler(f), map[string]any{"package": fixturePkg, "symbol": "HelloEnglish"})
+		callees := make([]string, 0, len(edges))
+		for _, e := range edges {
+			callees = append(callees, e.Callee)
+		}
+		assert.Equal(t, []string{fixturePkg + ".Hello", fixturePkg + ".New"}, callees)
+	})
+
+	t.Run("no callers", func(t *testing.T) {
+		edges := callList[symtab.CallEdge](t, getCallersHandler(f), map[string]any{"package": fixturePkg, "symbol": "NoReturn"})
+		assert.Empty(t, edges)
+		assert.NotNil(t, edges)
+	})
+
+	for _, depth := range []int{0, maxCallDepth + 1} {
+		req := mcp.CallToolRequest{Params: mcp.CallToolParams{Arguments: map[string]any{"package": fixturePkg, "symbol": "New", "depth": depth}}}
+		_, err := getCalleesHandler(f)(context.Background(), req)
+		assert.ErrorContains(t, err, "depth must be between 1 and 5")
+	}
+}
@@ -1,6 +1,7 @@
 package tools
 
 const (
+	fixtureModule  = "example.com/testdata"
 	fixturePkg     = "example.com/testdata/greeter"
 	fixturePkgPath = "../../tests/testdata"
 )
@@ -0,0 +1,41 @@
+package tools
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/tender-barbarian/go-llm-lens/internal/finder"
+	"github.com/tender-barbarian/go-llm-lens/internal/symtab"
+)
+
+// getLoadErrorsHandler returns a handler for the get_load_errors tool.
+// It lists the list, parse, and type errors recorded for indexed packages,
+// optionally filtered by import-path prefix, ordered by package and position.
+func getLoadErrorsHandler(f *finder.Finder) server.ToolHandlerFunc {
+	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		filter := req.GetString("filter", "")
+
+		return pageResult(ctx, f, req, defaultPage, func() ([]symtab.LoadError, error) {
+			var errs []symtab.LoadError
+			for _, p := range f.GetPackages() {
+				if filter != "" && !strings.HasPrefix(p.ImportPath, filter) {
+					continue
+				}
+				errs = append(errs, p.Errors...)
+			}
+			slices.SortStableFunc(errs, func(a, b symtab.LoadError) int {
+				return cmp.Or(
+					cmp.Compare(a.Package, b.Package),
+					cmp.Compare(a.File, b.File),
+					cmp.Compare(a.Line, b.Line),
+					cmp.Compare(a.Column, b.Column),
+				)
+			})
+			return errs, nil
+		})
+	}
+}
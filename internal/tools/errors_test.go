@@ -0,0 +1,63 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tender-barbarian/go-llm-lens/internal/finder"
+	"github.com/tender-barbarian/go-llm-lens/internal/symtab"
+)
+
+func TestGetLoadErrorsHandler(t *testing.T) {
+	idx, root := indexFixture(t, map[string]string{"broken/broken.go": "package broken\n\nfunc A() int { return undefinedName }\n\nfunc B() string { return 1 }\n"})
+
+	handler := getLoadErrorsHandler(finder.New(idx))
+
+	tests := []struct {
+		name          string
+		filter        string
+		expectedLines []int
+	}{
+		{name: "all packages", expectedLines: []int{3, 5}},
+		{name: "matching prefix", filter: "example.com/testdata/broken", expectedLines: []int{3, 5}},
+		{name: "package without errors", filter: fixturePkg},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args := map[string]any{}
+			if tt.filter != "" {
+				args["filter"] = tt.filter
+			}
+			req := mcp.CallToolRequest{Params: mcp.CallToolParams{Arguments: args}}
+			res, err := handler(context.Background(), req)
+			require.NoError(t, err)
+
+			content, ok := res.Content[0].(mcp.TextContent)
+			require.True(t, ok)
+
+			var got page[symtab.LoadError]
+			require.NoError(t, json.Unmarshal([]byte(content.Text), &got))
+			actual := got.Items
+
+			lines := make([]int, 0, len(actual))
+			for _, e := range actual {
+				assert.Equal(t, "example.com/testdata/broken", e.Package)
+				assert.Equal(t, symtab.LoadErrorType, e.Kind)
+				assert.Equal(t, filepath.Join(root, "broken", "broken.go"), e.File)
+				assert.NotZero(t, e.Column)
+				lines = append(lines, e.Line)
+			}
+			if tt.expectedLines == nil {
+				assert.Empty(t, lines)
+				return
+			}
+			assert.Equal(t, tt.expectedLines, lines)
+		})
+	}
+}
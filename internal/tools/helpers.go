@@ -2,9 +2,9 @@ package tools
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"go/token"
+	"slices"
 
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
@@ -14,29 +14,33 @@ import (
 const maxInputLen = 2048
 
 // withLengthCheck wraps a handler and rejects any request that contains a
-// string argument longer than maxInputLen bytes.
+// string argument, or a string element of an array argument, longer than
+// maxInputLen bytes.
 func withLengthCheck(next server.ToolHandlerFunc) server.ToolHandlerFunc {
 	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		for field, val := range req.GetArguments() {
-			s, ok := val.(string)
-			if !ok {
-				continue
-			}
-			if len(s) > maxInputLen {
-				return nil, fmt.Errorf("field %q exceeds maximum length of %d bytes", field, maxInputLen)
+			switch v := val.(type) {
+			case string:
+				if len(v) > maxInputLen {
+					return nil, fmt.Errorf("field %q exceeds maximum length of %d bytes", field, maxInputLen)
+				}
+			case []any:
+				for _, elem := range v {
+					if s, ok := elem.(string); ok && len(s) > maxInputLen {
+						return nil, fmt.Errorf("field %q has an element that exceeds maximum length of %d bytes", field, maxInputLen)
+					}
+				}
 			}
 		}
 		return next(ctx, req)
 	}
 }
 
-// jsonResult serialises v to JSON and wraps it in a text tool result.
-func jsonResult(v any) (*mcp.CallToolResult, error) {
-	out, err := json.Marshal(v)
-	if err != nil {
-		return nil, fmt.Errorf("encoding response: %w", err)
-	}
-	return mcp.NewToolResultText(string(out)), nil
+// jsonResult serialises v to JSON and wraps it in a text tool result. Nothing
+// is left out to fit the response budget of ctx; a response over it only says
+// so.
+func jsonResult(ctx context.Context, v any) (*mcp.CallToolResult, error) {
+	return fitResult(ctx, v, nil, nil)
 }
 
 // filterFuncs returns funcs, optionally dropping unexported ones.
@@ -90,3 +94,41 @@ func filterVars(vars []symtab.VarInfo, includeUnexported bool) []symtab.VarInfo
 	}
 	return result
 }
+
+// dropTestFuncs returns funcs without those declared in _test.go files.
+func dropTestFuncs(funcs []symtab.FuncInfo) []symtab.FuncInfo {
+	result := make([]symtab.FuncInfo, 0, len(funcs))
+	for _, f := range funcs {
+		if !f.IsTest {
+			result = append(result, f)
+		}
+	}
+	return result
+}
+
+// dropTestTypes returns types without those declared in _test.go files.
+// Methods declared in test files on the remaining types are dropped as well.
+func dropTestTypes(typs []symtab.TypeInfo) []symtab.TypeInfo {
+	result := make([]symtab.TypeInfo, 0, len(typs))
+	for _, t := range typs {
+		if t.IsTest {
+			continue
+		}
+		if slices.ContainsFunc(t.Methods, func(m symtab.FuncInfo) bool { return m.IsTest }) {
+			t.Methods = dropTestFuncs(t.Methods)
+		}
+		result = append(result, t)
+	}
+	return result
+}
+
+// dropTestVars returns vars without those declared in _test.go files.
+func dropTestVars(vars []symtab.VarInfo) []symtab.VarInfo {
+	result := make([]symtab.VarInfo, 0, len(vars))
+	for _, v := range vars {
+		if !v.IsTest {
+			result = append(result, v)
+		}
+	}
+	return result
+}
@@ -36,6 +36,16 @@ func TestWithLengthCheck(t *testing.T) {
 			args:        map[string]any{"q": strings.Repeat("x", maxInputLen+1)},
 			expectedErr: true,
 		},
+		{
+			name:        "array of short strings passes through",
+			args:        map[string]any{"q": []any{"a", "b"}},
+			expectedErr: false,
+		},
+		{
+			name:        "array element over limit is rejected",
+			args:        map[string]any{"q": []any{"a", strings.Repeat("x", maxInputLen+1)}},
+			expectedErr: true,
+		},
 		{
 			name:        "non-string argument is allowed",
 			args:        map[string]any{"n": 42},
@@ -130,3 +140,18 @@ func TestFilterVars(t *testing.T) {
 		})
 	}
 }
+
+func TestDropTestTypes(t *testing.T) {
+	types := []symtab.TypeInfo{
+		{Name: "Real", Methods: []symtab.FuncInfo{{Name: "Do"}, {Name: "fake", IsTest: true}}},
+		{Name: "Plain", Methods: []symtab.FuncInfo{{Name: "Do"}}},
+		{Name: "fake", IsTest: true},
+	}
+
+	actual := dropTestTypes(types)
+	assert.Equal(t, []symtab.TypeInfo{
+		{Name: "Real", Methods: []symtab.FuncInfo{{Name: "Do"}}},
+		{Name: "Plain", Methods: []symtab.FuncInfo{{Name: "Do"}}},
+	}, actual)
+	assert.Len(t, types[0].Methods, 2, "input must not be modified")
+}
@@ -7,13 +7,14 @@ import (
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
 	"github.com/tender-barbarian/go-llm-lens/internal/finder"
+	"github.com/tender-barbarian/go-llm-lens/internal/symtab"
 )
 
 // findImplementationsHandler returns a handler for the find_implementations tool.
 // It uses go/types.Implements to find all concrete types in the indexed codebase
 // that satisfy the named interface.
 func findImplementationsHandler(f *finder.Finder) server.ToolHandlerFunc {
-	return func(_ context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		pkgPath, err := req.RequireString("package")
 		if err != nil {
 			return nil, err
@@ -23,10 +24,75 @@ func findImplementationsHandler(f *finder.Finder) server.ToolHandlerFunc {
 			return nil, err
 		}
 
-		impls, err := f.FindImplementations(pkgPath, ifaceName)
+		return pageResult(ctx, f, req, defaultPage, func() ([]symtab.TypeInfo, error) {
+			impls, err := f.FindImplementations(pkgPath, ifaceName)
+			if err != nil {
+				return nil, fmt.Errorf("finding implementations of %q: %w", ifaceName, err)
+			}
+			if !req.GetBool("include_tests", false) {
+				impls = dropTestTypes(impls)
+			}
+			return impls, nil
+		})
+	}
+}
+
+// findInterfacesForTypeHandler returns a handler for the find_interfaces_for_type
+// tool. It reports the interfaces, from the indexed codebase and its
+// dependencies, that a type or a pointer to it satisfies.
+func findInterfacesForTypeHandler(f *finder.Finder) server.ToolHandlerFunc {
+	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		pkgPath, err := req.RequireString("package")
+		if err != nil {
+			return nil, err
+		}
+		typeName, err := req.RequireString("type")
+		if err != nil {
+			return nil, err
+		}
+
+		return pageResult(ctx, f, req, defaultPage, func() ([]symtab.InterfaceMatch, error) {
+			ifaces, err := f.FindInterfacesForType(pkgPath, typeName)
+			if err != nil {
+				return nil, fmt.Errorf("finding interfaces for %q: %w", typeName, err)
+			}
+			result := make([]symtab.InterfaceMatch, 0, len(ifaces))
+			for _, m := range ifaces {
+				if !m.IsTest || req.GetBool("include_tests", false) {
+					result = append(result, m)
+				}
+			}
+			return result, nil
+		})
+	}
+}
+
+// findConstructorsHandler returns a handler for the find_constructors tool.
+// It lists the functions that return a value of a type, directly or as an
+// interface it implements, nearest to the type's package first.
+func findConstructorsHandler(f *finder.Finder) server.ToolHandlerFunc {
+	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		pkgPath, err := req.RequireString("package")
 		if err != nil {
-			return nil, fmt.Errorf("finding implementations of %q: %w", ifaceName, err)
+			return nil, err
 		}
-		return jsonResult(impls)
+		typeName, err := req.RequireString("type")
+		if err != nil {
+			return nil, err
+		}
+
+		return pageResult(ctx, f, req, defaultPage, func() ([]symtab.Constructor, error) {
+			ctors, err := f.FindConstructors(pkgPath, typeName)
+			if err != nil {
+				return nil, fmt.Errorf("finding constructors of %q: %w", typeName, err)
+			}
+			result := make([]symtab.Constructor, 0, len(ctors))
+			for _, c := range ctors {
+				if !c.IsTest || req.GetBool("include_tests", false) {
+					result = append(result, c)
+				}
+			}
+			return result, nil
+		})
 	}
 }
@@ -126,9 +126,9 @@ func TestFindImplementationsHandler(t *testing.T) {
 			content, ok := res.Content[0].(mcp.TextContent)
 			require.True(t, ok)
 
-			var actual []symtab.TypeInfo
-			err = json.Unmarshal([]byte(content.Text), &actual)
-			require.NoError(t, err)
+			var got page[symtab.TypeInfo]
+			require.NoError(t, json.Unmarshal([]byte(content.Text), &got))
+			actual := got.Items
 
 			// Zero out Location fields — they contain absolute paths that vary by machine.
 			for i := range actual {
@@ -142,3 +142,42 @@ func TestFindImplementationsHandler(t *testing.T) {
 		})
 	}
 }
+
+func TestFindInterfacesForTypeHandler(t *testing.T) {
+	idx, err := indexer.New(fixturePkgPath)
+	require.NoError(t, err)
+	require.NoError(t, idx.Index())
+
+	handler := findInterfacesForTypeHandler(finder.New(idx))
+
+	actual := callList[symtab.InterfaceMatch](t, handler, map[string]any{"package": fixturePkg, "type": "English"})
+	require.Len(t, actual, 1)
+	actual[0].Location = symtab.Location{}
+	assert.Equal(t, symtab.InterfaceMatch{
+		Name:        "Greeter",
+		Package:     fixturePkg,
+		PointerOnly: true,
+		Doc:         "Greeter is the interface for producing greetings.",
+	}, actual[0])
+
+	req := mcp.CallToolRequest{Params: mcp.CallToolParams{Arguments: map[string]any{"package": fixturePkg, "type": "Greeter"}}}
+	_, err = handler(context.Background(), req)
+	assert.ErrorContains(t, err, "finding interfaces for \"Greeter\"")
+}
+
+func TestFindConstructorsHandler(t *testing.T) {
+	idx, _ := indexFixture(t, map[string]string{"greeter/greeter_test.go": "package greeter\n\nfunc newTestEnglish() *English { return New(\"test: \") }\n"})
+	handler := findConstructorsHandler(finder.New(idx))
+
+	actual := callList[symtab.Constructor](t, handler, map[string]any{"package": fixturePkg, "type": "English"})
+	require.Len(t, actual, 1)
+	assert.Equal(t, "New", actual[0].Name)
+	assert.Equal(t, "New returns an English greeter with the given prefix.", actual[0].Doc)
+
+	actual = callList[symtab.Constructor](t, handler, map[string]any{"package": fixturePkg, "type": "English", "include_tests": true})
+	assert.Len(t, actual, 2)
+
+	actual = callList[symtab.Constructor](t, handler, map[string]any{"package": fixturePkg, "type": "Formal"})
+	assert.NotNil(t, actual)
+	assert.Empty(t, actual)
+}
@@ -0,0 +1,81 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/tender-barbarian/go-llm-lens/internal/indexer"
+)
+
+// indexStatus describes the generation of the index that queries are served from.
+type indexStatus struct {
+	Generation uint64    `json:"generation"`
+	BuiltAt    time.Time `json:"built_at,omitzero"`
+	DurationMS int64     `json:"duration_ms"`
+	Packages   int       `json:"packages"`
+	Symbols    int       `json:"symbols"`
+	Rebuilding bool      `json:"rebuilding"`
+	Partial    bool      `json:"partial"` // restored from the on-disk cache; type-based tools are unavailable until a rebuild completes
+}
+
+// currentStatus summarises the current snapshot of idx.
+func currentStatus(idx *indexer.Indexer) indexStatus {
+	snap := idx.Snapshot()
+	symbols := 0
+	for _, pkg := range snap.PkgInfos() {
+		symbols += len(pkg.Funcs) + len(pkg.Types) + len(pkg.Vars)
+		for _, t := range pkg.Types {
+			for _, m := range t.Methods {
+				if !m.IsPromoted {
+					symbols++
+				}
+			}
+		}
+	}
+	return indexStatus{
+		Generation: snap.Generation(),
+		BuiltAt:    snap.BuiltAt(),
+		DurationMS: snap.Duration().Milliseconds(),
+		Packages:   len(snap.PkgInfos()),
+		Symbols:    symbols,
+		Rebuilding: idx.Building(),
+		Partial:    snap.Partial(),
+	}
+}
+
+// indexStatusHandler returns a handler for the index_status tool.
+// It reports when the current index was built, how long that took, its size,
+// its generation number, and whether a rebuild is in progress.
+func indexStatusHandler(idx *indexer.Indexer) server.ToolHandlerFunc {
+	return func(ctx context.Context, _ mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return jsonResult(ctx, currentStatus(idx))
+	}
+}
+
+// reindexHandler returns a handler for the reindex tool.
+// It rebuilds the whole index, or only the given packages and their importers,
+// waits for the new generation to be published, and saves it to the cache.
+func reindexHandler(idx *indexer.Indexer) server.ToolHandlerFunc {
+	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		pkgs := req.GetStringSlice("packages", nil)
+
+		reindexed, err := idx.Reindex(pkgs)
+		if err != nil {
+			return nil, fmt.Errorf("re-indexing: %w", err)
+		}
+
+		type result struct {
+			Reindexed  []string    `json:"reindexed"`
+			Status     indexStatus `json:"status"`
+			CacheError string      `json:"cache_error,omitempty"` // why the cache could not be saved; the index itself is up to date
+		}
+		r := result{Reindexed: reindexed, Status: currentStatus(idx)}
+		if err := idx.SaveCache(); err != nil {
+			r.CacheError = err.Error()
+		}
+		return jsonResult(ctx, r)
+	}
+}
@@ -0,0 +1,107 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tender-barbarian/go-llm-lens/internal/indexer"
+)
+
+func TestIndexStatusHandler(t *testing.T) {
+	idx, err := indexer.New(fixturePkgPath)
+	require.NoError(t, err)
+	handler := indexStatusHandler(idx)
+
+	call := func() indexStatus {
+		res, err := handler(context.Background(), mcp.CallToolRequest{})
+		require.NoError(t, err)
+		content, ok := res.Content[0].(mcp.TextContent)
+		require.True(t, ok)
+		var actual indexStatus
+		require.NoError(t, json.Unmarshal([]byte(content.Text), &actual))
+		return actual
+	}
+
+	before := call()
+	assert.Equal(t, indexStatus{}, before)
+
+	require.NoError(t, idx.Index())
+	after := call()
+	assert.Equal(t, uint64(1), after.Generation)
+	assert.False(t, after.BuiltAt.IsZero())
+	assert.Equal(t, 1, after.Packages)
+	// 6 funcs + 6 types + 2 vars + 4 declared methods (English.Greet, English.BlankReceiver,
+	// Formal.Greet, NamedGreeter.Name) + 1 interface method (Greeter.Greet).
+	assert.Equal(t, 19, after.Symbols)
+	assert.False(t, after.Rebuilding)
+}
+
+func TestReindexHandler(t *testing.T) {
+	idx, err := indexer.New(fixturePkgPath)
+	require.NoError(t, err)
+	require.NoError(t, idx.Index())
+
+	handler := reindexHandler(idx)
+
+	type result struct {
+		Reindexed []string    `json:"reindexed"`
+		Status    indexStatus `json:"status"`
+	}
+
+	tests := []struct {
+		name               string
+		packages           []any
+		expectedErr        string
+		expectedReindexed  []string
+		expectedGeneration uint64
+	}{
+		{name: "full rebuild", expectedReindexed: []string{fixturePkg}, expectedGeneration: 2},
+		{name: "single package", packages: []any{fixturePkg}, expectedReindexed: []string{fixturePkg}, expectedGeneration: 3},
+		{name: "unknown package", packages: []any{"no/such/pkg"}, expectedErr: `package "no/such/pkg" not found`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args := map[string]any{}
+			if tt.packages != nil {
+				args["packages"] = tt.packages
+			}
+			req := mcp.CallToolRequest{Params: mcp.CallToolParams{Arguments: args}}
+			res, err := handler(context.Background(), req)
+			if tt.expectedErr != "" {
+				require.Error(t, err)
+				assert.ErrorContains(t, err, tt.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+
+			content, ok := res.Content[0].(mcp.TextContent)
+			require.True(t, ok)
+
+			var actual result
+			require.NoError(t, json.Unmarshal([]byte(content.Text), &actual))
+			assert.Equal(t, tt.expectedReindexed, actual.Reindexed)
+			assert.Equal(t, tt.expectedGeneration, actual.Status.Generation)
+		})
+	}
+}
+
+func TestReindexHandlerSavesCache(t *testing.T) {
+	cacheDir := t.TempDir()
+	idx, err := indexer.New(fixturePkgPath, indexer.WithCacheDir(cacheDir))
+	require.NoError(t, err)
+	require.NoError(t, idx.Index())
+
+	_, err = reindexHandler(idx)(context.Background(), mcp.CallToolRequest{})
+	require.NoError(t, err)
+
+	restored, err := indexer.New(fixturePkgPath, indexer.WithCacheDir(cacheDir))
+	require.NoError(t, err)
+	hit, err := restored.LoadCache()
+	require.NoError(t, err)
+	assert.True(t, hit)
+}
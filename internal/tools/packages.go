@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"path/filepath"
+	"slices"
 	"strings"
 
 	"github.com/mark3labs/mcp-go/mcp"
@@ -13,36 +14,59 @@ import (
 )
 
 // listPackagesHandler returns a handler for the list_packages tool.
-// It lists all indexed packages, optionally filtered by import-path prefix.
+// It lists all indexed packages, optionally filtered by import-path prefix and
+// by module.
+// External test packages and test-only symbols are left out of the listing and
+// the counts unless include_tests is set.
 func listPackagesHandler(f *finder.Finder) server.ToolHandlerFunc {
-	return func(_ context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		filter := req.GetString("filter", "")
+		module := req.GetString("module", "")
+		includeTests := req.GetBool("include_tests", false)
 
 		type pkgSummary struct {
 			ImportPath string `json:"import_path"`
 			Name       string `json:"name"`
 			Dir        string `json:"dir"`
+			Module     string `json:"module,omitempty"`
 			FileCount  int    `json:"file_count"`
 			FuncCount  int    `json:"func_count"`
 			TypeCount  int    `json:"type_count"`
+			ErrorCount int    `json:"error_count,omitempty"`
 		}
 
-		pkgs := f.GetPackages()
-		results := make([]pkgSummary, 0, len(pkgs))
-		for _, p := range pkgs {
-			if filter != "" && !strings.HasPrefix(p.ImportPath, filter) {
-				continue
+		return pageResult(ctx, f, req, defaultPage, func() ([]pkgSummary, error) {
+			pkgs := f.GetPackages()
+			results := make([]pkgSummary, 0, len(pkgs))
+			for _, p := range pkgs {
+				if filter != "" && !strings.HasPrefix(p.ImportPath, filter) {
+					continue
+				}
+				if module != "" && p.Module != module {
+					continue
+				}
+				if p.IsTest && !includeTests {
+					continue
+				}
+				files, funcs, typs := p.Files, p.Funcs, p.Types
+				if !includeTests {
+					files = slices.DeleteFunc(slices.Clone(files), func(f string) bool { return strings.HasSuffix(f, "_test.go") })
+					funcs = dropTestFuncs(funcs)
+					typs = dropTestTypes(typs)
+				}
+				results = append(results, pkgSummary{
+					ImportPath: p.ImportPath,
+					Name:       p.Name,
+					Dir:        p.Dir,
+					Module:     p.Module,
+					FileCount:  len(files),
+					FuncCount:  len(funcs),
+					TypeCount:  len(typs),
+					ErrorCount: len(p.Errors),
+				})
 			}
-			results = append(results, pkgSummary{
-				ImportPath: p.ImportPath,
-				Name:       p.Name,
-				Dir:        p.Dir,
-				FileCount:  len(p.Files),
-				FuncCount:  len(p.Funcs),
-				TypeCount:  len(p.Types),
-			})
-		}
-		return jsonResult(results)
+			return results, nil
+		})
 	}
 }
 
@@ -50,7 +74,7 @@ func listPackagesHandler(f *finder.Finder) server.ToolHandlerFunc {
 // It returns all symbols defined in the given file across all indexed packages.
 // The file argument may be absolute or relative; relative paths are matched by suffix.
 func getFileSymbolsHandler(f *finder.Finder) server.ToolHandlerFunc {
-	return func(_ context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		file, err := req.RequireString("file")
 		if err != nil {
 			return nil, fmt.Errorf("getting file parameter: %w", err)
@@ -59,40 +83,36 @@ func getFileSymbolsHandler(f *finder.Finder) server.ToolHandlerFunc {
 		includeBodies := req.GetBool("include_bodies", false)
 		isAbs := filepath.IsAbs(file)
 
-		type result struct {
-			Funcs []symtab.FuncInfo `json:"funcs"`
-			Types []symtab.TypeInfo `json:"types"`
-			Vars  []symtab.VarInfo  `json:"vars"`
-		}
-
-		var funcs []symtab.FuncInfo
-		var types []symtab.TypeInfo
-		var vars []symtab.VarInfo
-		for _, pkg := range f.GetPackages() {
-			for _, fn := range pkg.Funcs {
-				if fileMatches(fn.Location.File, file, isAbs) {
-					funcs = append(funcs, fn)
+		return symbolsResult(ctx, f, req, func() (symbolsPage, error) {
+			var funcs []symtab.FuncInfo
+			var types []symtab.TypeInfo
+			var vars []symtab.VarInfo
+			for _, pkg := range f.GetPackages() {
+				for _, fn := range pkg.Funcs {
+					if fileMatches(fn.Location.File, file, isAbs) {
+						funcs = append(funcs, fn)
+					}
 				}
-			}
-			for _, t := range pkg.Types {
-				if fileMatches(t.Location.File, file, isAbs) {
-					types = append(types, t)
+				for _, t := range pkg.Types {
+					if fileMatches(t.Location.File, file, isAbs) {
+						types = append(types, t)
+					}
 				}
-			}
-			for _, v := range pkg.Vars {
-				if fileMatches(v.Location.File, file, isAbs) {
-					vars = append(vars, v)
+				for _, v := range pkg.Vars {
+					if fileMatches(v.Location.File, file, isAbs) {
+						vars = append(vars, v)
+					}
 				}
 			}
-		}
-		filtered := filterFuncs(funcs, includeUnexported)
-		if !includeBodies {
-			filtered = stripFuncBodies(filtered)
-		}
-		return jsonResult(result{
-			Funcs: filtered,
-			Types: filterTypes(types, includeUnexported),
-			Vars:  filterVars(vars, includeUnexported),
+			filtered := filterFuncs(funcs, includeUnexported)
+			if !includeBodies {
+				filtered = stripFuncBodies(filtered)
+			}
+			return symbolsPage{
+				Funcs: filtered,
+				Types: filterTypes(types, includeUnexported),
+				Vars:  filterVars(vars, includeUnexported),
+			}, nil
 		})
 	}
 }
@@ -108,34 +128,112 @@ func fileMatches(locFile, query string, isAbs bool) bool {
 
 // getPackageSymbolsHandler returns a handler for the get_package_symbols tool.
 // It returns all functions, types, and variables/constants in the given package,
-// optionally including unexported symbols.
+// optionally including unexported symbols and symbols declared in _test.go files.
 func getPackageSymbolsHandler(f *finder.Finder) server.ToolHandlerFunc {
-	return func(_ context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		pkgPath, err := req.RequireString("package")
 		if err != nil {
 			return nil, err
 		}
 		includeUnexported := req.GetBool("include_unexported", false)
 		includeBodies := req.GetBool("include_bodies", false)
+		includeTests := req.GetBool("include_tests", false)
 
-		pkg, ok := f.GetPackage(pkgPath)
-		if !ok {
-			return nil, fmt.Errorf("package %q not found", pkgPath)
-		}
+		return symbolsResult(ctx, f, req, func() (symbolsPage, error) {
+			pkg, ok := f.GetPackage(pkgPath)
+			if !ok {
+				return symbolsPage{}, fmt.Errorf("package %q not found", pkgPath)
+			}
+
+			funcs, typs, vars := pkg.Funcs, pkg.Types, pkg.Vars
+			if !includeTests {
+				funcs, typs, vars = dropTestFuncs(funcs), dropTestTypes(typs), dropTestVars(vars)
+			}
+			funcs = filterFuncs(funcs, includeUnexported)
+			if !includeBodies {
+				funcs = stripFuncBodies(funcs)
+			}
+			return symbolsPage{
+				Funcs: funcs,
+				Types: filterTypes(typs, includeUnexported),
+				Vars:  filterVars(vars, includeUnexported),
+			}, nil
+		})
+	}
+}
 
-		funcs := filterFuncs(pkg.Funcs, includeUnexported)
-		if !includeBodies {
-			funcs = stripFuncBodies(funcs)
+// getPackageImportsHandler returns a handler for the get_package_imports tool.
+// It walks the packages a package imports, directly or up to depth steps away.
+func getPackageImportsHandler(f *finder.Finder) server.ToolHandlerFunc {
+	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		pkgPath, err := req.RequireString("package")
+		if err != nil {
+			return nil, err
 		}
-		type result struct {
-			Funcs []symtab.FuncInfo `json:"funcs"`
-			Types []symtab.TypeInfo `json:"types"`
-			Vars  []symtab.VarInfo  `json:"vars"`
+		return pageResult(ctx, f, req, defaultPage, func() ([]symtab.PackageDep, error) {
+			deps, err := f.GetPackageImports(pkgPath, req.GetInt("depth", 1), req.GetBool("internal_only", false), req.GetBool("include_tests", false))
+			if err != nil {
+				return nil, fmt.Errorf("getting imports of %q: %w", pkgPath, err)
+			}
+			return deps, nil
+		})
+	}
+}
+
+// getPackageImportersHandler returns a handler for the get_package_importers
+// tool. It walks the indexed packages that import a package, directly or up to
+// depth steps away.
+func getPackageImportersHandler(f *finder.Finder) server.ToolHandlerFunc {
+	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		pkgPath, err := req.RequireString("package")
+		if err != nil {
+			return nil, err
 		}
-		return jsonResult(result{
-			Funcs: funcs,
-			Types: filterTypes(pkg.Types, includeUnexported),
-			Vars:  filterVars(pkg.Vars, includeUnexported),
+		return pageResult(ctx, f, req, defaultPage, func() ([]symtab.PackageDep, error) {
+			deps, err := f.GetPackageImporters(pkgPath, req.GetInt("depth", 1), req.GetBool("include_tests", false))
+			if err != nil {
+				return nil, fmt.Errorf("getting importers of %q: %w", pkgPath, err)
+			}
+			return deps, nil
 		})
 	}
 }
+
+// symbolsPage is one page of the symbols of a package or file. Its items are
+// the functions, then the types, then the variables and constants.
+type symbolsPage struct {
+	Funcs      []symtab.FuncInfo `json:"funcs"`
+	Types      []symtab.TypeInfo `json:"types"`
+	Vars       []symtab.VarInfo  `json:"vars"`
+	Total      int               `json:"total"`
+	NextCursor string            `json:"next_cursor,omitempty"`
+}
+
+// symbolsResult returns the page of the symbols list finds that req asks for,
+// fitted to the response budget of ctx.
+func symbolsResult(ctx context.Context, f *finder.Finder, req mcp.CallToolRequest, list func() (symbolsPage, error)) (*mcp.CallToolResult, error) {
+	p, err := parsePage(req, defaultPage)
+	if err != nil {
+		return nil, err
+	}
+	all, gen, err := stableList(f, list)
+	if err != nil {
+		return nil, err
+	}
+	nFuncs, nTypes := len(all.Funcs), len(all.Types)
+	total := nFuncs + nTypes + len(all.Vars)
+	start, end, next, err := p.bounds(total, gen)
+	if err != nil {
+		return nil, err
+	}
+	return fitResult(ctx, symbolsPage{
+		Funcs:      window(all.Funcs, 0, start, end),
+		Types:      window(all.Types, nFuncs, start, end),
+		Vars:       window(all.Vars, nFuncs+nTypes, start, end),
+		Total:      total,
+		NextCursor: next,
+	}, &entries{
+		keys:   []string{"funcs", "types", "vars"},
+		cursor: func(n int) string { return encodeCursor(gen, start+n) },
+	}, listOmissions)
+}
@@ -23,6 +23,7 @@ func TestListPackagesHandler(t *testing.T) {
 	type pkgSummary struct {
 		ImportPath string `json:"import_path"`
 		Name       string `json:"name"`
+		Module     string `json:"module"`
 		FileCount  int    `json:"file_count"`
 		FuncCount  int    `json:"func_count"`
 		TypeCount  int    `json:"type_count"`
@@ -31,11 +32,14 @@ func TestListPackagesHandler(t *testing.T) {
 	tests := []struct {
 		name     string
 		filter   string
+		module   string
 		expected *pkgSummary
 	}{
-		{name: "no filter returns all packages", expected: &pkgSummary{ImportPath: fixturePkg, Name: "greeter", FileCount: 1, FuncCount: 6, TypeCount: 6}},
-		{name: "matching prefix returns package", filter: "example.com", expected: &pkgSummary{ImportPath: fixturePkg, Name: "greeter", FileCount: 1, FuncCount: 6, TypeCount: 6}},
+		{name: "no filter returns all packages", expected: &pkgSummary{ImportPath: fixturePkg, Name: "greeter", Module: fixtureModule, FileCount: 1, FuncCount: 6, TypeCount: 6}},
+		{name: "matching prefix returns package", filter: "example.com", expected: &pkgSummary{ImportPath: fixturePkg, Name: "greeter", Module: fixtureModule, FileCount: 1, FuncCount: 6, TypeCount: 6}},
 		{name: "non-matching prefix returns empty", filter: "no/match"},
+		{name: "matching module returns package", module: fixtureModule, expected: &pkgSummary{ImportPath: fixturePkg, Name: "greeter", Module: fixtureModule, FileCount: 1, FuncCount: 6, TypeCount: 6}},
+		{name: "module must match exactly", module: "example.com"},
 	}
 
 	for _, tt := range tests {
@@ -44,6 +48,9 @@ func TestListPackagesHandler(t *testing.T) {
 			if tt.filter != "" {
 				args["filter"] = tt.filter
 			}
+			if tt.module != "" {
+				args["module"] = tt.module
+			}
 			req := mcp.CallToolRequest{Params: mcp.CallToolParams{Arguments: args}}
 			res, err := handler(context.Background(), req)
 			require.NoError(t, err)
@@ -51,9 +58,9 @@ func TestListPackagesHandler(t *testing.T) {
 			content, ok := res.Content[0].(mcp.TextContent)
 			require.True(t, ok)
 
-			var actual []pkgSummary
-			err = json.Unmarshal([]byte(content.Text), &actual)
-			require.NoError(t, err)
+			var got page[pkgSummary]
+			require.NoError(t, json.Unmarshal([]byte(content.Text), &got))
+			actual := got.Items
 
 			if tt.expected == nil {
 				assert.Empty(t, actual)
@@ -295,3 +302,24 @@ func TestGetPackageSymbolsHandler(t *testing.T) {
 		})
 	}
 }
+
+func TestPackageImportHandlers(t *testing.T) {
+	idx, err := indexer.New(fixturePkgPath)
+	require.NoError(t, err)
+	require.NoError(t, idx.Index())
+	f := finder.New(idx)
+
+	deps := callList[symtab.PackageDep](t, getPackageImportsHandler(f), map[string]any{"package": fixturePkg})
+	assert.Equal(t, []symtab.PackageDep{{Package: "sync", Depth: 1, External: true}}, deps)
+
+	deps = callList[symtab.PackageDep](t, getPackageImportsHandler(f), map[string]any{"package": fixturePkg, "internal_only": true})
+	assert.Empty(t, deps)
+	assert.NotNil(t, deps)
+
+	deps = callList[symtab.PackageDep](t, getPackageImportersHandler(f), map[string]any{"package": "sync"})
+	assert.Equal(t, []symtab.PackageDep{{Package: fixturePkg, Depth: 1}}, deps)
+
+	req := mcp.CallToolRequest{Params: mcp.CallToolParams{Arguments: map[string]any{"package": fixturePkg, "depth": 0}}}
+	_, err = getPackageImportersHandler(f)(context.Background(), req)
+	assert.ErrorContains(t, err, "depth must be at least 1")
+}
@@ -0,0 +1,143 @@
+package tools
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/tender-barbarian/go-llm-lens/internal/finder"
+)
+
+// pageLimits are the default and largest number of items a list tool returns
+// per page.
+type pageLimits struct {
+	def, max int
+}
+
+// defaultPage applies to list tools without limits of their own.
+var defaultPage = pageLimits{def: 100, max: 1000}
+
+// maxListAttempts caps how many times a list is taken again because the index
+// was rebuilt while it was being taken.
+const maxListAttempts = 3
+
+// page is one page of the items a list tool found.
+type page[T any] struct {
+	Items []T `json:"items"`
+	// Total counts the items on every page.
+	Total int `json:"total"`
+	// NextCursor is passed as cursor to get the next page. It is empty on the
+	// last page.
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+// pageRequest is the page a list tool was asked for with its limit and cursor
+// parameters.
+type pageRequest struct {
+	limit  int
+	offset int
+	// generation is the index generation the cursor was issued for, or 0
+	// for the first page.
+	generation uint64
+}
+
+// parsePage reads the limit and cursor parameters of req.
+func parsePage(req mcp.CallToolRequest, limits pageLimits) (pageRequest, error) {
+	p := pageRequest{limit: req.GetInt("limit", limits.def)}
+	if p.limit < 1 || p.limit > limits.max {
+		return pageRequest{}, fmt.Errorf("limit must be between 1 and %d, got %d", limits.max, p.limit)
+	}
+	cursor := req.GetString("cursor", "")
+	if cursor == "" {
+		return p, nil
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return pageRequest{}, fmt.Errorf("invalid cursor %q", cursor)
+	}
+	gen, offset, ok := strings.Cut(string(raw), ":")
+	if !ok {
+		return pageRequest{}, fmt.Errorf("invalid cursor %q", cursor)
+	}
+	p.generation, err = strconv.ParseUint(gen, 10, 64)
+	if err != nil || p.generation == 0 {
+		return pageRequest{}, fmt.Errorf("invalid cursor %q", cursor)
+	}
+	p.offset, err = strconv.Atoi(offset)
+	if err != nil || p.offset < 0 {
+		return pageRequest{}, fmt.Errorf("invalid cursor %q", cursor)
+	}
+	return p, nil
+}
+
+// encodeCursor returns the cursor of the page starting at offset of a list
+// taken from index generation gen.
+func encodeCursor(gen uint64, offset int) string {
+	return base64.RawURLEncoding.EncodeToString(fmt.Appendf(nil, "%d:%d", gen, offset))
+}
+
+// bounds returns the range of the requested page within a list of total items
+// taken from index generation gen, and the cursor of the page after it. It
+// fails if the cursor was issued for another generation, since the items may
+// have changed or moved since.
+func (p pageRequest) bounds(total int, gen uint64) (start, end int, next string, err error) {
+	if p.generation != 0 && p.generation != gen {
+		return 0, 0, "", fmt.Errorf("the index was rebuilt since the cursor was issued (generation %d, now %d); list again without a cursor", p.generation, gen)
+	}
+	start = min(p.offset, total)
+	end = min(start+p.limit, total)
+	if end < total {
+		next = encodeCursor(gen, end)
+	}
+	return start, end, next, nil
+}
+
+// window returns the part of items that falls within [start, end) of a list
+// in which items begin at offset. The result is never nil.
+func window[T any](items []T, offset, start, end int) []T {
+	lo := min(max(start-offset, 0), len(items))
+	hi := min(max(end-offset, 0), len(items))
+	return append(make([]T, 0, hi-lo), items[lo:hi]...)
+}
+
+// stableList calls list until the index generation is the same before and
+// after the call, so that the result belongs to one generation, and returns
+// the result with that generation.
+func stableList[T any](f *finder.Finder, list func() (T, error)) (T, uint64, error) {
+	var zero T
+	for range maxListAttempts {
+		gen := f.Generation()
+		result, err := list()
+		if err != nil {
+			return zero, 0, err
+		}
+		if f.Generation() == gen {
+			return result, gen, nil
+		}
+	}
+	return zero, 0, fmt.Errorf("the index was rebuilt %d times while listing; try again", maxListAttempts)
+}
+
+// pageResult returns the page of the items list finds that req asks for,
+// fitted to the response budget of ctx.
+func pageResult[T any](ctx context.Context, f *finder.Finder, req mcp.CallToolRequest, limits pageLimits, list func() ([]T, error)) (*mcp.CallToolResult, error) {
+	p, err := parsePage(req, limits)
+	if err != nil {
+		return nil, err
+	}
+	items, gen, err := stableList(f, list)
+	if err != nil {
+		return nil, err
+	}
+	start, end, next, err := p.bounds(len(items), gen)
+	if err != nil {
+		return nil, err
+	}
+	return fitResult(ctx, page[T]{Items: window(items, 0, start, end), Total: len(items), NextCursor: next}, &entries{
+		keys:   []string{"items"},
+		cursor: func(n int) string { return encodeCursor(gen, start+n) },
+	}, listOmissions)
+}
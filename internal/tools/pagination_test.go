@@ -0,0 +1,123 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"maps"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tender-barbarian/go-llm-lens/internal/finder"
+	"github.com/tender-barbarian/go-llm-lens/internal/indexer"
+	"github.com/tender-barbarian/go-llm-lens/internal/symtab"
+)
+
+func TestPagination(t *testing.T) {
+	idx, err := indexer.New(fixturePkgPath)
+	require.NoError(t, err)
+	require.NoError(t, idx.Index())
+	f := finder.New(idx)
+	handler := findSymbolHandler(f)
+	args := map[string]any{"name": "e", "match": "contains"}
+
+	all := callList[symtab.SymbolRef](t, handler, args)
+	require.Greater(t, len(all), 3)
+
+	t.Run("pages add up to the whole list", func(t *testing.T) {
+		var pages []symtab.SymbolRef
+		pageArgs := maps.Clone(args)
+		pageArgs["limit"] = 3
+		for {
+			var p page[symtab.SymbolRef]
+			callTool(t, handler, pageArgs, &p)
+			assert.Equal(t, len(all), p.Total)
+			assert.LessOrEqual(t, len(p.Items), 3)
+			pages = append(pages, p.Items...)
+			if p.NextCursor == "" {
+				break
+			}
+			pageArgs["cursor"] = p.NextCursor
+		}
+		assert.Equal(t, all, pages)
+	})
+
+	t.Run("symbol groups", func(t *testing.T) {
+		symbols := getPackageSymbolsHandler(f)
+		symArgs := map[string]any{"package": fixturePkg, "include_unexported": true}
+		var whole symbolsPage
+		callTool(t, symbols, symArgs, &whole)
+		require.Empty(t, whole.NextCursor)
+
+		var funcs, typs, vars int
+		symArgs["limit"] = 4
+		for {
+			var p symbolsPage
+			callTool(t, symbols, symArgs, &p)
+			assert.Equal(t, whole.Total, p.Total)
+			assert.LessOrEqual(t, len(p.Funcs)+len(p.Types)+len(p.Vars), 4)
+			funcs, typs, vars = funcs+len(p.Funcs), typs+len(p.Types), vars+len(p.Vars)
+			if p.NextCursor == "" {
+				break
+			}
+			symArgs["cursor"] = p.NextCursor
+		}
+		assert.Equal(t, []int{len(whole.Funcs), len(whole.Types), len(whole.Vars)}, []int{funcs, typs, vars})
+		assert.Equal(t, whole.Total, funcs+typs+vars)
+	})
+
+	t.Run("cursor past the end", func(t *testing.T) {
+		pageArgs := maps.Clone(args)
+		pageArgs["cursor"] = encodeCursor(f.Generation(), len(all)+10)
+		var p page[symtab.SymbolRef]
+		callTool(t, handler, pageArgs, &p)
+		assert.NotNil(t, p.Items)
+		assert.Empty(t, p.Items)
+		assert.Equal(t, len(all), p.Total)
+		assert.Empty(t, p.NextCursor)
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		tests := []struct {
+			name        string
+			limit       any
+			cursor      string
+			expectedErr string
+		}{
+			{name: "zero limit", limit: 0, expectedErr: "limit must be between 1 and 1000"},
+			{name: "limit too large", limit: 1001, expectedErr: "limit must be between 1 and 1000"},
+			{name: "malformed cursor", cursor: "not a cursor", expectedErr: "invalid cursor"},
+			{name: "negative offset", cursor: encodeCursor(1, -1), expectedErr: "invalid cursor"},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				reqArgs := maps.Clone(args)
+				if tt.limit != nil {
+					reqArgs["limit"] = tt.limit
+				}
+				if tt.cursor != "" {
+					reqArgs["cursor"] = tt.cursor
+				}
+				_, err := handler(context.Background(), mcp.CallToolRequest{Params: mcp.CallToolParams{Arguments: reqArgs}})
+				assert.ErrorContains(t, err, tt.expectedErr)
+			})
+		}
+	})
+
+	t.Run("cursor of an earlier generation", func(t *testing.T) {
+		pageArgs := maps.Clone(args)
+		pageArgs["limit"] = 1
+		req := mcp.CallToolRequest{Params: mcp.CallToolParams{Arguments: pageArgs}}
+		res, err := handler(context.Background(), req)
+		require.NoError(t, err)
+		var p page[symtab.SymbolRef]
+		require.NoError(t, json.Unmarshal([]byte(res.Content[0].(mcp.TextContent).Text), &p))
+		require.NotEmpty(t, p.NextCursor)
+
+		require.NoError(t, idx.Index())
+		pageArgs["cursor"] = p.NextCursor
+		_, err = handler(context.Background(), req)
+		assert.ErrorContains(t, err, "the index was rebuilt since the cursor was issued")
+	})
+}
@@ -0,0 +1,59 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/tender-barbarian/go-llm-lens/internal/finder"
+)
+
+// getDefinitionHandler returns a handler for the get_definition tool.
+// It resolves the identifier at a file position to its declaration.
+func getDefinitionHandler(f *finder.Finder) server.ToolHandlerFunc {
+	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		file, line, column, err := requirePosition(req)
+		if err != nil {
+			return nil, err
+		}
+
+		def, err := f.GetDefinition(file, line, column)
+		if err != nil {
+			return nil, fmt.Errorf("getting definition at %s:%d:%d: %w", file, line, column, err)
+		}
+		return jsonResult(ctx, def)
+	}
+}
+
+// getHoverHandler returns a handler for the get_hover tool.
+// It describes the static type of the expression at a file position.
+func getHoverHandler(f *finder.Finder) server.ToolHandlerFunc {
+	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		file, line, column, err := requirePosition(req)
+		if err != nil {
+			return nil, err
+		}
+
+		hover, err := f.GetHover(file, line, column)
+		if err != nil {
+			return nil, fmt.Errorf("getting hover at %s:%d:%d: %w", file, line, column, err)
+		}
+		return jsonResult(ctx, hover)
+	}
+}
+
+// requirePosition reads the file, line, and column arguments of a tool that
+// takes a source position.
+func requirePosition(req mcp.CallToolRequest) (file string, line, column int, err error) {
+	if file, err = req.RequireString("file"); err != nil {
+		return "", 0, 0, err
+	}
+	if line, err = req.RequireInt("line"); err != nil {
+		return "", 0, 0, err
+	}
+	if column, err = req.RequireInt("column"); err != nil {
+		return "", 0, 0, err
+	}
+	return file, line, column, nil
+}
@@ -0,0 +1,53 @@
+package tools
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tender-barbarian/go-llm-lens/internal/finder"
+	"github.com/tender-barbarian/go-llm-lens/internal/indexer"
+	"github.com/tender-barbarian/go-llm-lens/internal/symtab"
+)
+
+func TestGetDefinitionHandler(t *testing.T) {
+	idx, err := indexer.New(fixturePkgPath)
+	require.NoError(t, err)
+	require.NoError(t, idx.Index())
+	handler := getDefinitionHandler(finder.New(idx))
+
+	t.Run("resolves a use", func(t *testing.T) {
+		// return &English{Prefix: prefix} in New.
+		var def symtab.Definition
+		callTool(t, handler, map[string]any{"file": filepath.Join("greeter", "greeter.go"), "line": 39, "column": 26}, &def)
+		assert.Equal(t, "prefix", def.Name)
+		assert.Equal(t, symtab.SymbolKindParam, def.Kind)
+		require.NotNil(t, def.Location)
+		assert.Equal(t, 38, def.Location.Line)
+	})
+
+	t.Run("missing column", func(t *testing.T) {
+		req := mcp.CallToolRequest{}
+		req.Params.Arguments = map[string]any{"file": "greeter/greeter.go", "line": 39}
+		_, err := handler(context.Background(), req)
+		assert.Error(t, err)
+	})
+}
+
+func TestGetHoverHandler(t *testing.T) {
+	idx, err := indexer.New(fixturePkgPath)
+	require.NoError(t, err)
+	require.NoError(t, idx.Index())
+	handler := getHoverHandler(finder.New(idx))
+
+	// return &English{Prefix: prefix} in New.
+	var hover symtab.Hover
+	callTool(t, handler, map[string]any{"file": filepath.Join("greeter", "greeter.go"), "line": 39, "column": 9}, &hover)
+	assert.Equal(t, "&English{…}", hover.Expression)
+	assert.Equal(t, "*"+fixturePkg+".English", hover.Type)
+	assert.Contains(t, hover.Methods, "Greet(name string) string")
+	assert.Nil(t, hover.Object)
+}
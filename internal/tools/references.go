@@ -0,0 +1,68 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/tender-barbarian/go-llm-lens/internal/finder"
+	"github.com/tender-barbarian/go-llm-lens/internal/symtab"
+)
+
+// findReferencesHandler returns a handler for the find_references tool.
+// It lists every use of a function, type, method, field, variable, or constant
+// across the indexed packages. Uses in _test.go files are left out unless
+// include_tests is set.
+func findReferencesHandler(f *finder.Finder) server.ToolHandlerFunc {
+	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		pkgPath, err := req.RequireString("package")
+		if err != nil {
+			return nil, err
+		}
+		symbol, err := req.RequireString("symbol")
+		if err != nil {
+			return nil, err
+		}
+
+		return pageResult(ctx, f, req, defaultPage, func() ([]symtab.Reference, error) {
+			refs, err := f.FindReferences(pkgPath, symbol)
+			if err != nil {
+				return nil, fmt.Errorf("finding references to %q: %w", symbol, err)
+			}
+			if !req.GetBool("include_tests", false) {
+				refs = slices.DeleteFunc(refs, func(r symtab.Reference) bool { return r.IsTest })
+			}
+			return refs, nil
+		})
+	}
+}
+
+// findInstantiationsHandler returns a handler for the find_instantiations tool.
+// It lists the uses of a generic function or type that instantiate it, with
+// their type arguments. Uses in _test.go files are left out unless
+// include_tests is set.
+func findInstantiationsHandler(f *finder.Finder) server.ToolHandlerFunc {
+	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		pkgPath, err := req.RequireString("package")
+		if err != nil {
+			return nil, err
+		}
+		symbol, err := req.RequireString("symbol")
+		if err != nil {
+			return nil, err
+		}
+
+		return pageResult(ctx, f, req, defaultPage, func() ([]symtab.Reference, error) {
+			refs, err := f.FindInstantiations(pkgPath, symbol)
+			if err != nil {
+				return nil, fmt.Errorf("finding instantiations of %q: %w", symbol, err)
+			}
+			if !req.GetBool("include_tests", false) {
+				refs = slices.DeleteFunc(refs, func(r symtab.Reference) bool { return r.IsTest })
+			}
+			return refs, nil
+		})
+	}
+}
@@ -0,0 +1,65 @@
+package tools
+
+import (
+	"context"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tender-barbarian/go-llm-lens/internal/finder"
+	"github.com/tender-barbarian/go-llm-lens/internal/symtab"
+)
+
+func TestFindReferencesHandler(t *testing.T) {
+	idx, _ := indexFixture(t, map[string]string{"greeter/greeter_test.go": "package greeter\n\nfunc useNew() { _ = New(DefaultPrefix) }\n"})
+	handler := findReferencesHandler(finder.New(idx))
+
+	tests := []struct {
+		name         string
+		symbol       string
+		includeTests bool
+		expected     []symtab.RefKind
+	}{
+		{name: "field uses", symbol: "English.Prefix", expected: []symtab.RefKind{symtab.RefKindRead, symtab.RefKindWrite}},
+		{name: "test uses are dropped by default", symbol: "New", expected: []symtab.RefKind{}},
+		{name: "include_tests keeps test uses", symbol: "New", includeTests: true, expected: []symtab.RefKind{symtab.RefKindCall}},
+		{name: "type uses", symbol: "English", expected: []symtab.RefKind{symtab.RefKindType, symtab.RefKindType, symtab.RefKindType, symtab.RefKindType}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			refs := callList[symtab.Reference](t, handler, map[string]any{"package": fixturePkg, "symbol": tt.symbol, "include_tests": tt.includeTests})
+			actual := make([]symtab.RefKind, 0, len(refs))
+			for _, r := range refs {
+				assert.Equal(t, fixturePkg, r.Package)
+				actual = append(actual, r.Kind)
+			}
+			assert.Equal(t, tt.expected, actual)
+		})
+	}
+
+	t.Run("unknown symbol", func(t *testing.T) {
+		req := mcp.CallToolRequest{Params: mcp.CallToolParams{Arguments: map[string]any{"package": fixturePkg, "symbol": "Nope"}}}
+		_, err := handler(context.Background(), req)
+		assert.ErrorContains(t, err, `symbol "Nope" not found`)
+	})
+}
+
+func TestFindInstantiationsHandler(t *testing.T) {
+	idx, _ := indexFixture(t, map[string]string{
+		"greeter/generic.go":      "package greeter\n\nfunc First[T any](s []T) T { return s[0] }\n\nvar first = First([]string{\"a\"})\n",
+		"greeter/generic_test.go": "package greeter\n\nvar firstInt = First([]int{1})\n",
+	})
+	handler := findInstantiationsHandler(finder.New(idx))
+
+	refs := callList[symtab.Reference](t, handler, map[string]any{"package": fixturePkg, "symbol": "First"})
+	require.Len(t, refs, 1)
+	assert.Equal(t, []string{"string"}, refs[0].TypeArgs)
+
+	refs = callList[symtab.Reference](t, handler, map[string]any{"package": fixturePkg, "symbol": "First", "include_tests": true})
+	assert.Len(t, refs, 2)
+
+	req := mcp.CallToolRequest{Params: mcp.CallToolParams{Arguments: map[string]any{"package": fixturePkg, "symbol": "New"}}}
+	_, err := handler(context.Background(), req)
+	assert.ErrorContains(t, err, "is not a generic function or type")
+}
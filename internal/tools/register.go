@@ -1,56 +1,243 @@
 package tools
 
 import (
+	"fmt"
+
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
 
 	"github.com/tender-barbarian/go-llm-lens/internal/finder"
+	"github.com/tender-barbarian/go-llm-lens/internal/indexer"
 )
 
+// Option configures the tools Register adds.
+type Option func(*config)
+
+// config holds the settings Options change.
+type config struct {
+	maxResponseBytes int
+}
+
+// WithMaxResponseBytes sets the server-wide response budget: responses larger
+// than n bytes are cut down to fit, and calls can only lower it with their
+// max_bytes and max_tokens arguments. An n of 0, the default, sets no limit.
+func WithMaxResponseBytes(n int) Option {
+	return func(c *config) {
+		c.maxResponseBytes = n
+	}
+}
+
 // Register wires all codebase-scanner MCP tools to s.
-// Each tool delegates to f for querying the indexed codebase.
-func Register(s *server.MCPServer, f *finder.Finder) {
-	s.AddTool(mcp.NewTool("list_packages",
+// Query tools delegate to f; index maintenance tools operate on idx directly.
+func Register(s *server.MCPServer, idx *indexer.Indexer, f *finder.Finder, opts ...Option) {
+	var c config
+	for _, opt := range opts {
+		opt(&c)
+	}
+	add := func(tool mcp.Tool, handler server.ToolHandlerFunc) {
+		withBudgetParams()(&tool)
+		s.AddTool(tool, withLengthCheck(withBudget(c.maxResponseBytes, handler)))
+	}
+
+	add(mcp.NewTool("list_packages",
 		mcp.WithDescription("Lists all indexed packages with summary statistics."),
 		mcp.WithString("filter", mcp.Description("Optional prefix filter on import path")),
-	), withLengthCheck(listPackagesHandler(f)))
+		mcp.WithString("module", mcp.Description("Only list packages of the module with this path")),
+		mcp.WithBoolean("include_tests", mcp.Description("Include external test packages and count test-only symbols (default: false)")),
+		withPaging(defaultPage),
+	), listPackagesHandler(f))
+
+	add(mcp.NewTool("get_package_imports",
+		mcp.WithDescription("Lists the packages a package imports, optionally transitively, to work out the layering of the codebase. Packages outside the index, such as the standard library, are listed but not followed."),
+		mcp.WithString("package", mcp.Required(), mcp.Description("Package import path")),
+		mcp.WithNumber("depth", mcp.Description("How many imports away to walk (default: 1)")),
+		mcp.WithBoolean("internal_only", mcp.Description("Only list indexed packages, leaving out the standard library and third-party dependencies (default: false)")),
+		mcp.WithBoolean("include_tests", mcp.Description("Include the imports of the package's _test.go files (default: false)")),
+		withPaging(defaultPage),
+	), getPackageImportsHandler(f))
 
-	s.AddTool(mcp.NewTool("get_package_symbols",
+	add(mcp.NewTool("get_package_importers",
+		mcp.WithDescription("Lists the indexed packages that import a package, optionally transitively. The package may be outside the index, such as a standard library package."),
+		mcp.WithString("package", mcp.Required(), mcp.Description("Package import path")),
+		mcp.WithNumber("depth", mcp.Description("How many imports away to walk (default: 1)")),
+		mcp.WithBoolean("include_tests", mcp.Description("Include packages that import it only from _test.go files (default: false)")),
+		withPaging(defaultPage),
+	), getPackageImportersHandler(f))
+
+	add(mcp.NewTool("get_package_symbols",
 		mcp.WithDescription("Returns all symbols in a package: functions, types, variables, and constants."),
 		mcp.WithString("package", mcp.Required(), mcp.Description("Package import path")),
 		mcp.WithBoolean("include_unexported", mcp.Description("Include unexported symbols (default: false)")),
 		mcp.WithBoolean("include_bodies", mcp.Description("Include function bodies (default: false)")),
-	), withLengthCheck(getPackageSymbolsHandler(f)))
+		mcp.WithBoolean("include_tests", mcp.Description("Include symbols declared in _test.go files (default: false)")),
+		withPaging(defaultPage),
+	), getPackageSymbolsHandler(f))
 
-	s.AddTool(mcp.NewTool("find_symbol",
-		mcp.WithDescription("Searches for a symbol by name across the entire indexed codebase."),
+	add(mcp.NewTool("find_symbol",
+		mcp.WithDescription("Searches for a symbol by name across the entire indexed codebase. Results are ranked best match first."),
 		mcp.WithString("name", mcp.Required(), mcp.Description("Symbol name to search for")),
 		mcp.WithString("kind", mcp.Description("Filter by kind: func, method, type, var, const (empty = all)")),
-		mcp.WithString("match", mcp.Description(`Match mode: "exact" (default), "prefix", or "contains"`)),
-	), withLengthCheck(findSymbolHandler(f)))
+		mcp.WithString("match", mcp.Description(`Match mode: "exact" (default), "prefix", "contains", "fuzzy" (characters in order or camelCase initials, such as "NHS" for NewHTTPServer), or "regex" (RE2 syntax, such as "^(New|Must)[A-Z].*Client$"; at most 512 bytes)`)),
+		mcp.WithBoolean("ignore_case", mcp.Description("Compare names case-insensitively in the exact, prefix, contains, and regex modes; fuzzy always does (default: false)")),
+		mcp.WithBoolean("include_tests", mcp.Description("Include symbols declared in _test.go files (default: false)")),
+		withPaging(defaultPage),
+	), findSymbolHandler(f))
+
+	add(mcp.NewTool("search",
+		mcp.WithDescription("Ranks symbols by how well their names (split at camelCase), doc comments, field comments, and signatures match the words of a query, such as \"retry backoff\". Use it to find code by what it does rather than by name."),
+		mcp.WithString("query", mcp.Required(), mcp.Description("Words to search for")),
+		mcp.WithString("kind", mcp.Description("Filter by kind: func, method, type, var, const (empty = all)")),
+		mcp.WithBoolean("include_tests", mcp.Description("Include symbols declared in _test.go files (default: false)")),
+		withPaging(searchPage),
+	), searchHandler(f))
 
-	s.AddTool(mcp.NewTool("get_function",
+	add(mcp.NewTool("find_by_signature",
+		mcp.WithDescription("Finds functions and methods by the types of their parameters and results, such as taking context.Context and *Order and returning error. Use it to discover whether a helper turning one type into another already exists."),
+		mcp.WithArray("params", mcp.WithStringItems(), mcp.Description("Go types that must each match a different parameter, in any order, such as \"*Order\" or \"context.Context\". A method's receiver counts as a parameter.")),
+		mcp.WithArray("results", mcp.WithStringItems(), mcp.Description("Go types that must each match a different result, in any order")),
+		mcp.WithBoolean("exact", mcp.Description("Reject functions with parameters or results beyond those given (default: false)")),
+		mcp.WithBoolean("assignable", mcp.Description("Also match parameters the given types can be passed to, such as interfaces they implement, and results assignable to the given types (default: false)")),
+		mcp.WithBoolean("lenient_pointers", mcp.Description("Let T and *T match each other (default: false)")),
+		mcp.WithBoolean("include_tests", mcp.Description("Include functions declared in _test.go files (default: false)")),
+		withPaging(defaultPage),
+	), findBySignatureHandler(f))
+
+	add(mcp.NewTool("get_function",
 		mcp.WithDescription("Returns full details for a specific function or method."),
 		mcp.WithString("package", mcp.Required(), mcp.Description("Package import path")),
 		mcp.WithString("name", mcp.Required(), mcp.Description("Function name, or TypeName.MethodName for methods")),
-	), withLengthCheck(getFunctionHandler(f)))
+	), getFunctionHandler(f))
 
-	s.AddTool(mcp.NewTool("get_type",
+	add(mcp.NewTool("get_type",
 		mcp.WithDescription("Returns full definition of a type (struct or interface)."),
 		mcp.WithString("package", mcp.Required(), mcp.Description("Package import path")),
 		mcp.WithString("name", mcp.Required(), mcp.Description("Type name")),
-	), withLengthCheck(getTypeHandler(f)))
+	), getTypeHandler(f))
+
+	add(mcp.NewTool("get_enum",
+		mcp.WithDescription("Returns the constants of a named type, such as an iota enum, in declaration order with their values, and whether the type has a String() method."),
+		mcp.WithString("package", mcp.Required(), mcp.Description("Package import path of the type")),
+		mcp.WithString("name", mcp.Required(), mcp.Description("Type name")),
+		mcp.WithBoolean("include_tests", mcp.Description("Include constants declared in _test.go files (default: false)")),
+	), getEnumHandler(f))
 
-	s.AddTool(mcp.NewTool("get_file_symbols",
+	add(mcp.NewTool("get_file_symbols",
 		mcp.WithDescription("Returns all symbols defined in a specific file."),
 		mcp.WithString("file", mcp.Required(), mcp.Description("File path (absolute or relative)")),
 		mcp.WithBoolean("include_unexported", mcp.Description("Include unexported symbols (default: false)")),
 		mcp.WithBoolean("include_bodies", mcp.Description("Include function bodies (default: false)")),
-	), withLengthCheck(getFileSymbolsHandler(f)))
+		withPaging(defaultPage),
+	), getFileSymbolsHandler(f))
 
-	s.AddTool(mcp.NewTool("find_implementations",
+	add(mcp.NewTool("find_implementations",
 		mcp.WithDescription("Finds all concrete types in the indexed codebase that implement a given interface."),
 		mcp.WithString("package", mcp.Required(), mcp.Description("Package import path of the interface")),
 		mcp.WithString("interface", mcp.Required(), mcp.Description("Interface type name")),
-	), withLengthCheck(findImplementationsHandler(f)))
+		mcp.WithBoolean("include_tests", mcp.Description("Include types declared in _test.go files, such as fakes (default: false)")),
+		withPaging(defaultPage),
+	), findImplementationsHandler(f))
+
+	add(mcp.NewTool("find_interfaces_for_type",
+		mcp.WithDescription("Lists the interfaces a type satisfies: those declared in the indexed codebase, exported interfaces of its dependencies and the standard library, and error. Each result says whether the type itself or only a pointer to it satisfies the interface."),
+		mcp.WithString("package", mcp.Required(), mcp.Description("Package import path of the type")),
+		mcp.WithString("type", mcp.Required(), mcp.Description("Type name")),
+		mcp.WithBoolean("include_tests", mcp.Description("Include interfaces declared in _test.go files (default: false)")),
+		withPaging(defaultPage),
+	), findInterfacesForTypeHandler(f))
+
+	add(mcp.NewTool("find_constructors",
+		mcp.WithDescription("Lists the functions that produce a value of a type: those returning T or *T, alone or with an error, and those returning an interface the type implements. Functions of the type's own package come first. Use it to learn how to obtain a value of a type."),
+		mcp.WithString("package", mcp.Required(), mcp.Description("Package import path of the type")),
+		mcp.WithString("type", mcp.Required(), mcp.Description("Type name")),
+		mcp.WithBoolean("include_tests", mcp.Description("Include functions declared in _test.go files (default: false)")),
+		withPaging(defaultPage),
+	), findConstructorsHandler(f))
+
+	add(mcp.NewTool("find_references",
+		mcp.WithDescription("Finds every use of a function, type, method, field, variable, or constant across the indexed codebase, with the enclosing function and whether each use is a read, write, call, or type use."),
+		mcp.WithString("package", mcp.Required(), mcp.Description("Package import path of the symbol")),
+		mcp.WithString("symbol", mcp.Required(), mcp.Description("Symbol name, or TypeName.MemberName for methods and fields")),
+		mcp.WithBoolean("include_tests", mcp.Description("Include uses in _test.go files (default: false)")),
+		withPaging(defaultPage),
+	), findReferencesHandler(f))
+
+	add(mcp.NewTool("find_instantiations",
+		mcp.WithDescription("Finds the places a generic function or type is instantiated across the indexed codebase, with the explicit or inferred type arguments of each."),
+		mcp.WithString("package", mcp.Required(), mcp.Description("Package import path of the generic function or type")),
+		mcp.WithString("symbol", mcp.Required(), mcp.Description("Generic function or type name")),
+		mcp.WithBoolean("include_tests", mcp.Description("Include instantiations in _test.go files (default: false)")),
+		withPaging(defaultPage),
+	), findInstantiationsHandler(f))
+
+	add(mcp.NewTool("get_definition",
+		mcp.WithDescription("Resolves the identifier at a file position to its declaration: a package-level symbol, method, field, imported package, or a local variable, parameter, or label inside a function body."),
+		mcp.WithString("file", mcp.Required(), mcp.Description("File path (absolute, or relative and matching the end of one indexed file)")),
+		mcp.WithNumber("line", mcp.Required(), mcp.Description("1-based line")),
+		mcp.WithNumber("column", mcp.Required(), mcp.Description("1-based byte column")),
+	), getDefinitionHandler(f))
+
+	add(mcp.NewTool("get_hover",
+		mcp.WithDescription("Returns the static type of the expression or identifier at a file position, the methods callable on it, and the doc comment of the object it names. Works for local variables and intermediate expressions inside function bodies."),
+		mcp.WithString("file", mcp.Required(), mcp.Description("File path (absolute, or relative and matching the end of one indexed file)")),
+		mcp.WithNumber("line", mcp.Required(), mcp.Description("1-based line")),
+		mcp.WithNumber("column", mcp.Required(), mcp.Description("1-based byte column")),
+	), getHoverHandler(f))
+
+	add(mcp.NewTool("get_callers",
+		mcp.WithDescription("Walks the static call graph toward the callers of a function or method, with call sites. Calls through interfaces are resolved to every implementation (class hierarchy analysis). Only indexed functions are listed: calls into the standard library and third-party dependencies are left out."),
+		mcp.WithString("package", mcp.Required(), mcp.Description("Package import path of the function")),
+		mcp.WithString("symbol", mcp.Required(), mcp.Description("Function name, or TypeName.MethodName for methods")),
+		mcp.WithNumber("depth", mcp.Description("How many calls away to walk (default: 1, max: 5)")),
+		mcp.WithBoolean("include_tests", mcp.Description("Include calls from _test.go files (default: false)")),
+		withPaging(defaultPage),
+	), getCallersHandler(f))
+
+	add(mcp.NewTool("get_callees",
+		mcp.WithDescription("Walks the static call graph toward the functions and methods a function calls, with call sites. Calls through interfaces are resolved to every implementation (class hierarchy analysis). Only indexed functions are listed: calls into the standard library and third-party dependencies are left out."),
+		mcp.WithString("package", mcp.Required(), mcp.Description("Package import path of the function")),
+		mcp.WithString("symbol", mcp.Required(), mcp.Description("Function name, or TypeName.MethodName for methods")),
+		mcp.WithNumber("depth", mcp.Description("How many calls away to walk (default: 1, max: 5)")),
+		mcp.WithBoolean("include_tests", mcp.Description("Include test functions and the methods of test types (default: false)")),
+		withPaging(defaultPage),
+	), getCalleesHandler(f))
+
+	add(mcp.NewTool("list_tests",
+		mcp.WithDescription("Lists the Test, Benchmark, Fuzz, and Example functions of a package, including its external _test package, with their locations."),
+		mcp.WithString("package", mcp.Required(), mcp.Description("Package import path")),
+		mcp.WithString("kind", mcp.Description("Filter by kind: test, benchmark, fuzz, example (empty = all)")),
+		withPaging(defaultPage),
+	), listTestsHandler(f))
+
+	add(mcp.NewTool("get_load_errors",
+		mcp.WithDescription("Lists load, parse, and type errors in indexed packages with file, line, and column. Use it to check whether the code compiles."),
+		mcp.WithString("filter", mcp.Description("Optional prefix filter on import path")),
+		withPaging(defaultPage),
+	), getLoadErrorsHandler(f))
+
+	add(mcp.NewTool("reindex",
+		mcp.WithDescription("Rebuilds the index, fully or for specific packages and their importers. Use after editing code to make sure later queries see the changes."),
+		mcp.WithArray("packages", mcp.WithStringItems(), mcp.Description("Package import paths to re-index (empty = full rebuild)")),
+	), reindexHandler(idx))
+
+	add(mcp.NewTool("index_status",
+		mcp.WithDescription("Reports when the index was built, how long it took, package and symbol counts, the generation number, and whether a rebuild is in progress."),
+	), indexStatusHandler(idx))
+}
+
+// withPaging adds the limit and cursor parameters of a list tool whose pages
+// are bounded by limits.
+func withPaging(limits pageLimits) mcp.ToolOption {
+	return func(t *mcp.Tool) {
+		mcp.WithNumber("limit", mcp.Description(fmt.Sprintf("Maximum number of results per page (default: %d, max: %d)", limits.def, limits.max)))(t)
+		mcp.WithString("cursor", mcp.Description("next_cursor of the previous page, to get the page after it. Cursors expire when the index is rebuilt."))(t)
+	}
+}
+
+// withBudgetParams adds the max_bytes and max_tokens parameters that every tool
+// takes.
+func withBudgetParams() mcp.ToolOption {
+	return func(t *mcp.Tool) {
+		mcp.WithNumber("max_bytes", mcp.Description(fmt.Sprintf("Response budget in bytes, at least %d. Larger responses lose function bodies, then doc comments, then their last entries, and say so in a truncated member.", MinResponseBytes)))(t)
+		mcp.WithNumber("max_tokens", mcp.Description(fmt.Sprintf("Response budget in tokens, counted as %d bytes each; see max_bytes", bytesPerToken)))(t)
+	}
 }
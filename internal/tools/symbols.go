@@ -3,6 +3,7 @@ package tools
 import (
 	"context"
 	"fmt"
+	"slices"
 	"strings"
 
 	"github.com/mark3labs/mcp-go/mcp"
@@ -13,30 +14,86 @@ import (
 
 // findSymbolHandler returns a handler for the find_symbol tool.
 // It searches for symbols by name across all indexed packages,
-// with an optional kind filter (func, method, type, var, const) and match mode.
+// with an optional kind filter (func, method, type, var, const), match mode,
+// and case-insensitive comparison. Results are ranked best match first.
+// Symbols declared in _test.go files are left out unless include_tests is set.
 func findSymbolHandler(f *finder.Finder) server.ToolHandlerFunc {
-	return func(_ context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		name, err := req.RequireString("name")
 		if err != nil {
 			return nil, err
 		}
 		kind := req.GetString("kind", "")
+		includeTests := req.GetBool("include_tests", false)
 		match := finder.MatchMode(req.GetString("match", string(finder.MatchExact)))
 		if err := match.Validate(); err != nil {
 			return nil, err
 		}
 
-		refs := f.FindSymbol(name, match)
-		if kind != "" {
-			filtered := make([]symtab.SymbolRef, 0, len(refs))
-			for _, r := range refs {
-				if string(r.Kind) == kind {
-					filtered = append(filtered, r)
+		return pageResult(ctx, f, req, defaultPage, func() ([]symtab.SymbolRef, error) {
+			refs, err := f.FindSymbol(name, match, req.GetBool("ignore_case", false))
+			if err != nil {
+				return nil, fmt.Errorf("finding symbol %q: %w", name, err)
+			}
+			if kind != "" || !includeTests {
+				filtered := make([]symtab.SymbolRef, 0, len(refs))
+				for _, r := range refs {
+					if (kind == "" || string(r.Kind) == kind) && (includeTests || !r.IsTest) {
+						filtered = append(filtered, r)
+					}
 				}
+				refs = filtered
+			}
+			return refs, nil
+		})
+	}
+}
+
+// searchPage limits the pages of the search tool to its best results.
+var searchPage = pageLimits{def: 20, max: 100}
+
+// searchHandler returns a handler for the search tool.
+// It ranks symbols by how well their names, doc comments, and signatures match
+// the words of a query.
+func searchHandler(f *finder.Finder) server.ToolHandlerFunc {
+	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		query, err := req.RequireString("query")
+		if err != nil {
+			return nil, err
+		}
+		return pageResult(ctx, f, req, searchPage, func() ([]symtab.SearchResult, error) {
+			results, err := f.Search(query, symtab.SymbolKind(req.GetString("kind", "")), req.GetBool("include_tests", false))
+			if err != nil {
+				return nil, fmt.Errorf("searching for %q: %w", query, err)
 			}
-			refs = filtered
+			return results, nil
+		})
+	}
+}
+
+// findBySignatureHandler returns a handler for the find_by_signature tool.
+// It finds functions and methods by the types of their parameters and results.
+// Functions declared in _test.go files are left out unless include_tests is set.
+func findBySignatureHandler(f *finder.Finder) server.ToolHandlerFunc {
+	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		q := finder.SignatureQuery{
+			Params:          req.GetStringSlice("params", nil),
+			Results:         req.GetStringSlice("results", nil),
+			Exact:           req.GetBool("exact", false),
+			Assignable:      req.GetBool("assignable", false),
+			LenientPointers: req.GetBool("lenient_pointers", false),
 		}
-		return jsonResult(refs)
+
+		return pageResult(ctx, f, req, defaultPage, func() ([]symtab.SymbolRef, error) {
+			refs, err := f.FindBySignature(q)
+			if err != nil {
+				return nil, fmt.Errorf("finding functions by signature: %w", err)
+			}
+			if !req.GetBool("include_tests", false) {
+				refs = slices.DeleteFunc(refs, func(r symtab.SymbolRef) bool { return r.IsTest })
+			}
+			return refs, nil
+		})
 	}
 }
 
@@ -44,7 +101,7 @@ func findSymbolHandler(f *finder.Finder) server.ToolHandlerFunc {
 // It looks up a package-level function or, when name is "TypeName.MethodName",
 // a method on a named type.
 func getFunctionHandler(f *finder.Finder) server.ToolHandlerFunc {
-	return func(_ context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		pkgPath, err := req.RequireString("package")
 		if err != nil {
 			return nil, err
@@ -68,7 +125,7 @@ func getFunctionHandler(f *finder.Finder) server.ToolHandlerFunc {
 				}
 				for _, m := range t.Methods {
 					if m.Name == methodName {
-						return jsonResult(m)
+						return fitResult(ctx, m, nil, functionOmissions)
 					}
 				}
 				return nil, fmt.Errorf("method %q not found on type %q in package %q", methodName, typeName, pkgPath)
@@ -79,7 +136,7 @@ func getFunctionHandler(f *finder.Finder) server.ToolHandlerFunc {
 		// Package-level function
 		for _, fn := range pkg.Funcs {
 			if fn.Name == name {
-				return jsonResult(fn)
+				return fitResult(ctx, fn, nil, functionOmissions)
 			}
 		}
 		return nil, fmt.Errorf("function %q not found in package %q", name, pkgPath)
@@ -90,7 +147,7 @@ func getFunctionHandler(f *finder.Finder) server.ToolHandlerFunc {
 // It looks up a named type (struct, interface, or other) in the given package
 // and returns its full definition including fields, methods, and doc comment.
 func getTypeHandler(f *finder.Finder) server.ToolHandlerFunc {
-	return func(_ context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		pkgPath, err := req.RequireString("package")
 		if err != nil {
 			return nil, err
@@ -108,9 +165,35 @@ func getTypeHandler(f *finder.Finder) server.ToolHandlerFunc {
 		for i := range pkg.Types {
 			t := &pkg.Types[i]
 			if t.Name == name {
-				return jsonResult(t)
+				return fitResult(ctx, t, nil, typeOmissions)
 			}
 		}
 		return nil, fmt.Errorf("type %q not found in package %q", name, pkgPath)
 	}
 }
+
+// getEnumHandler returns a handler for the get_enum tool.
+// It lists the constants of a named type in declaration order with their
+// values, and whether the type has a String method. Constants declared in
+// _test.go files are left out unless include_tests is set.
+func getEnumHandler(f *finder.Finder) server.ToolHandlerFunc {
+	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		pkgPath, err := req.RequireString("package")
+		if err != nil {
+			return nil, err
+		}
+		name, err := req.RequireString("name")
+		if err != nil {
+			return nil, err
+		}
+
+		enum, err := f.GetEnum(pkgPath, name)
+		if err != nil {
+			return nil, fmt.Errorf("getting enum %q: %w", name, err)
+		}
+		if !req.GetBool("include_tests", false) {
+			enum.Consts = dropTestVars(enum.Consts)
+		}
+		return jsonResult(ctx, enum)
+	}
+}
@@ -25,6 +25,7 @@ func TestFindSymbolHandler(t *testing.T) {
 		symbol      string
 		kind        string
 		match       string
+		ignoreCase  bool
 		expected    []symtab.SymbolRef
 		expectedErr string
 	}{
@@ -43,7 +44,11 @@ func TestFindSymbolHandler(t *testing.T) {
 		{name: "nonexistent symbol", symbol: "NoSuchSymbol"},
 		{name: "prefix match", symbol: "Engl", match: "prefix", expected: []symtab.SymbolRef{{Kind: symtab.SymbolKindType}}},
 		{name: "contains match", symbol: "Length", match: "contains", expected: []symtab.SymbolRef{{Kind: symtab.SymbolKindVar}}},
-		{name: "invalid match mode", symbol: "New", match: "fuzzy", expectedErr: `unknown match mode "fuzzy"`},
+		{name: "ignore case", symbol: "english", ignoreCase: true, expected: []symtab.SymbolRef{{Kind: symtab.SymbolKindType}}},
+		{name: "fuzzy match", symbol: "FmlEng", match: "fuzzy", expected: []symtab.SymbolRef{{Kind: symtab.SymbolKindType}}},
+		{name: "regex match with kind", symbol: "^(New|Greet)$", match: "regex", kind: "func", expected: []symtab.SymbolRef{{Kind: symtab.SymbolKindFunc}}},
+		{name: "invalid regex", symbol: "New(", match: "regex", expectedErr: "missing closing )"},
+		{name: "invalid match mode", symbol: "New", match: "soundex", expectedErr: `unknown match mode "soundex"`},
 	}
 
 	for _, tt := range tests {
@@ -55,6 +60,9 @@ func TestFindSymbolHandler(t *testing.T) {
 			if tt.match != "" {
 				args["match"] = tt.match
 			}
+			if tt.ignoreCase {
+				args["ignore_case"] = true
+			}
 			req := mcp.CallToolRequest{Params: mcp.CallToolParams{Arguments: args}}
 			resp, err := handler(context.Background(), req)
 			if tt.expectedErr != "" {
@@ -67,13 +75,13 @@ func TestFindSymbolHandler(t *testing.T) {
 			content, ok := resp.Content[0].(mcp.TextContent)
 			require.True(t, ok)
 
-			var actuals []symtab.SymbolRef
-			err = json.Unmarshal([]byte(content.Text), &actuals)
-			require.NoError(t, err)
+			var got page[symtab.SymbolRef]
+			require.NoError(t, json.Unmarshal([]byte(content.Text), &got))
+			actuals := got.Items
 
 			assert.Len(t, actuals, len(tt.expected))
 			for i, actual := range actuals {
-				if tt.match == "" || tt.match == "exact" {
+				if (tt.match == "" || tt.match == "exact") && !tt.ignoreCase {
 					assert.Equal(t, tt.symbol, actual.Name)
 				}
 				assert.Equal(t, tt.expected[i].Kind, actual.Kind)
@@ -239,3 +247,78 @@ func TestGetTypeHandler(t *testing.T) {
 		})
 	}
 }
+
+func TestGetEnumHandler(t *testing.T) {
+	idx, _ := indexFixture(t, map[string]string{
+		"greeter/tone.go":      "package greeter\n\n// Tone is how a greeting sounds.\ntype Tone int\n\nconst (\n\tWarm Tone = iota\n\tCold\n)\n",
+		"greeter/tone_test.go": "package greeter\n\nconst testTone Tone = 7\n",
+	})
+	handler := getEnumHandler(finder.New(idx))
+
+	var enum symtab.EnumInfo
+	callTool(t, handler, map[string]any{"package": fixturePkg, "name": "Tone"}, &enum)
+	assert.Equal(t, "Tone", enum.Name)
+	assert.False(t, enum.HasString)
+	require.Len(t, enum.Consts, 2)
+	assert.Equal(t, "Warm", enum.Consts[0].Name)
+	assert.Equal(t, "1", enum.Consts[1].Value)
+
+	callTool(t, handler, map[string]any{"package": fixturePkg, "name": "Tone", "include_tests": true}, &enum)
+	assert.Len(t, enum.Consts, 3)
+
+	req := mcp.CallToolRequest{Params: mcp.CallToolParams{Arguments: map[string]any{"package": fixturePkg, "name": "Greeter"}}}
+	_, err := handler(context.Background(), req)
+	assert.ErrorContains(t, err, "has kind interface")
+}
+
+func TestSearchHandler(t *testing.T) {
+	idx, err := indexer.New(fixturePkgPath)
+	require.NoError(t, err)
+	require.NoError(t, idx.Index())
+	handler := searchHandler(finder.New(idx))
+
+	t.Run("ranks by doc comment", func(t *testing.T) {
+		results := callList[symtab.SearchResult](t, handler, map[string]any{"query": "formal salutation", "kind": "type"})
+		require.NotEmpty(t, results)
+		assert.Equal(t, "Formal", results[0].Name)
+		assert.Equal(t, "Formal greets with a formal salutation.", results[0].Snippet)
+	})
+
+	t.Run("no matches", func(t *testing.T) {
+		results := callList[symtab.SearchResult](t, handler, map[string]any{"query": "kubernetes"})
+		assert.NotNil(t, results)
+		assert.Empty(t, results)
+	})
+
+	t.Run("limit out of range", func(t *testing.T) {
+		req := mcp.CallToolRequest{}
+		req.Params.Arguments = map[string]any{"query": "greet", "limit": 0}
+		_, err := handler(context.Background(), req)
+		assert.Error(t, err)
+	})
+}
+
+func TestFindBySignatureHandler(t *testing.T) {
+	idx, err := indexer.New(fixturePkgPath)
+	require.NoError(t, err)
+	require.NoError(t, idx.Index())
+	handler := findBySignatureHandler(finder.New(idx))
+
+	t.Run("matches", func(t *testing.T) {
+		refs := callList[symtab.SymbolRef](t, handler, map[string]any{"params": []any{"string"}, "results": []any{"*English"}, "exact": true})
+		require.Len(t, refs, 1)
+		assert.Equal(t, "New", refs[0].Name)
+		assert.Equal(t, fixturePkg, refs[0].Package)
+	})
+
+	t.Run("no matches", func(t *testing.T) {
+		refs := callList[symtab.SymbolRef](t, handler, map[string]any{"params": []any{"*Formal"}, "exact": true})
+		assert.NotNil(t, refs)
+		assert.Empty(t, refs)
+	})
+
+	t.Run("no types", func(t *testing.T) {
+		_, err := handler(context.Background(), mcp.CallToolRequest{})
+		assert.ErrorContains(t, err, "at least one parameter or result type is required")
+	})
+}
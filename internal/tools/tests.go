@@ -0,0 +1,43 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/tender-barbarian/go-llm-lens/internal/finder"
+	"github.com/tender-barbarian/go-llm-lens/internal/symtab"
+)
+
+// listTestsHandler returns a handler for the list_tests tool.
+// It lists the test, benchmark, fuzz, and example functions of a package and
+// its external test package, optionally filtered by kind.
+func listTestsHandler(f *finder.Finder) server.ToolHandlerFunc {
+	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		pkgPath, err := req.RequireString("package")
+		if err != nil {
+			return nil, err
+		}
+		kind := symtab.TestKind(req.GetString("kind", ""))
+		switch kind {
+		case "", symtab.TestKindTest, symtab.TestKindBenchmark, symtab.TestKindFuzz, symtab.TestKindExample:
+		default:
+			return nil, fmt.Errorf("unknown test kind %q: must be one of test, benchmark, fuzz, example", kind)
+		}
+
+		return pageResult(ctx, f, req, defaultPage, func() ([]symtab.FuncInfo, error) {
+			tests, err := f.ListTests(pkgPath)
+			if err != nil {
+				return nil, err
+			}
+			result := make([]symtab.FuncInfo, 0, len(tests))
+			for _, fn := range tests {
+				if kind == "" || fn.TestKind == kind {
+					result = append(result, fn)
+				}
+			}
+			return stripFuncBodies(result), nil
+		})
+	}
+}
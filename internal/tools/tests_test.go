@@ -0,0 +1,192 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tender-barbarian/go-llm-lens/internal/finder"
+	"github.com/tender-barbarian/go-llm-lens/internal/indexer"
+	"github.com/tender-barbarian/go-llm-lens/internal/symtab"
+)
+
+// newTestsFinder indexes a copy of the fixture module with an in-package test
+// file and an external test file added to the greeter package.
+func newTestsFinder(t *testing.T) *finder.Finder {
+	t.Helper()
+	idx, _ := indexFixture(t, map[string]string{
+		"greeter/greeter_test.go": `package greeter
+
+import "testing"
+
+type fakeGreeter struct{}
+
+func (fakeGreeter) Greet(string) string { return "" }
+
+func TestGreet(t *testing.T) {}
+
+func BenchmarkGreet(b *testing.B) {}
+`,
+		"greeter/example_test.go": `package greeter_test
+
+import "testing"
+
+func ExampleNew() {}
+
+func FuzzGreet(f *testing.F) {}
+`,
+	})
+	return finder.New(idx)
+}
+
+// indexFixture indexes a copy of the fixture module with the extra files
+// added, keyed by their path relative to the root, and returns the indexer and
+// the copy's root.
+func indexFixture(t *testing.T, extra map[string]string) (*indexer.Indexer, string) {
+	t.Helper()
+	root := t.TempDir()
+	require.NoError(t, os.CopyFS(root, os.DirFS(fixturePkgPath)))
+	for name, content := range extra {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o750))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	}
+	idx, err := indexer.New(root)
+	require.NoError(t, err)
+	require.NoError(t, idx.Index())
+	return idx, root
+}
+
+// callTool invokes handler with args and decodes its JSON result into out.
+func callTool(t *testing.T, handler func(context.Context, mcp.CallToolRequest) (*mcp.CallToolResult, error), args map[string]any, out any) {
+	t.Helper()
+	req := mcp.CallToolRequest{Params: mcp.CallToolParams{Arguments: args}}
+	res, err := handler(context.Background(), req)
+	require.NoError(t, err)
+	content, ok := res.Content[0].(mcp.TextContent)
+	require.True(t, ok)
+	require.NoError(t, json.Unmarshal([]byte(content.Text), out))
+}
+
+// callList calls a list tool for a single page and returns its items.
+func callList[T any](t *testing.T, handler func(context.Context, mcp.CallToolRequest) (*mcp.CallToolResult, error), args map[string]any) []T {
+	t.Helper()
+	var out page[T]
+	callTool(t, handler, args, &out)
+	require.Empty(t, out.NextCursor, "more than one page")
+	require.Len(t, out.Items, out.Total)
+	return out.Items
+}
+
+func TestListTestsHandler(t *testing.T) {
+	handler := listTestsHandler(newTestsFinder(t))
+
+	tests := []struct {
+		name        string
+		args        map[string]any
+		expected    map[string]symtab.TestKind
+		expectedErr string
+	}{
+		{
+			name: "all kinds",
+			args: map[string]any{"package": fixturePkg},
+			expected: map[string]symtab.TestKind{
+				"TestGreet":      symtab.TestKindTest,
+				"BenchmarkGreet": symtab.TestKindBenchmark,
+				"ExampleNew":     symtab.TestKindExample,
+				"FuzzGreet":      symtab.TestKindFuzz,
+			},
+		},
+		{
+			name:     "kind filter",
+			args:     map[string]any{"package": fixturePkg, "kind": "fuzz"},
+			expected: map[string]symtab.TestKind{"FuzzGreet": symtab.TestKindFuzz},
+		},
+		{name: "invalid kind", args: map[string]any{"package": fixturePkg, "kind": "unit"}, expectedErr: `unknown test kind "unit"`},
+		{name: "unknown package", args: map[string]any{"package": "no/such/pkg"}, expectedErr: "not found"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.expectedErr != "" {
+				req := mcp.CallToolRequest{Params: mcp.CallToolParams{Arguments: tt.args}}
+				_, err := handler(context.Background(), req)
+				assert.ErrorContains(t, err, tt.expectedErr)
+				return
+			}
+
+			actual := callList[symtab.FuncInfo](t, handler, tt.args)
+			kinds := make(map[string]symtab.TestKind, len(actual))
+			for _, fn := range actual {
+				kinds[fn.Name] = fn.TestKind
+				assert.NotZero(t, fn.Location.Line)
+				assert.Empty(t, fn.Body)
+			}
+			assert.Equal(t, tt.expected, kinds)
+		})
+	}
+}
+
+func TestIncludeTests(t *testing.T) {
+	f := newTestsFinder(t)
+
+	t.Run("find_symbol", func(t *testing.T) {
+		without := callList[symtab.SymbolRef](t, findSymbolHandler(f), map[string]any{"name": "Greet"})
+		with := callList[symtab.SymbolRef](t, findSymbolHandler(f), map[string]any{"name": "Greet", "include_tests": true})
+		assert.Len(t, without, 3)
+		require.Len(t, with, 4)
+		fake := with[slices.IndexFunc(with, func(r symtab.SymbolRef) bool { return r.IsTest })]
+		assert.Equal(t, fixturePkg+".fakeGreeter", fake.Receiver)
+		assert.Equal(t, "greeter_test.go", filepath.Base(fake.Location.File))
+	})
+
+	t.Run("get_package_symbols", func(t *testing.T) {
+		type result struct {
+			Funcs []symtab.FuncInfo `json:"funcs"`
+			Types []symtab.TypeInfo `json:"types"`
+		}
+		names := func(r result) []string {
+			var names []string
+			for _, fn := range r.Funcs {
+				names = append(names, fn.Name)
+			}
+			for _, ti := range r.Types {
+				names = append(names, ti.Name)
+			}
+			return names
+		}
+		var without, with result
+		callTool(t, getPackageSymbolsHandler(f), map[string]any{"package": fixturePkg, "include_unexported": true}, &without)
+		callTool(t, getPackageSymbolsHandler(f), map[string]any{"package": fixturePkg, "include_unexported": true, "include_tests": true}, &with)
+		assert.NotContains(t, names(without), "TestGreet")
+		assert.NotContains(t, names(without), "fakeGreeter")
+		assert.Subset(t, names(with), []string{"TestGreet", "BenchmarkGreet", "fakeGreeter"})
+	})
+
+	t.Run("list_packages", func(t *testing.T) {
+		type summary struct {
+			ImportPath string `json:"import_path"`
+			FileCount  int    `json:"file_count"`
+		}
+		without := callList[summary](t, listPackagesHandler(f), map[string]any{})
+		with := callList[summary](t, listPackagesHandler(f), map[string]any{"include_tests": true})
+		assert.Equal(t, []summary{{ImportPath: fixturePkg, FileCount: 1}}, without)
+		assert.ElementsMatch(t, []summary{{ImportPath: fixturePkg, FileCount: 2}, {ImportPath: fixturePkg + "_test", FileCount: 1}}, with)
+	})
+
+	t.Run("find_implementations", func(t *testing.T) {
+		args := map[string]any{"package": fixturePkg, "interface": "Greeter"}
+		without := callList[symtab.TypeInfo](t, findImplementationsHandler(f), args)
+		args["include_tests"] = true
+		with := callList[symtab.TypeInfo](t, findImplementationsHandler(f), args)
+		assert.Len(t, without, 3)
+		require.Len(t, with, 4)
+		assert.True(t, slices.ContainsFunc(with, func(ti symtab.TypeInfo) bool { return ti.Name == "fakeGreeter" && ti.IsTest }))
+	})
+}